@@ -0,0 +1,4 @@
+// objectPath encodes an object name for a URL path the way it is written in SQL: the
+// server upper-cases names that are not in double quotes, so other names are quoted.
+export const objectPath = (name) =>
+    encodeURIComponent(/^[A-Z][A-Z0-9_$]*$/.test(name) ? name : `"${name.replace(/"/g, '""')}"`)
@@ -0,0 +1,34 @@
+package domain
+
+// DependencyNode is an object in a dependency graph.
+type DependencyNode struct {
+	ID    string `json:"id"` // "TYPE:NAME", e.g. "TABLE:CUSTOMER"
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Depth int    `json:"depth"` // Distance from the root object
+}
+
+// DependencyEdge states that the object From uses the object To.
+type DependencyEdge struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Column     string `json:"column,omitempty"`      // Column of To that is referenced
+	FromColumn string `json:"from_column,omitempty"` // Column of From that holds the reference, e.g. a column based on a domain
+}
+
+// DependencyGraph is the set of objects reachable from a root object over dependencies.
+type DependencyGraph struct {
+	Root  string           `json:"root"`
+	Nodes []DependencyNode `json:"nodes"`
+	Edges []DependencyEdge `json:"edges"`
+}
+
+// DependencyWarning reports the objects a DDL statement would break.
+type DependencyWarning struct {
+	Action     string        `json:"action"` // e.g. "DROP", "RECREATE", "DROP COLUMN"
+	ObjectType string        `json:"object_type"`
+	Name       string        `json:"name"`
+	Column     string        `json:"column,omitempty"`
+	Dependents []ObjectUsage `json:"dependents"`
+	Message    string        `json:"message"`
+}
@@ -0,0 +1,59 @@
+package domain
+
+// SQLError is a Firebird error that points at a position in the submitted statement,
+// such as a syntax error or an unknown column in PSQL source.
+type SQLError struct {
+	Message string `json:"error"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+}
+
+func (e *SQLError) Error() string {
+	return e.Message
+}
+
+// PermissionError reports an action the connected user is not allowed to perform.
+type PermissionError struct {
+	Message string
+}
+
+func (e *PermissionError) Error() string {
+	return e.Message
+}
+
+// NotFoundError reports a database object that does not exist.
+type NotFoundError struct {
+	ObjectType string
+	Name       string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.ObjectType + " " + e.Name + " not found"
+}
+
+// ValidationError reports a request that cannot be carried out as submitted.
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// ConflictError reports an object that already exists.
+type ConflictError struct {
+	Message string
+}
+
+func (e *ConflictError) Error() string {
+	return e.Message
+}
+
+// UnsupportedError reports a feature the connected server version does not provide.
+type UnsupportedError struct {
+	Message string
+}
+
+func (e *UnsupportedError) Error() string {
+	return e.Message
+}
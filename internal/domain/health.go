@@ -0,0 +1,41 @@
+package domain
+
+// DatabaseHealth is the database header and transaction state with the warnings derived
+// from it.
+type DatabaseHealth struct {
+	Database          string                `json:"database"`
+	ODSVersion        string                `json:"ods_version"`
+	PageSize          int64                 `json:"page_size"`
+	Pages             int64                 `json:"pages"`
+	SQLDialect        int64                 `json:"sql_dialect"`
+	OldestTransaction int64                 `json:"oldest_transaction"` // OIT
+	OldestActive      int64                 `json:"oldest_active"`      // OAT
+	OldestSnapshot    int64                 `json:"oldest_snapshot"`    // OST
+	NextTransaction   int64                 `json:"next_transaction"`
+	OITGap            int64                 `json:"oit_gap"`        // OAT minus OIT, cleared by a sweep
+	OATGap            int64                 `json:"oat_gap"`        // Next transaction minus OAT, grows while a transaction stays open
+	OSTGap            int64                 `json:"ost_gap"`        // Next transaction minus OST, versions garbage collection cannot remove yet
+	SweepInterval     int64                 `json:"sweep_interval"` // 0 disables automatic sweep
+	PageBuffers       int64                 `json:"page_buffers"`   // 0 uses the server default
+	ForcedWrites      bool                  `json:"forced_writes"`
+	ReadOnly          bool                  `json:"read_only"`
+	ShutdownMode      string                `json:"shutdown_mode"` // "online", "multi", "single" or "full"
+	BackupState       string                `json:"backup_state"`  // "normal", "stalled" or "merge"
+	OldestActiveTx    *MonitoredTransaction `json:"oldest_active_transaction,omitempty"`
+	Warnings          []HealthWarning       `json:"warnings"`
+}
+
+// HealthWarning is a finding of the health report.
+type HealthWarning struct {
+	Level   string `json:"level"` // "info", "warning" or "critical"
+	Code    string `json:"code"`  // e.g. "OAT_GAP", "FORCED_WRITES_OFF"
+	Message string `json:"message"`
+}
+
+// HealthThresholds are the limits above which the health report warns. Zero values use
+// the defaults.
+type HealthThresholds struct {
+	OITGap         int64 `json:"oit_gap"`
+	OATGap         int64 `json:"oat_gap"`
+	TransactionAge int64 `json:"transaction_age"` // Seconds the oldest active transaction may run
+}
@@ -5,6 +5,15 @@ type ConnectionParams struct {
 	Database string `json:"database"` // e.g., "localhost:/var/lib/firebird/data/employee.fdb" or "my_alias"
 	User     string `json:"user"`
 	Password string `json:"password"`
+
+	// Connection options; empty values use the driver defaults.
+	Charset           string `json:"charset,omitempty"`     // Connection character set, UTF8 by default
+	Role              string `json:"role,omitempty"`        // SQL role
+	AuthPlugin        string `json:"auth_plugin,omitempty"` // "Srp256" (default), "Srp" or "Legacy_Auth"
+	WireCrypt         string `json:"wire_crypt,omitempty"`  // "enabled" (default), "required" or "disabled"
+	WireCompression   bool   `json:"wire_compression,omitempty"`
+	Timezone          string `json:"timezone,omitempty"` // Session time zone, e.g. "Europe/Berlin" or "+02:00"
+	ColumnNameToLower bool   `json:"column_name_to_lower,omitempty"`
 }
 
 // Table represents a database table metadata.
@@ -19,10 +28,16 @@ type Column struct {
 	ReadOnly bool   `json:"read_only"`
 }
 
-// ProcedureParameter represents a stored procedure input parameter.
+// ProcedureParameter represents a stored procedure or function parameter.
 type ProcedureParameter struct {
-	Name string `json:"name"`
-	Type string `json:"type"` // Simplified type name
+	Name        string `json:"name"`
+	Type        string `json:"type"`                // Declared type: SQL type, domain or TYPE OF reference
+	DataType    string `json:"data_type,omitempty"` // Underlying SQL type, also for domain based parameters
+	Direction   string `json:"direction,omitempty"` // "IN" or "OUT"
+	NotNull     bool   `json:"not_null,omitempty"`
+	Default     string `json:"default,omitempty"`
+	Collation   string `json:"collation,omitempty"`
+	Description string `json:"description,omitempty"`
 }
 
 // TableMetadata contains full metadata for autocompletion
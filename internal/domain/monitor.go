@@ -0,0 +1,80 @@
+package domain
+
+import "time"
+
+// IOStats holds page-level I/O counters (MON$IO_STATS) and memory usage (MON$MEMORY_USAGE).
+type IOStats struct {
+	Reads           int64 `json:"reads"`
+	Writes          int64 `json:"writes"`
+	Fetches         int64 `json:"fetches"`
+	Marks           int64 `json:"marks"`
+	MemoryUsed      int64 `json:"memory_used"`
+	MemoryAllocated int64 `json:"memory_allocated"`
+}
+
+// Attachment is a connection to the database (MON$ATTACHMENTS).
+type Attachment struct {
+	ID             int64     `json:"id"`
+	User           string    `json:"user"`
+	Role           string    `json:"role,omitempty"`
+	State          string    `json:"state"` // "active" or "idle"
+	RemoteProtocol string    `json:"remote_protocol,omitempty"`
+	RemoteAddress  string    `json:"remote_address,omitempty"`
+	RemoteHost     string    `json:"remote_host,omitempty"`
+	RemoteOSUser   string    `json:"remote_os_user,omitempty"`
+	RemoteProcess  string    `json:"remote_process,omitempty"`
+	RemotePID      int64     `json:"remote_pid,omitempty"`
+	ClientVersion  string    `json:"client_version,omitempty"`
+	AuthMethod     string    `json:"auth_method,omitempty"`
+	Started        time.Time `json:"started"`
+	Current        bool      `json:"current"` // The monitoring connection itself
+	IO             IOStats   `json:"io"`
+}
+
+// MonitoredTransaction is a running transaction (MON$TRANSACTIONS).
+type MonitoredTransaction struct {
+	ID            int64     `json:"id"`
+	AttachmentID  int64     `json:"attachment_id"`
+	User          string    `json:"user"`
+	RemoteAddress string    `json:"remote_address,omitempty"`
+	RemoteProcess string    `json:"remote_process,omitempty"`
+	State         string    `json:"state"`        // "active" or "idle"
+	Isolation     string    `json:"isolation"`    // e.g. "SNAPSHOT", "READ COMMITTED RECORD_VERSION"
+	LockTimeout   int64     `json:"lock_timeout"` // -1 waits forever, 0 is NO WAIT
+	ReadOnly      bool      `json:"read_only"`
+	AutoCommit    bool      `json:"auto_commit"`
+	Started       time.Time `json:"started"`
+	DurationMs    int64     `json:"duration_ms"`
+	IO            IOStats   `json:"io"`
+}
+
+// MonitoredStatement is a prepared or running statement (MON$STATEMENTS).
+type MonitoredStatement struct {
+	ID            int64     `json:"id"`
+	AttachmentID  int64     `json:"attachment_id"`
+	TransactionID int64     `json:"transaction_id,omitempty"`
+	User          string    `json:"user"`
+	RemoteAddress string    `json:"remote_address,omitempty"`
+	State         string    `json:"state"` // "idle", "active" or "stalled"
+	SQL           string    `json:"sql"`
+	ExplainedPlan string    `json:"explained_plan,omitempty"`
+	Started       time.Time `json:"started"`
+	DurationMs    int64     `json:"duration_ms"` // Running time of active and stalled statements
+	IO            IOStats   `json:"io"`
+}
+
+// MonitorOverview summarizes the database activity (MON$DATABASE).
+type MonitorOverview struct {
+	Database          string                `json:"database"`
+	OldestTransaction int64                 `json:"oldest_transaction"` // OIT
+	OldestActive      int64                 `json:"oldest_active"`      // OAT
+	OldestSnapshot    int64                 `json:"oldest_snapshot"`    // OST, the garbage collection threshold
+	NextTransaction   int64                 `json:"next_transaction"`
+	GarbageGap        int64                 `json:"garbage_gap"` // Next transaction minus oldest snapshot
+	PageBuffers       int64                 `json:"page_buffers"`
+	SweepInterval     int64                 `json:"sweep_interval"`
+	Attachments       int                   `json:"attachments"`
+	ActiveStatements  int                   `json:"active_statements"`
+	OldestActiveTx    *MonitoredTransaction `json:"oldest_active_transaction,omitempty"` // Who holds back garbage collection
+	IO                IOStats               `json:"io"`
+}
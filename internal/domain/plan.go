@@ -0,0 +1,21 @@
+package domain
+
+// QueryPlan is the access plan of a prepared statement.
+type QueryPlan struct {
+	Plan          string     `json:"plan"`           // Legacy PLAN clause(s), one line per select expression
+	ExplainedPlan string     `json:"explained_plan"` // Detailed plan text as reported by the server
+	Tree          []PlanNode `json:"tree"`           // Explained plan parsed into operations
+	NaturalScans  []string   `json:"natural_scans"`  // Tables read without an index
+	Indexes       []string   `json:"indexes"`        // Indexes used by the plan
+}
+
+// PlanNode is an operation of an explained plan, e.g. a join, a sort or a table access.
+type PlanNode struct {
+	Operation string     `json:"operation"`         // Line of the explained plan, e.g. `Table "EMPLOYEE" Full Scan`
+	Kind      string     `json:"kind"`              // e.g. "Select Expression", "Sort", "Nested Loop Join", "Table", "Index"
+	Object    string     `json:"object,omitempty"`  // Table, index or procedure name
+	Alias     string     `json:"alias,omitempty"`   // Table alias
+	Access    string     `json:"access,omitempty"`  // e.g. "Full Scan", "Access By ID", "Unique Scan", "Range Scan"
+	Details   string     `json:"details,omitempty"` // Parenthesized details, e.g. "inner" or "record length: 28, key length: 8"
+	Children  []PlanNode `json:"children,omitempty"`
+}
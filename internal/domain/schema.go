@@ -0,0 +1,310 @@
+package domain
+
+// Schema is a snapshot of all user-defined metadata of a database.
+// It is loaded in one pass and used to generate the full DDL script.
+type Schema struct {
+	DefaultCharset string              `json:"default_charset"`
+	Description    string              `json:"description,omitempty"`
+	Domains        []Domain            `json:"domains"`
+	Sequences      []Sequence          `json:"sequences"`
+	Exceptions     []DatabaseException `json:"exceptions"`
+	Tables         []TableDefinition   `json:"tables"`
+	Views          []ViewDefinition    `json:"views"`
+	Procedures     []Routine           `json:"procedures"`
+	Functions      []Routine           `json:"functions"`
+	Packages       []Package           `json:"packages"`
+	Triggers       []Trigger           `json:"triggers"`
+	Indexes        []Index             `json:"indexes"`
+	Roles          []Role              `json:"roles"`
+	Grants         []Grant             `json:"grants"`
+}
+
+// Domain represents a user-defined domain (RDB$FIELDS).
+type Domain struct {
+	Name        string        `json:"name"`
+	Type        string        `json:"type"`
+	NotNull     bool          `json:"not_null"`
+	Default     string        `json:"default,omitempty"` // e.g. "DEFAULT 0"
+	Check       string        `json:"check,omitempty"`   // e.g. "CHECK (VALUE > 0)"
+	Collation   string        `json:"collation,omitempty"`
+	UsedBy      []ObjectUsage `json:"used_by,omitempty"` // Only filled by the domain browser
+	Description string        `json:"description,omitempty"`
+}
+
+// DomainRequest is the body of a domain change. Nil fields keep their current value, an
+// empty default or check drops it and a different name renames the domain.
+type DomainRequest struct {
+	Name      *string `json:"name"`
+	Type      *string `json:"type"`
+	NotNull   *bool   `json:"not_null"`
+	Default   *string `json:"default"`
+	Check     *string `json:"check"`
+	Collation *string `json:"collation"`
+}
+
+// CharacterSet represents a character set (RDB$CHARACTER_SETS) with its collations.
+type CharacterSet struct {
+	Name              string   `json:"name"`
+	BytesPerCharacter int64    `json:"bytes_per_character"`
+	DefaultCollation  string   `json:"default_collation"`
+	Collations        []string `json:"collations,omitempty"`
+	Description       string   `json:"description,omitempty"`
+}
+
+// Collation represents a collation (RDB$COLLATIONS).
+type Collation struct {
+	Name               string `json:"name"`
+	CharacterSet       string `json:"character_set"`
+	BaseCollation      string `json:"base_collation,omitempty"` // FROM clause of user collations
+	PadSpace           bool   `json:"pad_space"`
+	CaseInsensitive    bool   `json:"case_insensitive"`
+	AccentInsensitive  bool   `json:"accent_insensitive"`
+	SpecificAttributes string `json:"specific_attributes,omitempty"` // e.g. "LOCALE=de_DE"
+	System             bool   `json:"system"`
+	Description        string `json:"description,omitempty"`
+}
+
+// Sequence represents a generator/sequence (RDB$GENERATORS).
+type Sequence struct {
+	Name         string        `json:"name"`
+	InitialValue int64         `json:"initial_value"`
+	Increment    int64         `json:"increment"`
+	CurrentValue *int64        `json:"current_value,omitempty"` // Only filled by the sequence browser
+	Identity     bool          `json:"identity,omitempty"`      // Internal generator of an identity column
+	UsedBy       []ObjectUsage `json:"used_by,omitempty"`
+	Description  string        `json:"description,omitempty"`
+}
+
+// ObjectUsage names an object that references another one, e.g. a trigger using a sequence.
+type ObjectUsage struct {
+	ObjectType string `json:"object_type"` // e.g. "TRIGGER", "PROCEDURE", "IDENTITY COLUMN"
+	Name       string `json:"name"`
+	Column     string `json:"column,omitempty"`
+}
+
+// DatabaseException represents a user exception (RDB$EXCEPTIONS).
+type DatabaseException struct {
+	Name        string        `json:"name"`
+	Number      int64         `json:"number,omitempty"`
+	Message     string        `json:"message"`
+	UsedBy      []ObjectUsage `json:"used_by,omitempty"` // Only filled by the exception browser
+	Description string        `json:"description,omitempty"`
+}
+
+// TableDefinition describes a table with its columns and constraints.
+type TableDefinition struct {
+	Name         string        `json:"name"`
+	Kind         string        `json:"kind"` // "TABLE", "EXTERNAL", "GTT_PRESERVE", "GTT_DELETE"
+	ExternalFile string        `json:"external_file,omitempty"`
+	Columns      []TableColumn `json:"columns"`
+	Constraints  []Constraint  `json:"constraints"`
+	Description  string        `json:"description,omitempty"`
+}
+
+// TableDesign is a table definition as edited in the table designer, with its indexes.
+type TableDesign struct {
+	TableDefinition
+	Indexes []Index `json:"indexes"`
+}
+
+// TableDesignResult lists the statements that create or alter a table to match a design.
+type TableDesignResult struct {
+	Statements []string       `json:"statements"`
+	Changes    []SchemaChange `json:"changes"`
+	Warnings   []string       `json:"warnings,omitempty"`
+	Executed   bool           `json:"executed"` // False for a dry run
+}
+
+// TableColumn describes a single table or view column.
+type TableColumn struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`             // Rendered SQL type or domain name
+	Domain      string `json:"domain,omitempty"` // Set when the column is based on a user domain
+	NotNull     bool   `json:"not_null"`
+	Default     string `json:"default,omitempty"`
+	Computed    string `json:"computed,omitempty"` // COMPUTED BY expression
+	Identity    string `json:"identity,omitempty"` // "ALWAYS" or "BY DEFAULT"
+	Collation   string `json:"collation,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Constraint describes a PRIMARY KEY, UNIQUE, FOREIGN KEY or CHECK constraint.
+type Constraint struct {
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Columns    []string `json:"columns,omitempty"`
+	Index      string   `json:"index,omitempty"`
+	Descending bool     `json:"descending,omitempty"`
+	RefTable   string   `json:"ref_table,omitempty"`
+	RefColumns []string `json:"ref_columns,omitempty"`
+	OnUpdate   string   `json:"on_update,omitempty"`
+	OnDelete   string   `json:"on_delete,omitempty"`
+	Check      string   `json:"check,omitempty"` // e.g. "CHECK (A > 0)"
+}
+
+// ViewDefinition describes a view and its select statement.
+type ViewDefinition struct {
+	Name        string              `json:"name"`
+	Columns     []TableColumn       `json:"columns"`
+	Source      string              `json:"source"`
+	DependsOn   []string            `json:"depends_on,omitempty"`   // Other views referenced by this view
+	UsesColumns map[string][]string `json:"uses_columns,omitempty"` // Table columns referenced by this view, by table
+	Updatable   bool                `json:"updatable,omitempty"`    // Only filled by the view browser
+	Description string              `json:"description,omitempty"`
+}
+
+// Routine describes a stored procedure or a stored function.
+type Routine struct {
+	Name          string               `json:"name"`
+	Package       string               `json:"package,omitempty"`
+	Kind          string               `json:"kind,omitempty"`    // "PSQL", "UDR" or "UDF"
+	Private       bool                 `json:"private,omitempty"` // Declared only in the package body
+	Inputs        []ProcedureParameter `json:"inputs"`
+	Outputs       []ProcedureParameter `json:"outputs,omitempty"`     // Procedure output parameters
+	Returns       string               `json:"returns,omitempty"`     // Function return type as declared
+	ReturnType    string               `json:"return_type,omitempty"` // Underlying SQL type of the function result
+	Source        string               `json:"source,omitempty"`
+	Selectable    bool                 `json:"selectable,omitempty"`
+	Deterministic bool                 `json:"deterministic,omitempty"`
+	Legacy        bool                 `json:"legacy,omitempty"` // DECLARE EXTERNAL FUNCTION (UDF)
+	ModuleName    string               `json:"module_name,omitempty"`
+	EntryPoint    string               `json:"entry_point,omitempty"`
+	Engine        string               `json:"engine,omitempty"`
+	SQLSecurity   string               `json:"sql_security,omitempty"` // "DEFINER", "INVOKER" or empty
+	Description   string               `json:"description,omitempty"`
+}
+
+// Package describes a PSQL package header and body.
+type Package struct {
+	Name        string    `json:"name"`
+	Header      string    `json:"header"`
+	Body        string    `json:"body,omitempty"`
+	ValidBody   bool      `json:"valid_body"` // False when there is no body or it must be recompiled after a header change
+	SQLSecurity string    `json:"sql_security,omitempty"`
+	Procedures  []Routine `json:"procedures,omitempty"`
+	Functions   []Routine `json:"functions,omitempty"`
+	Description string    `json:"description,omitempty"`
+}
+
+// Trigger describes a table, database or DDL trigger.
+type Trigger struct {
+	Name        string `json:"name"`
+	Relation    string `json:"relation,omitempty"`
+	Kind        string `json:"kind"`  // "TABLE", "DATABASE" or "DDL"
+	Event       string `json:"event"` // e.g. "BEFORE INSERT OR UPDATE", "ON CONNECT"
+	Position    int    `json:"position"`
+	Active      bool   `json:"active"`
+	Source      string `json:"source,omitempty"`
+	EntryPoint  string `json:"entry_point,omitempty"`
+	Engine      string `json:"engine,omitempty"`
+	SQLSecurity string `json:"sql_security,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Index describes a table index.
+type Index struct {
+	Name        string   `json:"name"`
+	Relation    string   `json:"relation"`
+	Unique      bool     `json:"unique"`
+	Descending  bool     `json:"descending"`
+	Active      bool     `json:"active"`
+	Segments    []string `json:"segments,omitempty"`
+	Expression  string   `json:"expression,omitempty"`
+	Constraint  string   `json:"constraint,omitempty"` // Constraint type when the index backs a constraint
+	Statistics  float64  `json:"statistics"`           // Selectivity from RDB$STATISTICS: 1 / distinct keys, 0 when never computed
+	Description string   `json:"description,omitempty"`
+}
+
+// IndexIssue is a finding of the index report.
+type IndexIssue struct {
+	Index       string  `json:"index"`
+	Relation    string  `json:"relation"`
+	Kind        string  `json:"kind"`                 // "DUPLICATE", "REDUNDANT" or "LOW_SELECTIVITY"
+	CoveredBy   string  `json:"covered_by,omitempty"` // Index that makes a duplicate or redundant one unnecessary
+	Selectivity float64 `json:"selectivity,omitempty"`
+	Message     string  `json:"message"`
+}
+
+// Role describes an SQL role.
+type Role struct {
+	Name        string `json:"name"`
+	Owner       string `json:"owner"`
+	Description string `json:"description,omitempty"`
+}
+
+// Grant describes a single row of RDB$USER_PRIVILEGES.
+type Grant struct {
+	Grantee     string `json:"grantee"`
+	GranteeType int    `json:"grantee_type"`
+	Grantor     string `json:"grantor"`
+	Privilege   string `json:"privilege"` // S, I, U, D, R, X, G, M, C, L, O
+	GrantOption int    `json:"grant_option"`
+	Object      string `json:"object"`
+	ObjectType  int    `json:"object_type"`
+	Field       string `json:"field,omitempty"`
+}
+
+// SchemaSource selects the schema for one side of a comparison:
+// either a live connection or a snapshot previously saved from GET /api/schema/snapshot.
+type SchemaSource struct {
+	Connection *ConnectionParams `json:"connection,omitempty"`
+	Snapshot   *Schema           `json:"snapshot,omitempty"`
+}
+
+// SchemaChange describes a single difference found by a schema comparison.
+type SchemaChange struct {
+	ObjectType string   `json:"object_type"` // e.g. "TABLE", "COLUMN", "CONSTRAINT", "VIEW"
+	Name       string   `json:"name"`
+	Parent     string   `json:"parent,omitempty"` // Owning table for columns, constraints and indexes
+	Action     string   `json:"action"`           // "CREATE", "ALTER" or "DROP"
+	Details    []string `json:"details,omitempty"`
+}
+
+// SchemaDiff is the result of comparing two schemas. Script migrates the target schema into the source schema.
+type SchemaDiff struct {
+	Changes  []SchemaChange `json:"changes"`
+	Warnings []string       `json:"warnings,omitempty"`
+	Script   string         `json:"script"`
+}
+
+// DataCompareOptions selects the tables and the row key of a data comparison.
+type DataCompareOptions struct {
+	Table       string   `json:"table"`
+	TargetTable string   `json:"target_table,omitempty"` // Defaults to Table
+	KeyColumns  []string `json:"key_columns,omitempty"`  // Defaults to the primary key of the source table
+	ChunkSize   int      `json:"chunk_size,omitempty"`   // Rows fetched per round trip
+	MaxRows     int      `json:"max_rows,omitempty"`     // Maximum number of row differences returned
+}
+
+// ColumnDifference is a single column value that differs between source and target.
+type ColumnDifference struct {
+	Column string      `json:"column"`
+	Source interface{} `json:"source"`
+	Target interface{} `json:"target"`
+}
+
+// RowDifference describes a row that has to be inserted, updated or deleted in the target
+// to make it match the source.
+type RowDifference struct {
+	Action  string                 `json:"action"` // "INSERT", "UPDATE" or "DELETE"
+	Key     map[string]interface{} `json:"key"`
+	Values  map[string]interface{} `json:"values,omitempty"` // Source row for inserts, target row for deletes
+	Changes []ColumnDifference     `json:"changes,omitempty"`
+}
+
+// DataDiff is the result of comparing the rows of two tables matched by key.
+type DataDiff struct {
+	Table       string          `json:"table"`
+	TargetTable string          `json:"target_table"`
+	KeyColumns  []string        `json:"key_columns"`
+	Columns     []TableColumn   `json:"columns"` // Compared columns
+	SourceRows  int64           `json:"source_rows"`
+	TargetRows  int64           `json:"target_rows"`
+	Inserted    int64           `json:"inserted"`
+	Updated     int64           `json:"updated"`
+	Deleted     int64           `json:"deleted"`
+	Unchanged   int64           `json:"unchanged"`
+	Rows        []RowDifference `json:"rows"`
+	Truncated   bool            `json:"truncated,omitempty"` // More differences exist than returned in Rows
+	Warnings    []string        `json:"warnings,omitempty"`
+}
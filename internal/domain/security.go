@@ -0,0 +1,56 @@
+package domain
+
+// User is a login of the security database (SEC$USERS).
+type User struct {
+	Name        string            `json:"name"`
+	FirstName   string            `json:"first_name,omitempty"`
+	MiddleName  string            `json:"middle_name,omitempty"`
+	LastName    string            `json:"last_name,omitempty"`
+	Plugin      string            `json:"plugin"` // User manager, e.g. "Srp" or "Legacy_UserManager"
+	Active      bool              `json:"active"`
+	Admin       bool              `json:"admin"` // Has the RDB$ADMIN role in the security database
+	Description string            `json:"description,omitempty"`
+	Tags        map[string]string `json:"tags"`
+}
+
+// UserRequest creates or alters a user. Nil fields are left unchanged when altering.
+type UserRequest struct {
+	Name       string            `json:"name"`               // Used as is; logins typed without quotes are upper case
+	Password   string            `json:"password,omitempty"` // Required to create; empty keeps the password
+	FirstName  *string           `json:"first_name,omitempty"`
+	MiddleName *string           `json:"middle_name,omitempty"`
+	LastName   *string           `json:"last_name,omitempty"`
+	Active     *bool             `json:"active,omitempty"`
+	Admin      *bool             `json:"admin,omitempty"`
+	Plugin     string            `json:"plugin,omitempty"` // Empty uses the first plugin of UserManager
+	Tags       map[string]string `json:"tags,omitempty"`   // An empty value drops the tag
+}
+
+// PrivilegeMatrix lists who holds which privileges on one table, view or routine.
+type PrivilegeMatrix struct {
+	ObjectType string              `json:"object_type"` // "table", "view", "procedure", "function" or "package"
+	Object     string              `json:"object"`
+	Grantees   []GranteePrivileges `json:"grantees"`
+}
+
+// GranteePrivileges is one row of a privilege matrix.
+type GranteePrivileges struct {
+	Grantee     string            `json:"grantee"`
+	GranteeType string            `json:"grantee_type"` // "USER", "ROLE", "PROCEDURE", "TRIGGER", "FUNCTION", "PACKAGE" or "VIEW"
+	Privileges  []ObjectPrivilege `json:"privileges"`
+}
+
+// ObjectPrivilege is a privilege held on the object or, for UPDATE and REFERENCES, on one
+// of its columns.
+type ObjectPrivilege struct {
+	Privilege   string `json:"privilege"` // S, I, U, D, R or X
+	Column      string `json:"column,omitempty"`
+	GrantOption bool   `json:"grant_option"`
+}
+
+// PrivilegeChanges are the GRANT and REVOKE statements that turn the current privileges
+// into the desired ones.
+type PrivilegeChanges struct {
+	Statements []string `json:"statements"`
+	Executed   bool     `json:"executed"` // False for a dry run
+}
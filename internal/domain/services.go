@@ -0,0 +1,61 @@
+package domain
+
+// BackupRequest starts a gbak backup through the Services Manager.
+type BackupRequest struct {
+	BackupFile       string `json:"backup_file"`        // Path of the backup file on the server
+	MetadataOnly     bool   `json:"metadata_only"`      // gbak -m
+	NoGarbageCollect bool   `json:"no_garbage_collect"` // gbak -g
+	NonTransportable bool   `json:"non_transportable"`  // gbak -nt; the transportable (XDR) format is the default
+	IgnoreChecksums  bool   `json:"ignore_checksums"`   // gbak -ig
+	IgnoreLimbo      bool   `json:"ignore_limbo"`       // gbak -l
+}
+
+// RestoreRequest starts a gbak restore through the Services Manager.
+type RestoreRequest struct {
+	BackupFile        string `json:"backup_file"`        // Path of the backup file on the server
+	Database          string `json:"database"`           // Path or alias to restore to; empty restores the connected database
+	Replace           bool   `json:"replace"`            // gbak -rep; otherwise the database must not exist (gbak -c)
+	PageSize          int32  `json:"page_size"`          // 0 keeps the page size of the backup
+	DeactivateIndexes bool   `json:"deactivate_indexes"` // gbak -i
+	NoValidity        bool   `json:"no_validity"`        // gbak -n, skips CHECK and NOT NULL validation
+	OneAtATime        bool   `json:"one_at_a_time"`      // gbak -o, commits after each table
+}
+
+// ServiceResult is the outcome of a maintenance service call.
+type ServiceResult struct {
+	Action     string   `json:"action"`
+	Database   string   `json:"database"`
+	DurationMs float64  `json:"duration_ms"`
+	Output     []string `json:"output"` // Service output or the changes made
+}
+
+// ValidationRequest runs a database validation (gfix -v).
+type ValidationRequest struct {
+	Full            bool `json:"full"`             // gfix -full, also checks record structures
+	ReadOnly        bool `json:"read_only"`        // gfix -n, leaves orphan pages allocated
+	IgnoreChecksums bool `json:"ignore_checksums"` // gfix -i
+	Online          bool `json:"online"`           // Online validation (Firebird 3+), no exclusive access needed
+}
+
+// ValidationReport is the result of a validation with the errors it found.
+type ValidationReport struct {
+	ServiceResult
+	Errors     map[string]int64 `json:"errors"` // Errors per kind, e.g. "record level" or "RDB$PAGES"
+	ErrorCount int64            `json:"error_count"`
+	Valid      bool             `json:"valid"`
+}
+
+// ShutdownRequest shuts the database down (gfix -shut).
+type ShutdownRequest struct {
+	Mode    string `json:"mode"`    // "force", "attachments" or "transactions"
+	State   string `json:"state"`   // "multi" (default), "single" or "full"
+	Timeout int    `json:"timeout"` // Seconds to wait for attachments or transactions to end
+}
+
+// DatabaseProperties changes database header settings; nil fields are left unchanged.
+type DatabaseProperties struct {
+	SweepInterval *int64 `json:"sweep_interval,omitempty"`
+	ForcedWrites  *bool  `json:"forced_writes,omitempty"`
+	PageBuffers   *int64 `json:"page_buffers,omitempty"`
+	ReadOnly      *bool  `json:"read_only,omitempty"`
+}
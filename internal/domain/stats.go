@@ -0,0 +1,29 @@
+package domain
+
+// QueryStats describes the cost of one statement execution. I/O and record counters are
+// the differences of the attachment's monitoring counters before and after the statement;
+// they are only read when requested, Tables is nil otherwise.
+type QueryStats struct {
+	PrepareMs   float64      `json:"prepare_ms"`
+	ExecuteMs   float64      `json:"execute_ms"`
+	FetchMs     float64      `json:"fetch_ms"`
+	RowsFetched int          `json:"rows_fetched"`
+	Reads       int64        `json:"reads"`   // Pages read from disk
+	Writes      int64        `json:"writes"`  // Pages written to disk
+	Fetches     int64        `json:"fetches"` // Pages fetched from the page cache
+	Marks       int64        `json:"marks"`   // Pages marked dirty
+	Tables      []TableStats `json:"tables"`  // Record counters per table; empty before Firebird 3
+}
+
+// TableStats holds the record-level counters of one table.
+type TableStats struct {
+	Table    string `json:"table"`
+	SeqReads int64  `json:"seq_reads"` // Records read by natural scans
+	IdxReads int64  `json:"idx_reads"` // Records read through indexes
+	Inserts  int64  `json:"inserts"`
+	Updates  int64  `json:"updates"`
+	Deletes  int64  `json:"deletes"`
+	Backouts int64  `json:"backouts"`
+	Purges   int64  `json:"purges"`
+	Expunges int64  `json:"expunges"`
+}
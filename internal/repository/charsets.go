@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"database/sql"
+	"firebird-web-admin/internal/domain"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// RDB$COLLATION_ATTRIBUTES flags.
+const (
+	collationPadSpace          = 1
+	collationCaseInsensitive   = 2
+	collationAccentInsensitive = 4
+)
+
+// ListCharacterSets returns the character sets with their default collation and collations.
+func (r *FirebirdRepository) ListCharacterSets(params domain.ConnectionParams) ([]domain.CharacterSet, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		SELECT RDB$CHARACTER_SET_NAME, RDB$BYTES_PER_CHARACTER, RDB$DEFAULT_COLLATE_NAME, RDB$DESCRIPTION
+		FROM RDB$CHARACTER_SETS
+		ORDER BY RDB$CHARACTER_SET_NAME
+	`)
+	if err != nil {
+		log.Printf("ListCharacterSets error: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var charsets []domain.CharacterSet
+	index := make(map[string]int)
+	for rows.Next() {
+		var name string
+		var bytesPerChar sql.NullInt64
+		var defaultCollation, description sql.NullString
+		if err := rows.Scan(&name, &bytesPerChar, &defaultCollation, &description); err != nil {
+			return nil, err
+		}
+		cs := domain.CharacterSet{
+			Name:              strings.TrimSpace(name),
+			BytesPerCharacter: bytesPerChar.Int64,
+			DefaultCollation:  strings.TrimSpace(defaultCollation.String),
+			Description:       description.String,
+		}
+		index[cs.Name] = len(charsets)
+		charsets = append(charsets, cs)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	collations, err := loadCollations(db)
+	if err != nil {
+		log.Printf("ListCharacterSets collations error: %v", err)
+		return nil, err
+	}
+	for _, c := range collations {
+		if i, ok := index[c.CharacterSet]; ok {
+			charsets[i].Collations = append(charsets[i].Collations, c.Name)
+		}
+	}
+	return charsets, nil
+}
+
+func (r *FirebirdRepository) GetCharacterSet(params domain.ConnectionParams, name string) (*domain.CharacterSet, error) {
+	name = objectName(name)
+	charsets, err := r.ListCharacterSets(params)
+	if err != nil {
+		return nil, err
+	}
+	for _, cs := range charsets {
+		if cs.Name == name {
+			return &cs, nil
+		}
+	}
+	return nil, &domain.NotFoundError{ObjectType: "character set", Name: name}
+}
+
+// GetCharacterSetDDL returns the statement that sets the default collation of a character set.
+// Character sets are built in; their default collation is the only thing that can be changed.
+func (r *FirebirdRepository) GetCharacterSetDDL(params domain.ConnectionParams, name string) (string, error) {
+	cs, err := r.GetCharacterSet(params, name)
+	if err != nil {
+		return "", err
+	}
+	return characterSetSQL(cs.Name, cs.DefaultCollation), nil
+}
+
+func (r *FirebirdRepository) SetDefaultCollation(params domain.ConnectionParams, charset string, collation string) error {
+	if strings.TrimSpace(collation) == "" {
+		return invalidf("collation is required")
+	}
+	return r.execDDL(params, characterSetSQL(objectName(charset), strings.TrimSpace(collation)))
+}
+
+// ListCollations returns system and user collations.
+func (r *FirebirdRepository) ListCollations(params domain.ConnectionParams) ([]domain.Collation, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	collations, err := loadCollations(db)
+	if err != nil {
+		log.Printf("ListCollations error: %v", err)
+		return nil, err
+	}
+	return collations, nil
+}
+
+func (r *FirebirdRepository) GetCollation(params domain.ConnectionParams, name string) (*domain.Collation, error) {
+	name = objectName(name)
+	collations, err := r.ListCollations(params)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range collations {
+		if c.Name == name {
+			return &c, nil
+		}
+	}
+	return nil, &domain.NotFoundError{ObjectType: "collation", Name: name}
+}
+
+// GetCollationDDL returns the CREATE COLLATION statement of a user collation.
+func (r *FirebirdRepository) GetCollationDDL(params domain.ConnectionParams, name string) (string, error) {
+	c, err := r.GetCollation(params, name)
+	if err != nil {
+		return "", err
+	}
+	if c.System {
+		return "", invalidf("collation %s is built in and has no DDL", c.Name)
+	}
+	return collationSQL(*c), nil
+}
+
+// CreateCollation creates a user collation. Firebird has no ALTER COLLATION,
+// so a collation is changed by dropping and creating it while nothing uses it.
+func (r *FirebirdRepository) CreateCollation(params domain.ConnectionParams, c domain.Collation) error {
+	c.Name = strings.TrimSpace(c.Name)
+	switch {
+	case c.Name == "":
+		return invalidf("collation name is required")
+	case strings.TrimSpace(c.CharacterSet) == "":
+		return invalidf("character set is required")
+	}
+	return r.execDDL(params, collationSQL(c))
+}
+
+func (r *FirebirdRepository) DropCollation(params domain.ConnectionParams, name string) error {
+	return r.execDDL(params, "DROP COLLATION "+quoteIdent(objectName(name)))
+}
+
+func loadCollations(db *sql.DB) ([]domain.Collation, error) {
+	rows, err := db.Query(`
+		SELECT co.RDB$COLLATION_NAME, cs.RDB$CHARACTER_SET_NAME, co.RDB$BASE_COLLATION_NAME,
+			co.RDB$COLLATION_ATTRIBUTES, co.RDB$SPECIFIC_ATTRIBUTES, co.RDB$SYSTEM_FLAG, co.RDB$DESCRIPTION
+		FROM RDB$COLLATIONS co
+		JOIN RDB$CHARACTER_SETS cs ON cs.RDB$CHARACTER_SET_ID = co.RDB$CHARACTER_SET_ID
+		ORDER BY cs.RDB$CHARACTER_SET_NAME, co.RDB$COLLATION_ID
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var collations []domain.Collation
+	for rows.Next() {
+		var name, charset string
+		var base, specific, description sql.NullString
+		var attributes, systemFlag sql.NullInt64
+		if err := rows.Scan(&name, &charset, &base, &attributes, &specific, &systemFlag, &description); err != nil {
+			return nil, err
+		}
+		collations = append(collations, domain.Collation{
+			Name:               strings.TrimSpace(name),
+			CharacterSet:       strings.TrimSpace(charset),
+			BaseCollation:      strings.TrimSpace(base.String),
+			PadSpace:           attributes.Int64&collationPadSpace != 0,
+			CaseInsensitive:    attributes.Int64&collationCaseInsensitive != 0,
+			AccentInsensitive:  attributes.Int64&collationAccentInsensitive != 0,
+			SpecificAttributes: strings.TrimSpace(specific.String),
+			System:             systemFlag.Int64 != 0,
+			Description:        description.String,
+		})
+	}
+	return collations, rows.Err()
+}
+
+func characterSetSQL(charset string, collation string) string {
+	return fmt.Sprintf("ALTER CHARACTER SET %s SET DEFAULT COLLATION %s", quoteIdent(charset), quoteIdent(collation))
+}
+
+func collationSQL(c domain.Collation) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "CREATE COLLATION %s FOR %s", quoteIdent(c.Name), quoteIdent(c.CharacterSet))
+	if c.BaseCollation != "" {
+		sb.WriteString(" FROM " + quoteIdent(c.BaseCollation))
+	}
+	if c.PadSpace {
+		sb.WriteString(" PAD SPACE")
+	} else {
+		sb.WriteString(" NO PAD")
+	}
+	if c.CaseInsensitive {
+		sb.WriteString(" CASE INSENSITIVE")
+	}
+	if c.AccentInsensitive {
+		sb.WriteString(" ACCENT INSENSITIVE")
+	}
+	if c.SpecificAttributes != "" {
+		sb.WriteString(" " + quoteString(c.SpecificAttributes))
+	}
+	return sb.String()
+}
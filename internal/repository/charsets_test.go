@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"firebird-web-admin/internal/domain"
+	"testing"
+)
+
+func TestCharacterSetSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		got      string
+		expected string
+	}{
+		{name: "Default collation", got: characterSetSQL("UTF8", "UNICODE_CI_AI"), expected: `ALTER CHARACTER SET "UTF8" SET DEFAULT COLLATION "UNICODE_CI_AI"`},
+		{name: "Quoted collation", got: characterSetSQL("WIN1251", "My \"Coll\""), expected: `ALTER CHARACTER SET "WIN1251" SET DEFAULT COLLATION "My ""Coll"""`},
+		{name: "Collation without base", got: collationSQL(domain.Collation{Name: "UTF8_NOPAD", CharacterSet: "UTF8"}), expected: `CREATE COLLATION "UTF8_NOPAD" FOR "UTF8" NO PAD`},
+		{name: "Accent insensitive collation", got: collationSQL(domain.Collation{Name: "UNICODE_AI", CharacterSet: "UTF8", BaseCollation: "UNICODE", PadSpace: true, AccentInsensitive: true}), expected: `CREATE COLLATION "UNICODE_AI" FOR "UTF8" FROM "UNICODE" PAD SPACE ACCENT INSENSITIVE`},
+		{name: "Quoted specific attributes", got: collationSQL(domain.Collation{Name: "C", CharacterSet: "UTF8", BaseCollation: "UNICODE", SpecificAttributes: "LOCALE=it_IT;X='1'"}), expected: `CREATE COLLATION "C" FOR "UTF8" FROM "UNICODE" NO PAD 'LOCALE=it_IT;X=''1'''`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.expected {
+				t.Errorf("got %q, want %q", tt.got, tt.expected)
+			}
+		})
+	}
+}
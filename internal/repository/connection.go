@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"database/sql"
+	"firebird-web-admin/internal/domain"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/nakagami/firebirdsql"
+)
+
+// authPlugins maps the accepted auth plugin names to the names the driver uses.
+var authPlugins = map[string]string{
+	"srp256":      "Srp256",
+	"srp":         "Srp",
+	"legacy":      "Legacy_Auth",
+	"legacy_auth": "Legacy_Auth",
+}
+
+var (
+	charsetName  = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+	timezoneName = regexp.MustCompile(`^[A-Za-z0-9_/+:-]+$`)
+)
+
+// validateConnectionOptions rejects connection options the driver cannot honour.
+func validateConnectionOptions(params domain.ConnectionParams) error {
+	if params.Charset != "" && !charsetName.MatchString(params.Charset) {
+		return invalidf("invalid character set %q", params.Charset)
+	}
+	if _, ok := authPlugins[strings.ToLower(params.AuthPlugin)]; params.AuthPlugin != "" && !ok {
+		return invalidf("unknown auth plugin %q, use Srp256, Srp or Legacy_Auth", params.AuthPlugin)
+	}
+	switch params.WireCrypt {
+	case "", "enabled", "required", "disabled":
+	default:
+		return invalidf("invalid wire crypt mode %q, use enabled, required or disabled", params.WireCrypt)
+	}
+	if params.WireCrypt == "required" && params.AuthPlugin != "" && authPlugins[strings.ToLower(params.AuthPlugin)] == "Legacy_Auth" {
+		return invalidf("wire encryption needs an SRP auth plugin, Legacy_Auth cannot provide the key")
+	}
+	if params.WireCompression {
+		// The pure-Go driver does not implement the zlib wire protocol (isc_dpb_config WireCompression).
+		return invalidf("wire compression is not supported by the Firebird driver")
+	}
+	if params.Timezone != "" && !timezoneName.MatchString(params.Timezone) {
+		return invalidf("invalid time zone %q", params.Timezone)
+	}
+	return nil
+}
+
+// connectionOptions returns the DSN query parameters for the options that differ from the
+// driver defaults.
+func connectionOptions(params domain.ConnectionParams) url.Values {
+	v := url.Values{}
+	if params.Charset != "" {
+		v.Set("charset", strings.ToUpper(params.Charset))
+	}
+	if params.Role != "" {
+		v.Set("role", params.Role)
+	}
+	if plugin := authPlugins[strings.ToLower(params.AuthPlugin)]; plugin != "" {
+		v.Set("auth_plugin_name", plugin)
+	}
+	if params.WireCrypt == "disabled" {
+		v.Set("wire_crypt", "false")
+	}
+	if params.Timezone != "" {
+		v.Set("timezone", params.Timezone)
+	}
+	if params.ColumnNameToLower {
+		v.Set("column_name_to_lower", "true")
+	}
+	return v
+}
+
+// serviceManagerOptions returns the auth plugin and wire crypt settings of params for
+// Services Manager connections.
+func serviceManagerOptions(params domain.ConnectionParams) firebirdsql.ServiceManagerOptions {
+	opts := firebirdsql.GetDefaultServiceManagerOptions()
+	if plugin := authPlugins[strings.ToLower(params.AuthPlugin)]; plugin != "" {
+		opts = opts.WithAuthPlugin(plugin)
+	}
+	if params.WireCrypt == "disabled" {
+		opts = opts.WithoutWireCrypt()
+	}
+	return opts
+}
+
+// checkWireEncryption fails when the attachment is not encrypted. The driver falls back to
+// plain text when the server does not offer encryption, so "required" is verified after
+// connecting (Firebird 4 and later report it).
+func checkWireEncryption(db *sql.DB) error {
+	var encrypted sql.NullString
+	err := db.QueryRow(`SELECT RDB$GET_CONTEXT('SYSTEM', 'WIRE_ENCRYPTED') FROM RDB$DATABASE`).Scan(&encrypted)
+	if err != nil {
+		return fmt.Errorf("cannot verify wire encryption: %v", err)
+	}
+	if encrypted.String != "TRUE" {
+		return fmt.Errorf("the server did not encrypt the connection, wire encryption is required")
+	}
+	return nil
+}
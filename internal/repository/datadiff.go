@@ -0,0 +1,544 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"firebird-web-admin/internal/domain"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	defaultDataChunkSize = 1000
+	maxDataChunkSize     = 50000
+)
+
+// dataRow is a fetched row. Values are normalized for comparison and JSON output,
+// keyRaw keeps the key values as returned by the driver for the next keyset query.
+type dataRow struct {
+	key    string
+	keyRaw []interface{}
+	values []interface{}
+}
+
+// dataComparison holds the state of a running CompareTableData call.
+type dataComparison struct {
+	diff *domain.DataDiff
+	keys []int // Positions of the key columns in diff.Columns
+	emit func(*domain.DataDiff, domain.RowDifference) error
+}
+
+// CompareTableData matches the rows of the source and target tables by key and calls emit for every
+// row that differs. The source is read in key order one chunk at a time and each chunk is compared with
+// the target rows of the same key range, read in pages of the same size, so memory use does not depend
+// on the table size.
+// The returned diff holds the counters; collecting Rows is left to emit.
+func (r *FirebirdRepository) CompareTableData(source, target domain.ConnectionParams, opts domain.DataCompareOptions, emit func(*domain.DataDiff, domain.RowDifference) error) (*domain.DataDiff, error) {
+	if opts.TargetTable == "" {
+		opts.TargetTable = opts.Table
+	}
+	chunk := opts.ChunkSize
+	if chunk <= 0 {
+		chunk = defaultDataChunkSize
+	} else if chunk > maxDataChunkSize {
+		chunk = maxDataChunkSize
+	}
+
+	srcConnStr, err := r.getConnectionString(source)
+	if err != nil {
+		return nil, err
+	}
+	tgtConnStr, err := r.getConnectionString(target)
+	if err != nil {
+		return nil, err
+	}
+	srcDB, err := sql.Open("firebirdsql", srcConnStr)
+	if err != nil {
+		return nil, err
+	}
+	defer srcDB.Close()
+	tgtDB, err := sql.Open("firebirdsql", tgtConnStr)
+	if err != nil {
+		return nil, err
+	}
+	defer tgtDB.Close()
+
+	srcColumns, primaryKey, err := loadDataColumns(srcDB, opts.Table)
+	if err != nil {
+		return nil, fmt.Errorf("source: %w", err)
+	}
+	tgtColumns, _, err := loadDataColumns(tgtDB, opts.TargetTable)
+	if err != nil {
+		return nil, fmt.Errorf("target: %w", err)
+	}
+
+	keyNames := opts.KeyColumns
+	if len(keyNames) == 0 {
+		keyNames = primaryKey
+	}
+	columns, keys, warnings, err := matchDataColumns(srcColumns, tgtColumns, keyNames)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &dataComparison{
+		diff: &domain.DataDiff{
+			Table:       opts.Table,
+			TargetTable: opts.TargetTable,
+			KeyColumns:  keyNames,
+			Columns:     columns,
+			Warnings:    warnings,
+		},
+		keys: keys,
+		emit: emit,
+	}
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = col.Name
+	}
+
+	var last []interface{}
+	for {
+		query := dataRowsQuery(opts.Table, names, keyNames, chunk, last != nil, false)
+		srcRows, err := c.readRows(srcDB, query, keysetArgs(last))
+		if err != nil {
+			log.Printf("CompareTableData source error: %v", err)
+			return nil, fmt.Errorf("reading source: %w", err)
+		}
+		if len(srcRows) == 0 {
+			break
+		}
+
+		// The target rows of the chunk's key range are paged as well: where the source keys
+		// are sparse and the target keys dense, the range can hold most of the target table.
+		src := c.newChunk(srcRows)
+		upper := srcRows[len(srcRows)-1].keyRaw
+		for tgtLast := last; ; {
+			query = dataRowsQuery(opts.TargetTable, names, keyNames, chunk, tgtLast != nil, true)
+			tgtRows, err := c.readRows(tgtDB, query, append(keysetArgs(tgtLast), keysetArgs(upper)...))
+			if err != nil {
+				log.Printf("CompareTableData target error: %v", err)
+				return nil, fmt.Errorf("reading target: %w", err)
+			}
+			if err := c.compareTarget(src, tgtRows); err != nil {
+				return nil, err
+			}
+			if len(tgtRows) < chunk {
+				break
+			}
+			tgtLast = tgtRows[len(tgtRows)-1].keyRaw
+		}
+		if err := c.finishChunk(src); err != nil {
+			return nil, err
+		}
+
+		last = upper
+		if len(srcRows) < chunk {
+			break
+		}
+	}
+
+	// Target rows after the last source key do not exist in the source at all.
+	for {
+		query := dataRowsQuery(opts.TargetTable, names, keyNames, chunk, last != nil, false)
+		tgtRows, err := c.readRows(tgtDB, query, keysetArgs(last))
+		if err != nil {
+			log.Printf("CompareTableData target error: %v", err)
+			return nil, fmt.Errorf("reading target: %w", err)
+		}
+		if len(tgtRows) == 0 {
+			break
+		}
+		if err := c.compareTarget(nil, tgtRows); err != nil {
+			return nil, err
+		}
+		last = tgtRows[len(tgtRows)-1].keyRaw
+		if len(tgtRows) < chunk {
+			break
+		}
+	}
+
+	return c.diff, nil
+}
+
+// loadDataColumns returns the columns and the primary key of a table or view.
+func loadDataColumns(db *sql.DB, table string) ([]domain.TableColumn, []string, error) {
+	charset, err := loadDefaultCharset(db)
+	if err != nil {
+		return nil, nil, err
+	}
+	columns, err := loadRelationColumns(db, charset, table)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(columns[table]) == 0 {
+		return nil, nil, &domain.NotFoundError{ObjectType: "table", Name: table}
+	}
+
+	constraints, err := loadConstraints(db, table)
+	if err != nil {
+		return nil, nil, err
+	}
+	var primaryKey []string
+	for _, c := range constraints[table] {
+		if c.Type == "PRIMARY KEY" {
+			primaryKey = c.Columns
+		}
+	}
+	return columns[table], primaryKey, nil
+}
+
+// matchDataColumns returns the columns present in both tables, using the target definition,
+// and the positions of the key columns among them. Computed columns are never compared.
+func matchDataColumns(source, target []domain.TableColumn, keyNames []string) ([]domain.TableColumn, []int, []string, error) {
+	if len(keyNames) == 0 {
+		return nil, nil, nil, invalidf("the source table has no primary key; specify key_columns")
+	}
+
+	targetByName := make(map[string]domain.TableColumn, len(target))
+	for _, col := range target {
+		targetByName[col.Name] = col
+	}
+	sourceByName := make(map[string]domain.TableColumn, len(source))
+	for _, col := range source {
+		sourceByName[col.Name] = col
+	}
+
+	var columns []domain.TableColumn
+	var warnings []string
+	for _, src := range source {
+		if src.Computed != "" {
+			continue
+		}
+		tgt, ok := targetByName[src.Name]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("Column %s exists only in the source table and is not compared", src.Name))
+			continue
+		}
+		if tgt.Computed != "" {
+			warnings = append(warnings, fmt.Sprintf("Column %s is computed in the target table and is not compared", src.Name))
+			continue
+		}
+		if src.Type != tgt.Type {
+			warnings = append(warnings, fmt.Sprintf("Column %s is %s in the source table and %s in the target table", src.Name, src.Type, tgt.Type))
+		}
+		columns = append(columns, tgt)
+	}
+	for _, tgt := range target {
+		if _, ok := sourceByName[tgt.Name]; !ok && tgt.Computed == "" {
+			warnings = append(warnings, fmt.Sprintf("Column %s exists only in the target table and is not compared", tgt.Name))
+		}
+	}
+
+	keys := make([]int, len(keyNames))
+	for i, name := range keyNames {
+		keys[i] = -1
+		for j, col := range columns {
+			if col.Name == name {
+				keys[i] = j
+			}
+		}
+		if keys[i] == -1 {
+			return nil, nil, nil, invalidf("key column %s is not present in both tables", name)
+		}
+	}
+	return columns, keys, warnings, nil
+}
+
+// dataRowsQuery builds a query reading table in key order. lower restricts it to keys after
+// a keyset bound, upper to keys up to and including one. first <= 0 reads all matching rows.
+func dataRowsQuery(table string, columns, keys []string, first int, lower, upper bool) string {
+	var sb strings.Builder
+	sb.WriteString("SELECT ")
+	if first > 0 {
+		fmt.Fprintf(&sb, "FIRST %d ", first)
+	}
+	sb.WriteString(quoteIdentList(columns))
+	sb.WriteString(" FROM ")
+	sb.WriteString(quoteIdent(table))
+
+	var conditions []string
+	if lower {
+		conditions = append(conditions, keysetCondition(keys, ">"))
+	}
+	if upper {
+		conditions = append(conditions, keysetCondition(keys, "<="))
+	}
+	if len(conditions) > 0 {
+		sb.WriteString(" WHERE (" + strings.Join(conditions, ") AND (") + ")")
+	}
+	sb.WriteString(" ORDER BY ")
+	sb.WriteString(quoteIdentList(keys))
+	return sb.String()
+}
+
+// keysetCondition renders the row value comparison (k1, k2, ...) op (?, ?, ...), which Firebird
+// does not support, as the equivalent OR chain. op is ">" or "<=".
+func keysetCondition(keys []string, op string) string {
+	strict := op
+	if op == "<=" {
+		strict = "<"
+	}
+	terms := make([]string, len(keys))
+	for i := range keys {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, quoteIdent(keys[j])+" = ?")
+		}
+		termOp := strict
+		if i == len(keys)-1 {
+			termOp = op
+		}
+		parts = append(parts, quoteIdent(keys[i])+" "+termOp+" ?")
+		terms[i] = "(" + strings.Join(parts, " AND ") + ")"
+	}
+	return strings.Join(terms, " OR ")
+}
+
+// keysetArgs returns the arguments of keysetCondition for the given key values: k1, then k1, k2, and so on.
+func keysetArgs(values []interface{}) []interface{} {
+	var args []interface{}
+	for i := range values {
+		args = append(args, values[:i+1]...)
+	}
+	return args
+}
+
+func (c *dataComparison) readRows(db *sql.DB, query string, args []interface{}) ([]dataRow, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []dataRow
+	for rows.Next() {
+		raw := make([]interface{}, len(c.diff.Columns))
+		ptrs := make([]interface{}, len(raw))
+		for i := range raw {
+			ptrs[i] = &raw[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+
+		row := dataRow{
+			keyRaw: make([]interface{}, len(c.keys)),
+			values: make([]interface{}, len(raw)),
+		}
+		for i, v := range raw {
+			row.values[i] = normalizeDataValue(v, c.diff.Columns[i].Type)
+		}
+		parts := make([]string, len(c.keys))
+		for i, idx := range c.keys {
+			row.keyRaw[i] = raw[idx]
+			parts[i] = fmt.Sprint(row.values[idx])
+		}
+		row.key = strings.Join(parts, "\x00")
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// sourceChunk is a chunk of source rows waiting for the target rows of its key range.
+type sourceChunk struct {
+	rows      []dataRow
+	unmatched map[string]dataRow // Rows no target row has matched yet, by key
+}
+
+func (c *dataComparison) newChunk(source []dataRow) *sourceChunk {
+	c.diff.SourceRows += int64(len(source))
+	chunk := &sourceChunk{rows: source, unmatched: make(map[string]dataRow, len(source))}
+	for _, row := range source {
+		chunk.unmatched[row.key] = row
+	}
+	return chunk
+}
+
+// compareTarget compares a page of target rows with the source rows of the same key range.
+// Target rows without a source row are deleted; a nil chunk means there are none.
+func (c *dataComparison) compareTarget(chunk *sourceChunk, target []dataRow) error {
+	c.diff.TargetRows += int64(len(target))
+
+	var unmatched map[string]dataRow
+	if chunk != nil {
+		unmatched = chunk.unmatched
+	}
+	for _, tgt := range target {
+		src, ok := unmatched[tgt.key]
+		if !ok {
+			c.diff.Deleted++
+			if err := c.emit(c.diff, domain.RowDifference{Action: "DELETE", Key: c.rowKey(tgt), Values: c.rowValues(tgt)}); err != nil {
+				return err
+			}
+			continue
+		}
+		delete(unmatched, tgt.key)
+
+		var changes []domain.ColumnDifference
+		for i, col := range c.diff.Columns {
+			if !dataValuesEqual(src.values[i], tgt.values[i]) {
+				changes = append(changes, domain.ColumnDifference{Column: col.Name, Source: src.values[i], Target: tgt.values[i]})
+			}
+		}
+		if len(changes) == 0 {
+			c.diff.Unchanged++
+			continue
+		}
+		c.diff.Updated++
+		if err := c.emit(c.diff, domain.RowDifference{Action: "UPDATE", Key: c.rowKey(src), Changes: changes}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finishChunk inserts the source rows no target row matched, once the whole key range of the
+// chunk has been read from the target.
+func (c *dataComparison) finishChunk(chunk *sourceChunk) error {
+	for _, src := range chunk.rows {
+		if _, ok := chunk.unmatched[src.key]; !ok {
+			continue
+		}
+		c.diff.Inserted++
+		if err := c.emit(c.diff, domain.RowDifference{Action: "INSERT", Key: c.rowKey(src), Values: c.rowValues(src)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *dataComparison) rowKey(row dataRow) map[string]interface{} {
+	key := make(map[string]interface{}, len(c.keys))
+	for _, idx := range c.keys {
+		key[c.diff.Columns[idx].Name] = row.values[idx]
+	}
+	return key
+}
+
+func (c *dataComparison) rowValues(row dataRow) map[string]interface{} {
+	values := make(map[string]interface{}, len(row.values))
+	for i, col := range c.diff.Columns {
+		values[col.Name] = row.values[i]
+	}
+	return values
+}
+
+// isBinaryType reports whether values of the rendered SQL type are raw bytes.
+func isBinaryType(typ string) bool {
+	if strings.HasPrefix(typ, "BLOB") {
+		return !strings.HasPrefix(typ, "BLOB SUB_TYPE TEXT")
+	}
+	return strings.Contains(typ, "CHARACTER SET OCTETS")
+}
+
+// normalizeDataValue converts driver values to comparable, JSON friendly values:
+// binary data becomes a hex string, text read as bytes becomes a string.
+func normalizeDataValue(v interface{}, typ string) interface{} {
+	if b, ok := v.([]byte); ok {
+		if isBinaryType(typ) {
+			return hex.EncodeToString(b)
+		}
+		return string(b)
+	}
+	return v
+}
+
+func dataValuesEqual(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if ta, ok := a.(time.Time); ok {
+		tb, ok := b.(time.Time)
+		return ok && ta.Equal(tb)
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// sqlLiteral renders a normalized value as a literal of the given SQL type.
+func sqlLiteral(v interface{}, typ string) string {
+	switch val := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if val {
+			return "TRUE"
+		}
+		return "FALSE"
+	case time.Time:
+		return timeLiteral(val, typ)
+	case string:
+		if isBinaryType(typ) {
+			return "x'" + val + "'"
+		}
+		return quoteString(val)
+	}
+	return fmt.Sprint(v)
+}
+
+func timeLiteral(t time.Time, typ string) string {
+	withZone := strings.HasSuffix(typ, "WITH TIME ZONE")
+	var kind, value string
+	switch {
+	case strings.HasPrefix(typ, "DATE"):
+		kind, value = "DATE", t.Format("2006-01-02")
+	case strings.HasPrefix(typ, "TIME") && !strings.HasPrefix(typ, "TIMESTAMP"):
+		kind, value = "TIME", t.Format("15:04:05.0000")
+	default:
+		kind, value = "TIMESTAMP", t.Format("2006-01-02 15:04:05.0000")
+	}
+	if withZone {
+		value += " " + t.Location().String()
+	}
+	return kind + " " + quoteString(value)
+}
+
+// rowSyncSQL returns the statement that applies a row difference to the target table.
+func rowSyncSQL(diff *domain.DataDiff, row domain.RowDifference) string {
+	types := make(map[string]string, len(diff.Columns))
+	for _, col := range diff.Columns {
+		types[col.Name] = col.Type
+	}
+	where := make([]string, len(diff.KeyColumns))
+	for i, k := range diff.KeyColumns {
+		value := row.Key[k]
+		if value == nil {
+			where[i] = quoteIdent(k) + " IS NULL"
+		} else {
+			where[i] = quoteIdent(k) + " = " + sqlLiteral(value, types[k])
+		}
+	}
+	table := quoteIdent(diff.TargetTable)
+
+	switch row.Action {
+	case "INSERT":
+		names := make([]string, len(diff.Columns))
+		values := make([]string, len(diff.Columns))
+		overriding := ""
+		for i, col := range diff.Columns {
+			names[i] = quoteIdent(col.Name)
+			values[i] = sqlLiteral(row.Values[col.Name], col.Type)
+			if col.Identity == "ALWAYS" {
+				overriding = " OVERRIDING SYSTEM VALUE"
+			}
+		}
+		return fmt.Sprintf("INSERT INTO %s (%s)%s VALUES (%s);", table, strings.Join(names, ", "), overriding, strings.Join(values, ", "))
+	case "UPDATE":
+		sets := make([]string, len(row.Changes))
+		for i, ch := range row.Changes {
+			sets[i] = quoteIdent(ch.Column) + " = " + sqlLiteral(ch.Source, types[ch.Column])
+		}
+		return fmt.Sprintf("UPDATE %s SET %s WHERE %s;", table, strings.Join(sets, ", "), strings.Join(where, " AND "))
+	default:
+		return fmt.Sprintf("DELETE FROM %s WHERE %s;", table, strings.Join(where, " AND "))
+	}
+}
+
+// WriteRowSync writes the statement that applies one row difference to the target table.
+func WriteRowSync(w io.Writer, diff *domain.DataDiff, row domain.RowDifference) error {
+	_, err := io.WriteString(w, rowSyncSQL(diff, row)+"\n")
+	return err
+}
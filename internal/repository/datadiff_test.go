@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"firebird-web-admin/internal/domain"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDataRowsQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     []string
+		first    int
+		lower    bool
+		upper    bool
+		expected string
+	}{
+		{
+			name:     "First chunk",
+			keys:     []string{"ID"},
+			first:    500,
+			expected: `SELECT FIRST 500 "ID", "NAME" FROM "T" ORDER BY "ID"`,
+		},
+		{
+			name:     "Next chunk",
+			keys:     []string{"ID"},
+			first:    500,
+			lower:    true,
+			expected: `SELECT FIRST 500 "ID", "NAME" FROM "T" WHERE (("ID" > ?)) ORDER BY "ID"`,
+		},
+		{
+			name:     "Composite key range",
+			keys:     []string{"A", "B"},
+			lower:    true,
+			upper:    true,
+			expected: `SELECT "ID", "NAME" FROM "T" WHERE (("A" > ?) OR ("A" = ? AND "B" > ?)) AND (("A" < ?) OR ("A" = ? AND "B" <= ?)) ORDER BY "A", "B"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dataRowsQuery("T", []string{"ID", "NAME"}, tt.keys, tt.first, tt.lower, tt.upper)
+			if got != tt.expected {
+				t.Errorf("dataRowsQuery() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	args := keysetArgs([]interface{}{1, "x", 3})
+	if !reflect.DeepEqual(args, []interface{}{1, 1, "x", 1, "x", 3}) {
+		t.Errorf("keysetArgs() = %v", args)
+	}
+}
+
+func TestRowSyncSQL(t *testing.T) {
+	diff := &domain.DataDiff{
+		TargetTable: "CUSTOMER",
+		KeyColumns:  []string{"ID"},
+		Columns: []domain.TableColumn{
+			{Name: "ID", Type: "INTEGER", Identity: "ALWAYS"},
+			{Name: "NAME", Type: "VARCHAR(50)"},
+			{Name: "BORN", Type: "DATE"},
+			{Name: "PHOTO", Type: "BLOB SUB_TYPE BINARY"},
+		},
+	}
+	born := time.Date(1980, 5, 17, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		row      domain.RowDifference
+		expected string
+	}{
+		{
+			name: "Insert",
+			row: domain.RowDifference{Action: "INSERT", Key: map[string]interface{}{"ID": int32(1)},
+				Values: map[string]interface{}{"ID": int32(1), "NAME": "O'Neil", "BORN": born, "PHOTO": "ff00"}},
+			expected: `INSERT INTO "CUSTOMER" ("ID", "NAME", "BORN", "PHOTO") OVERRIDING SYSTEM VALUE VALUES (1, 'O''Neil', DATE '1980-05-17', x'ff00');`,
+		},
+		{
+			name: "Update",
+			row: domain.RowDifference{Action: "UPDATE", Key: map[string]interface{}{"ID": int32(2)},
+				Changes: []domain.ColumnDifference{{Column: "NAME", Source: "New", Target: "Old"}, {Column: "BORN", Source: nil, Target: born}}},
+			expected: `UPDATE "CUSTOMER" SET "NAME" = 'New', "BORN" = NULL WHERE "ID" = 2;`,
+		},
+		{
+			name:     "Delete",
+			row:      domain.RowDifference{Action: "DELETE", Key: map[string]interface{}{"ID": int32(3)}},
+			expected: `DELETE FROM "CUSTOMER" WHERE "ID" = 3;`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rowSyncSQL(diff, tt.row); got != tt.expected {
+				t.Errorf("rowSyncSQL() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompareTarget(t *testing.T) {
+	var emitted []domain.RowDifference
+	c := &dataComparison{
+		diff: &domain.DataDiff{Columns: []domain.TableColumn{{Name: "ID", Type: "INTEGER"}, {Name: "NAME", Type: "VARCHAR(10)"}}},
+		keys: []int{0},
+		emit: func(_ *domain.DataDiff, row domain.RowDifference) error {
+			emitted = append(emitted, row)
+			return nil
+		},
+	}
+	row := func(id int32, name interface{}) dataRow {
+		return dataRow{key: string(rune('0' + id)), keyRaw: []interface{}{id}, values: []interface{}{id, name}}
+	}
+
+	source := []dataRow{row(1, "same"), row(2, "new"), row(4, "added")}
+	target := []dataRow{row(1, "same"), row(2, "old"), row(3, "removed")}
+	// The target range is read in two pages.
+	chunk := c.newChunk(source)
+	for _, page := range [][]dataRow{target[:1], target[1:]} {
+		if err := c.compareTarget(chunk, page); err != nil {
+			t.Fatalf("compareTarget() error = %v", err)
+		}
+	}
+	if err := c.finishChunk(chunk); err != nil {
+		t.Fatalf("finishChunk() error = %v", err)
+	}
+
+	d := c.diff
+	if d.SourceRows != 3 || d.TargetRows != 3 || d.Inserted != 1 || d.Updated != 1 || d.Deleted != 1 || d.Unchanged != 1 {
+		t.Errorf("counters = %+v", d)
+	}
+	actions := make([]string, len(emitted))
+	for i, r := range emitted {
+		actions[i] = r.Action
+	}
+	if !reflect.DeepEqual(actions, []string{"UPDATE", "DELETE", "INSERT"}) {
+		t.Errorf("emitted actions = %v", actions)
+	}
+	if ch := emitted[0].Changes; len(ch) != 1 || ch[0].Column != "NAME" || ch[0].Source != "new" || ch[0].Target != "old" {
+		t.Errorf("update changes = %+v", ch)
+	}
+}
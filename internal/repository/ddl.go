@@ -0,0 +1,800 @@
+package repository
+
+import (
+	"firebird-web-admin/internal/domain"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// quoteIdent quotes a Firebird identifier, escaping embedded double quotes.
+func quoteIdent(name string) string {
+	return "\"" + strings.ReplaceAll(name, "\"", "\"\"") + "\""
+}
+
+// objectName returns the name an object is stored under for a name taken from a URL path.
+// As in SQL, a name in double quotes is taken as written and any other name is upper-cased.
+// Names in request bodies are stored names, as the API returns them, and are used as is.
+func objectName(name string) string {
+	name = strings.TrimSpace(name)
+	if len(name) >= 2 && strings.HasPrefix(name, "\"") && strings.HasSuffix(name, "\"") {
+		return strings.ReplaceAll(name[1:len(name)-1], "\"\"", "\"")
+	}
+	return strings.ToUpper(name)
+}
+
+// quoteString renders a Firebird string literal.
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func quoteIdentList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = quoteIdent(n)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// scriptWriter writes an SQL script, remembering the first write error.
+// When the underlying writer can flush (e.g. an HTTP response), every section is flushed as soon as it is complete.
+type scriptWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (sw *scriptWriter) printf(format string, args ...interface{}) {
+	if sw.err != nil {
+		return
+	}
+	_, sw.err = fmt.Fprintf(sw.w, format, args...)
+}
+
+func (sw *scriptWriter) section(title string) {
+	if f, ok := sw.w.(interface{ Flush() }); ok && sw.err == nil {
+		f.Flush()
+	}
+	sw.printf("\n/* %s */\n", title)
+}
+
+// psql writes a block of PSQL statements, each terminated with "^", inside SET TERM.
+func (sw *scriptWriter) psql(statements []string) {
+	if len(statements) == 0 {
+		return
+	}
+	sw.printf("SET TERM ^ ;\n\n")
+	for _, s := range statements {
+		sw.printf("%s^\n\n", s)
+	}
+	sw.printf("SET TERM ; ^\n")
+}
+
+// WriteSchemaScript writes s as an SQL script that recreates the schema (isql -x equivalent).
+// Objects are ordered so that every statement only references objects created before it:
+// routines are first created as stubs and receive their bodies after tables and views exist.
+func WriteSchemaScript(w io.Writer, s *domain.Schema) error {
+	sw := &scriptWriter{w: w}
+
+	sw.printf("SET SQL DIALECT 3;\n\n")
+	sw.printf("/* CREATE DATABASE '<path>' DEFAULT CHARACTER SET %s; */\n", s.DefaultCharset)
+
+	if len(s.Domains) > 0 {
+		sw.section("Domains")
+		for _, d := range s.Domains {
+			sw.printf("%s;\n", domainSQL(d))
+		}
+	}
+
+	if len(s.Sequences) > 0 {
+		sw.section("Sequences")
+		for _, seq := range s.Sequences {
+			sw.printf("%s;\n", sequenceSQL(seq))
+		}
+	}
+
+	if len(s.Exceptions) > 0 {
+		sw.section("Exceptions")
+		for _, e := range s.Exceptions {
+			sw.printf("%s;\n", exceptionSQL(e))
+		}
+	}
+
+	var legacy, functionStubs, functionBodies []string
+	for _, f := range s.Functions {
+		switch {
+		case f.Legacy:
+			legacy = append(legacy, legacyFunctionSQL(f))
+		case f.Engine != "":
+			functionStubs = append(functionStubs, functionSQL(f, false))
+		default:
+			functionStubs = append(functionStubs, functionSQL(f, true))
+			functionBodies = append(functionBodies, functionSQL(f, false))
+		}
+	}
+	if len(legacy) > 0 {
+		sw.section("External functions")
+		for _, f := range legacy {
+			sw.printf("%s;\n\n", f)
+		}
+	}
+	if len(functionStubs) > 0 {
+		sw.section("Functions")
+		sw.psql(functionStubs)
+	}
+
+	var procedureStubs, procedureBodies []string
+	for _, p := range s.Procedures {
+		if p.Engine != "" {
+			procedureStubs = append(procedureStubs, procedureSQL(p, false))
+			continue
+		}
+		procedureStubs = append(procedureStubs, procedureSQL(p, true))
+		procedureBodies = append(procedureBodies, procedureSQL(p, false))
+	}
+	if len(procedureStubs) > 0 {
+		sw.section("Procedures")
+		sw.psql(procedureStubs)
+	}
+
+	if len(s.Packages) > 0 {
+		sw.section("Package headers")
+		var headers []string
+		for _, p := range s.Packages {
+			headers = append(headers, packageHeaderSQL(p))
+		}
+		sw.psql(headers)
+	}
+
+	if len(s.Tables) > 0 {
+		sw.section("Tables")
+		for _, t := range s.Tables {
+			sw.printf("%s;\n\n", tableSQL(t))
+		}
+	}
+
+	if len(s.Views) > 0 {
+		sw.section("Views")
+		for _, v := range sortViews(s.Views) {
+			sw.printf("%s;\n\n", viewSQL(v))
+		}
+	}
+
+	var deferred []string
+	for _, t := range s.Tables {
+		for _, c := range t.Constraints {
+			if c.Type == "FOREIGN KEY" || c.Type == "CHECK" {
+				deferred = append(deferred, fmt.Sprintf("ALTER TABLE %s ADD %s;", quoteIdent(t.Name), constraintSQL(c)))
+			}
+		}
+	}
+	if len(deferred) > 0 {
+		sw.section("Foreign keys and check constraints")
+		for _, c := range deferred {
+			sw.printf("%s\n", c)
+		}
+	}
+
+	var indexes []string
+	for _, i := range s.Indexes {
+		if i.Constraint != "" {
+			continue
+		}
+		indexes = append(indexes, indexSQL(i)+";")
+		if !i.Active {
+			indexes = append(indexes, fmt.Sprintf("ALTER INDEX %s INACTIVE;", quoteIdent(i.Name)))
+		}
+	}
+	if len(indexes) > 0 {
+		sw.section("Indexes")
+		for _, i := range indexes {
+			sw.printf("%s\n", i)
+		}
+	}
+
+	if len(functionBodies) > 0 {
+		sw.section("Function bodies")
+		sw.psql(functionBodies)
+	}
+
+	if len(procedureBodies) > 0 {
+		sw.section("Procedure bodies")
+		sw.psql(procedureBodies)
+	}
+
+	var bodies []string
+	for _, p := range s.Packages {
+		if p.Body != "" {
+			bodies = append(bodies, packageBodySQL(p))
+		}
+	}
+	if len(bodies) > 0 {
+		sw.section("Package bodies")
+		sw.psql(bodies)
+	}
+
+	if len(s.Triggers) > 0 {
+		sw.section("Triggers")
+		var triggers []string
+		for _, t := range s.Triggers {
+			triggers = append(triggers, triggerSQL(t))
+		}
+		sw.psql(triggers)
+	}
+
+	if len(s.Roles) > 0 {
+		sw.section("Roles")
+		for _, r := range s.Roles {
+			sw.printf("CREATE ROLE %s;\n", quoteIdent(r.Name))
+		}
+	}
+
+	var grants []string
+	for _, g := range s.Grants {
+		if stmt := grantSQL(g); stmt != "" {
+			grants = append(grants, stmt)
+		}
+	}
+	if len(grants) > 0 {
+		sw.section("Grants")
+		for _, g := range grants {
+			sw.printf("%s;\n", g)
+		}
+	}
+
+	if comments := schemaComments(s); len(comments) > 0 {
+		sw.section("Comments")
+		for _, c := range comments {
+			sw.printf("%s;\n", c)
+		}
+	}
+
+	if sw.err == nil {
+		if f, ok := w.(interface{ Flush() }); ok {
+			f.Flush()
+		}
+	}
+	return sw.err
+}
+
+// sortViews orders views so that every view comes after the views it selects from.
+// Views caught in a dependency cycle keep their original order at the end.
+func sortViews(views []domain.ViewDefinition) []domain.ViewDefinition {
+	byName := make(map[string]domain.ViewDefinition, len(views))
+	for _, v := range views {
+		byName[v.Name] = v
+	}
+	state := make(map[string]int) // 0 = new, 1 = visiting, 2 = done
+	var sorted []domain.ViewDefinition
+	var visit func(name string)
+	visit = func(name string) {
+		v, ok := byName[name]
+		if !ok || state[name] != 0 {
+			return
+		}
+		state[name] = 1
+		deps := append([]string(nil), v.DependsOn...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			visit(dep)
+		}
+		state[name] = 2
+		sorted = append(sorted, v)
+	}
+	for _, v := range views {
+		visit(v.Name)
+	}
+	return sorted
+}
+
+func domainSQL(d domain.Domain) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "CREATE DOMAIN %s AS %s", quoteIdent(d.Name), d.Type)
+	if d.Default != "" {
+		sb.WriteString(" " + d.Default)
+	}
+	if d.NotNull {
+		sb.WriteString(" NOT NULL")
+	}
+	if d.Check != "" {
+		sb.WriteString(" " + d.Check)
+	}
+	if d.Collation != "" {
+		sb.WriteString(" COLLATE " + d.Collation)
+	}
+	return sb.String()
+}
+
+func sequenceSQL(s domain.Sequence) string {
+	stmt := "CREATE SEQUENCE " + quoteIdent(s.Name)
+	if s.InitialValue != 0 {
+		stmt += fmt.Sprintf(" START WITH %d", s.InitialValue)
+	}
+	if s.Increment != 1 {
+		stmt += fmt.Sprintf(" INCREMENT BY %d", s.Increment)
+	}
+	return stmt
+}
+
+// restartSequenceSQL renders ALTER SEQUENCE ... RESTART, at the initial value when value is nil.
+func restartSequenceSQL(name string, value *int64) string {
+	stmt := fmt.Sprintf("ALTER SEQUENCE %s RESTART", quoteIdent(name))
+	if value != nil {
+		stmt += fmt.Sprintf(" WITH %d", *value)
+	}
+	return stmt
+}
+
+func setSequenceValueSQL(name string, value int64) string {
+	return fmt.Sprintf("SET GENERATOR %s TO %d", quoteIdent(name), value)
+}
+
+func exceptionSQL(e domain.DatabaseException) string {
+	return fmt.Sprintf("CREATE EXCEPTION %s %s", quoteIdent(e.Name), quoteString(e.Message))
+}
+
+func alterExceptionSQL(name string, message string) string {
+	return fmt.Sprintf("ALTER EXCEPTION %s %s", quoteIdent(name), quoteString(message))
+}
+
+// columnSQL renders a column definition as used in CREATE TABLE and ALTER TABLE ADD.
+func columnSQL(c domain.TableColumn) string {
+	var sb strings.Builder
+	sb.WriteString(quoteIdent(c.Name))
+	if c.Computed != "" {
+		sb.WriteString(" COMPUTED BY " + parenthesize(c.Computed))
+		return sb.String()
+	}
+	if c.Domain != "" {
+		sb.WriteString(" " + quoteIdent(c.Domain))
+	} else {
+		sb.WriteString(" " + c.Type)
+	}
+	if c.Identity != "" {
+		sb.WriteString(" GENERATED " + c.Identity + " AS IDENTITY")
+	}
+	if c.Default != "" {
+		sb.WriteString(" " + c.Default)
+	}
+	if c.NotNull && c.Identity == "" {
+		sb.WriteString(" NOT NULL")
+	}
+	if c.Collation != "" {
+		sb.WriteString(" COLLATE " + c.Collation)
+	}
+	return sb.String()
+}
+
+// constraintSQL renders a table constraint clause. System-generated INTEG_ names are omitted.
+func constraintSQL(c domain.Constraint) string {
+	var sb strings.Builder
+	if c.Name != "" && !strings.HasPrefix(c.Name, "INTEG_") {
+		sb.WriteString("CONSTRAINT " + quoteIdent(c.Name) + " ")
+	}
+	switch c.Type {
+	case "CHECK":
+		sb.WriteString(c.Check)
+		return sb.String()
+	case "FOREIGN KEY":
+		fmt.Fprintf(&sb, "FOREIGN KEY (%s) REFERENCES %s (%s)", quoteIdentList(c.Columns), quoteIdent(c.RefTable), quoteIdentList(c.RefColumns))
+		if c.OnUpdate != "" && c.OnUpdate != "RESTRICT" && c.OnUpdate != "NO ACTION" {
+			sb.WriteString(" ON UPDATE " + c.OnUpdate)
+		}
+		if c.OnDelete != "" && c.OnDelete != "RESTRICT" && c.OnDelete != "NO ACTION" {
+			sb.WriteString(" ON DELETE " + c.OnDelete)
+		}
+	default:
+		fmt.Fprintf(&sb, "%s (%s)", c.Type, quoteIdentList(c.Columns))
+	}
+	if c.Index != "" && c.Index != c.Name && !strings.HasPrefix(c.Index, "RDB$") {
+		sb.WriteString(" USING ")
+		if c.Descending {
+			sb.WriteString("DESC ")
+		}
+		sb.WriteString("INDEX " + quoteIdent(c.Index))
+	}
+	return sb.String()
+}
+
+// tableSQL renders CREATE TABLE with columns, primary key and unique constraints.
+// Foreign keys and checks are left out because they may reference objects created later.
+func tableSQL(t domain.TableDefinition) string {
+	var sb strings.Builder
+	switch t.Kind {
+	case "GTT_PRESERVE", "GTT_DELETE":
+		sb.WriteString("CREATE GLOBAL TEMPORARY TABLE " + quoteIdent(t.Name))
+	case "EXTERNAL":
+		sb.WriteString("CREATE TABLE " + quoteIdent(t.Name) + " EXTERNAL FILE " + quoteString(t.ExternalFile))
+	default:
+		sb.WriteString("CREATE TABLE " + quoteIdent(t.Name))
+	}
+	sb.WriteString(" (\n")
+
+	var lines []string
+	for _, c := range t.Columns {
+		lines = append(lines, "    "+columnSQL(c))
+	}
+	for _, c := range t.Constraints {
+		if c.Type == "PRIMARY KEY" || c.Type == "UNIQUE" {
+			lines = append(lines, "    "+constraintSQL(c))
+		}
+	}
+	sb.WriteString(strings.Join(lines, ",\n"))
+	sb.WriteString("\n)")
+
+	switch t.Kind {
+	case "GTT_PRESERVE":
+		sb.WriteString(" ON COMMIT PRESERVE ROWS")
+	case "GTT_DELETE":
+		sb.WriteString(" ON COMMIT DELETE ROWS")
+	}
+	return sb.String()
+}
+
+func viewSQL(v domain.ViewDefinition) string {
+	names := make([]string, len(v.Columns))
+	for i, c := range v.Columns {
+		names[i] = c.Name
+	}
+	return fmt.Sprintf("CREATE VIEW %s (%s) AS\n%s", quoteIdent(v.Name), quoteIdentList(names), v.Source)
+}
+
+// parameterSQL renders a routine parameter declaration.
+func parameterSQL(p domain.ProcedureParameter) string {
+	s := quoteIdent(p.Name) + " " + p.Type
+	if p.NotNull {
+		s += " NOT NULL"
+	}
+	if p.Collation != "" {
+		s += " COLLATE " + p.Collation
+	}
+	if p.Default != "" {
+		s += " " + p.Default
+	}
+	return s
+}
+
+func parameterListSQL(params []domain.ProcedureParameter) string {
+	if len(params) == 0 {
+		return ""
+	}
+	lines := make([]string, len(params))
+	for i, p := range params {
+		lines[i] = "    " + parameterSQL(p)
+	}
+	return " (\n" + strings.Join(lines, ",\n") + ")"
+}
+
+// routineBody renders the part after the routine header: an external reference, a stub or the PSQL source.
+func routineBody(r domain.Routine, stub string, asStub bool) string {
+	var sb strings.Builder
+	if r.SQLSecurity != "" {
+		sb.WriteString("SQL SECURITY " + r.SQLSecurity + "\n")
+	}
+	switch {
+	case r.Engine != "":
+		fmt.Fprintf(&sb, "EXTERNAL NAME %s ENGINE %s", quoteString(r.EntryPoint), r.Engine)
+	case asStub || r.Source == "":
+		sb.WriteString("AS\n" + stub)
+	default:
+		sb.WriteString("AS\n" + r.Source)
+	}
+	return sb.String()
+}
+
+// procedureSQL renders CREATE OR ALTER PROCEDURE. With asStub the body is replaced by an empty block
+// so the procedure can be referenced before the objects used in its body exist.
+func procedureSQL(p domain.Routine, asStub bool) string {
+	var sb strings.Builder
+	sb.WriteString("CREATE OR ALTER PROCEDURE " + quoteIdent(p.Name))
+	sb.WriteString(parameterListSQL(p.Inputs))
+	if len(p.Outputs) > 0 {
+		sb.WriteString("\nRETURNS" + parameterListSQL(p.Outputs))
+	}
+	sb.WriteString("\n")
+	stub := "BEGIN\n  EXIT;\nEND"
+	if p.Selectable {
+		stub = "BEGIN\n  SUSPEND;\nEND"
+	}
+	sb.WriteString(routineBody(p, stub, asStub))
+	return sb.String()
+}
+
+// functionSQL renders CREATE OR ALTER FUNCTION, optionally with a stub body (see procedureSQL).
+func functionSQL(f domain.Routine, asStub bool) string {
+	var sb strings.Builder
+	sb.WriteString("CREATE OR ALTER FUNCTION " + quoteIdent(f.Name))
+	sb.WriteString(parameterListSQL(f.Inputs))
+	sb.WriteString("\nRETURNS " + f.Returns)
+	if f.Deterministic {
+		sb.WriteString(" DETERMINISTIC")
+	}
+	sb.WriteString("\n")
+	sb.WriteString(routineBody(f, "BEGIN\n  RETURN NULL;\nEND", asStub))
+	return sb.String()
+}
+
+// legacyFunctionSQL renders DECLARE EXTERNAL FUNCTION for a legacy UDF.
+func legacyFunctionSQL(f domain.Routine) string {
+	args := make([]string, len(f.Inputs))
+	for i, a := range f.Inputs {
+		args[i] = a.Type
+	}
+	var sb strings.Builder
+	sb.WriteString("DECLARE EXTERNAL FUNCTION " + quoteIdent(f.Name))
+	if len(args) > 0 {
+		sb.WriteString("\n    " + strings.Join(args, ", "))
+	}
+	fmt.Fprintf(&sb, "\nRETURNS %s\nENTRY_POINT %s MODULE_NAME %s", f.Returns, quoteString(f.EntryPoint), quoteString(f.ModuleName))
+	return sb.String()
+}
+
+func packageHeaderSQL(p domain.Package) string {
+	s := "CREATE OR ALTER PACKAGE " + quoteIdent(p.Name) + "\n"
+	if p.SQLSecurity != "" {
+		s += "SQL SECURITY " + p.SQLSecurity + "\n"
+	}
+	return s + "AS\n" + p.Header
+}
+
+func packageBodySQL(p domain.Package) string {
+	return "RECREATE PACKAGE BODY " + quoteIdent(p.Name) + "\nAS\n" + p.Body
+}
+
+// triggerSQL renders CREATE OR ALTER TRIGGER. RDB$TRIGGER_SOURCE already starts with "AS".
+func triggerSQL(t domain.Trigger) string {
+	var sb strings.Builder
+	sb.WriteString("CREATE OR ALTER TRIGGER " + quoteIdent(t.Name))
+	if t.Kind == "TABLE" && t.Relation != "" {
+		sb.WriteString(" FOR " + quoteIdent(t.Relation))
+	}
+	state := "ACTIVE"
+	if !t.Active {
+		state = "INACTIVE"
+	}
+	fmt.Fprintf(&sb, "\n%s %s POSITION %d\n", state, t.Event, t.Position)
+	if t.SQLSecurity != "" {
+		sb.WriteString("SQL SECURITY " + t.SQLSecurity + "\n")
+	}
+	if t.Engine != "" {
+		fmt.Fprintf(&sb, "EXTERNAL NAME %s ENGINE %s", quoteString(t.EntryPoint), t.Engine)
+	} else {
+		sb.WriteString(t.Source)
+	}
+	return sb.String()
+}
+
+func indexSQL(i domain.Index) string {
+	var sb strings.Builder
+	sb.WriteString("CREATE ")
+	if i.Unique {
+		sb.WriteString("UNIQUE ")
+	}
+	if i.Descending {
+		sb.WriteString("DESCENDING ")
+	}
+	fmt.Fprintf(&sb, "INDEX %s ON %s ", quoteIdent(i.Name), quoteIdent(i.Relation))
+	if i.Expression != "" {
+		sb.WriteString("COMPUTED BY " + parenthesize(i.Expression))
+	} else {
+		sb.WriteString("(" + quoteIdentList(i.Segments) + ")")
+	}
+	return sb.String()
+}
+
+// parenthesize wraps an expression in parentheses unless it is already fully enclosed in them.
+func parenthesize(expr string) string {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") {
+		depth := 0
+		for i, ch := range expr {
+			switch ch {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+			if depth == 0 && i < len(expr)-1 {
+				return "(" + expr + ")"
+			}
+		}
+		return expr
+	}
+	return "(" + expr + ")"
+}
+
+// Object types used in RDB$USER_PRIVILEGES.RDB$OBJECT_TYPE and RDB$USER_TYPE.
+const (
+	objRelation  = 0
+	objView      = 1
+	objTrigger   = 2
+	objProcedure = 5
+	objException = 7
+	objUser      = 8
+	objDomain    = 9
+	objCharset   = 11
+	objRole      = 13
+	objGenerator = 14
+	objFunction  = 15
+	objCollation = 17
+	objPackage   = 18
+)
+
+// ddlPrivilegeObjects maps object types of metadata privileges (CREATE/ALTER ANY/DROP ANY) to their SQL names.
+var ddlPrivilegeObjects = map[int]string{
+	22: "TABLE", 23: "VIEW", 24: "PROCEDURE", 25: "FUNCTION", 26: "PACKAGE", 27: "GENERATOR",
+	28: "DOMAIN", 29: "EXCEPTION", 30: "ROLE", 31: "CHARACTER SET", 32: "COLLATION", 33: "FILTER",
+}
+
+var privilegeNames = map[string]string{
+	"S": "SELECT", "I": "INSERT", "U": "UPDATE", "D": "DELETE", "R": "REFERENCES", "X": "EXECUTE", "G": "USAGE",
+}
+
+// granteeSQL renders the TO clause target of a grant.
+func granteeSQL(name string, userType int) string {
+	switch userType {
+	case objUser:
+		if name == "PUBLIC" {
+			return "PUBLIC"
+		}
+		return "USER " + quoteIdent(name)
+	case objRole:
+		return "ROLE " + quoteIdent(name)
+	case objProcedure:
+		return "PROCEDURE " + quoteIdent(name)
+	case objTrigger:
+		return "TRIGGER " + quoteIdent(name)
+	case objFunction:
+		return "FUNCTION " + quoteIdent(name)
+	case objPackage:
+		return "PACKAGE " + quoteIdent(name)
+	case objView:
+		return "VIEW " + quoteIdent(name)
+	}
+	return quoteIdent(name)
+}
+
+// grantObjectSQL renders the ON clause target of an object privilege.
+func grantObjectSQL(name string, objectType int) string {
+	switch objectType {
+	case objRelation, objView:
+		return quoteIdent(name)
+	case objProcedure:
+		return "PROCEDURE " + quoteIdent(name)
+	case objFunction:
+		return "FUNCTION " + quoteIdent(name)
+	case objPackage:
+		return "PACKAGE " + quoteIdent(name)
+	case objException:
+		return "EXCEPTION " + quoteIdent(name)
+	case objGenerator:
+		return "SEQUENCE " + quoteIdent(name)
+	case objDomain:
+		return "DOMAIN " + quoteIdent(name)
+	case objCharset:
+		return "CHARACTER SET " + quoteIdent(name)
+	case objCollation:
+		return "COLLATION " + quoteIdent(name)
+	}
+	return ""
+}
+
+// grantSQL renders a single GRANT statement, or "" for privileges it cannot represent.
+func grantSQL(g domain.Grant) string {
+	what, ok := privilegeClause(g)
+	if !ok {
+		return ""
+	}
+	s := fmt.Sprintf("GRANT %s TO %s", what, granteeSQL(g.Grantee, g.GranteeType))
+	switch {
+	case g.GrantOption > 0 && g.Privilege == "M":
+		s += " WITH ADMIN OPTION"
+	case g.GrantOption > 0:
+		s += " WITH GRANT OPTION"
+	}
+	return s
+}
+
+// revokeSQL renders the REVOKE statement that removes a grant, or "" for privileges it
+// cannot represent.
+func revokeSQL(g domain.Grant) string {
+	what, ok := privilegeClause(g)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("REVOKE %s FROM %s", what, granteeSQL(g.Grantee, g.GranteeType))
+}
+
+// privilegeClause renders what a grant gives: a role, a metadata privilege such as
+// CREATE TABLE, or an object privilege such as SELECT ON "T".
+func privilegeClause(g domain.Grant) (string, bool) {
+	switch g.Privilege {
+	case "M":
+		return quoteIdent(g.Object), true
+	case "C", "L", "O":
+		object, ok := ddlPrivilegeObjects[g.ObjectType]
+		verb := map[string]string{"C": "CREATE", "L": "ALTER ANY", "O": "DROP ANY"}[g.Privilege]
+		return verb + " " + object, ok
+	}
+
+	privilege, ok := privilegeNames[g.Privilege]
+	on := grantObjectSQL(g.Object, g.ObjectType)
+	if !ok || on == "" {
+		return "", false
+	}
+	if g.Field != "" && (g.Privilege == "U" || g.Privilege == "R") {
+		privilege += " (" + quoteIdent(g.Field) + ")"
+	}
+	return privilege + " ON " + on, true
+}
+
+func commentSQL(object string, text string) string {
+	return fmt.Sprintf("COMMENT ON %s IS %s", object, quoteString(text))
+}
+
+// schemaComments renders COMMENT ON statements for every described object.
+func schemaComments(s *domain.Schema) []string {
+	var comments []string
+	add := func(object, text string) {
+		if strings.TrimSpace(text) != "" {
+			comments = append(comments, commentSQL(object, text))
+		}
+	}
+	addColumns := func(relation string, cols []domain.TableColumn) {
+		for _, c := range cols {
+			add("COLUMN "+quoteIdent(relation)+"."+quoteIdent(c.Name), c.Description)
+		}
+	}
+	addParams := func(kind, routine string, params []domain.ProcedureParameter) {
+		for _, p := range params {
+			if p.Name != "" {
+				add(kind+" PARAMETER "+quoteIdent(routine)+"."+quoteIdent(p.Name), p.Description)
+			}
+		}
+	}
+
+	add("DATABASE", s.Description)
+	for _, d := range s.Domains {
+		add("DOMAIN "+quoteIdent(d.Name), d.Description)
+	}
+	for _, seq := range s.Sequences {
+		add("SEQUENCE "+quoteIdent(seq.Name), seq.Description)
+	}
+	for _, e := range s.Exceptions {
+		add("EXCEPTION "+quoteIdent(e.Name), e.Description)
+	}
+	for _, t := range s.Tables {
+		add("TABLE "+quoteIdent(t.Name), t.Description)
+		addColumns(t.Name, t.Columns)
+	}
+	for _, v := range s.Views {
+		add("VIEW "+quoteIdent(v.Name), v.Description)
+		addColumns(v.Name, v.Columns)
+	}
+	for _, p := range s.Procedures {
+		add("PROCEDURE "+quoteIdent(p.Name), p.Description)
+		addParams("PROCEDURE", p.Name, p.Inputs)
+		addParams("PROCEDURE", p.Name, p.Outputs)
+	}
+	for _, f := range s.Functions {
+		if f.Legacy {
+			add("EXTERNAL FUNCTION "+quoteIdent(f.Name), f.Description)
+			continue
+		}
+		add("FUNCTION "+quoteIdent(f.Name), f.Description)
+		addParams("FUNCTION", f.Name, f.Inputs)
+	}
+	for _, p := range s.Packages {
+		add("PACKAGE "+quoteIdent(p.Name), p.Description)
+	}
+	for _, t := range s.Triggers {
+		add("TRIGGER "+quoteIdent(t.Name), t.Description)
+	}
+	for _, i := range s.Indexes {
+		add("INDEX "+quoteIdent(i.Name), i.Description)
+	}
+	for _, r := range s.Roles {
+		add("ROLE "+quoteIdent(r.Name), r.Description)
+	}
+	return comments
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"firebird-web-admin/internal/domain"
+	"strings"
+	"testing"
+)
+
+func TestObjectName(t *testing.T) {
+	tests := map[string]string{
+		"employee":     "EMPLOYEE",
+		" Emp_Proj ":   "EMP_PROJ",
+		`"employee"`:   "employee",
+		`"My ""Big"""`: `My "Big"`,
+		`"`:            `"`,
+	}
+	for name, expected := range tests {
+		if got := objectName(name); got != expected {
+			t.Errorf("objectName(%q) = %q, want %q", name, got, expected)
+		}
+	}
+}
+
+func TestFormatFieldType(t *testing.T) {
+	tests := []struct {
+		name      string
+		fieldType int64
+		subType   int64
+		charLen   int64
+		precision int64
+		scale     int64
+		segment   int64
+		charset   string
+		expected  string
+	}{
+		{name: "Integer", fieldType: 8, expected: "INTEGER"},
+		{name: "Numeric", fieldType: 16, subType: 1, precision: 18, scale: -2, expected: "NUMERIC(18, 2)"},
+		{name: "Decimal", fieldType: 8, subType: 2, precision: 9, scale: -3, expected: "DECIMAL(9, 3)"},
+		{name: "Numeric without precision", fieldType: 7, subType: 1, scale: -1, expected: "NUMERIC(4, 1)"},
+		{name: "Varchar default charset", fieldType: 37, charLen: 50, charset: "UTF8", expected: "VARCHAR(50)"},
+		{name: "Varchar other charset", fieldType: 37, charLen: 50, charset: "WIN1251", expected: "VARCHAR(50) CHARACTER SET WIN1251"},
+		{name: "Char octets", fieldType: 14, charLen: 16, charset: "OCTETS", expected: "CHAR(16) CHARACTER SET OCTETS"},
+		{name: "Text blob", fieldType: 261, subType: 1, segment: 80, charset: "UTF8", expected: "BLOB SUB_TYPE TEXT"},
+		{name: "Binary blob with segment", fieldType: 261, segment: 4096, expected: "BLOB SUB_TYPE BINARY SEGMENT SIZE 4096"},
+		{name: "Timestamp with time zone", fieldType: 29, expected: "TIMESTAMP WITH TIME ZONE"},
+		{name: "Boolean", fieldType: 23, expected: "BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatFieldType(tt.fieldType, tt.subType, tt.charLen, tt.precision, tt.scale, tt.segment, tt.charset, "UTF8")
+			if got != tt.expected {
+				t.Errorf("formatFieldType() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDecodeTriggerType(t *testing.T) {
+	tests := []struct {
+		name        string
+		triggerType int64
+		kind        string
+		event       string
+	}{
+		{name: "Before insert", triggerType: 1, kind: "TABLE", event: "BEFORE INSERT"},
+		{name: "After delete", triggerType: 6, kind: "TABLE", event: "AFTER DELETE"},
+		{name: "Before insert or update", triggerType: 17, kind: "TABLE", event: "BEFORE INSERT OR UPDATE"},
+		{name: "After insert or update or delete", triggerType: 114, kind: "TABLE", event: "AFTER INSERT OR UPDATE OR DELETE"},
+		{name: "On connect", triggerType: 8192, kind: "DATABASE", event: "ON CONNECT"},
+		{name: "On transaction commit", triggerType: 8195, kind: "DATABASE", event: "ON TRANSACTION COMMIT"},
+		{name: "Before create table", triggerType: 0x4000 | 1<<1, kind: "DDL", event: "BEFORE CREATE TABLE"},
+		{name: "After any DDL", triggerType: 0x7FFFFFFFFFFFDFFF, kind: "DDL", event: "AFTER ANY DDL STATEMENT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, event := decodeTriggerType(tt.triggerType)
+			if kind != tt.kind || event != tt.event {
+				t.Errorf("decodeTriggerType() = %v, %v, want %v, %v", kind, event, tt.kind, tt.event)
+			}
+		})
+	}
+}
+
+func TestWriteSchemaScript(t *testing.T) {
+	schema := &domain.Schema{
+		DefaultCharset: "UTF8",
+		Domains:        []domain.Domain{{Name: "D_ID", Type: "INTEGER", NotNull: true}},
+		Tables: []domain.TableDefinition{
+			{
+				Name: "CUSTOMER",
+				Kind: "TABLE",
+				Columns: []domain.TableColumn{
+					{Name: "ID", Type: "INTEGER", Domain: "D_ID", NotNull: true},
+					{Name: "NAME", Type: "VARCHAR(50)", Default: "DEFAULT ''"},
+				},
+				Constraints: []domain.Constraint{{Name: "PK_CUSTOMER", Type: "PRIMARY KEY", Columns: []string{"ID"}, Index: "PK_CUSTOMER"}},
+			},
+			{
+				Name:    "ORDERS",
+				Kind:    "TABLE",
+				Columns: []domain.TableColumn{{Name: "CUSTOMER_ID", Type: "INTEGER"}},
+				Constraints: []domain.Constraint{{
+					Name: "FK_ORDERS", Type: "FOREIGN KEY", Columns: []string{"CUSTOMER_ID"}, Index: "FK_ORDERS",
+					RefTable: "CUSTOMER", RefColumns: []string{"ID"}, OnUpdate: "RESTRICT", OnDelete: "CASCADE",
+				}},
+			},
+		},
+		Views: []domain.ViewDefinition{
+			{Name: "V_TOP", Columns: []domain.TableColumn{{Name: "ID"}}, Source: "SELECT ID FROM V_BASE", DependsOn: []string{"V_BASE"}},
+			{Name: "V_BASE", Columns: []domain.TableColumn{{Name: "ID"}}, Source: "SELECT ID FROM CUSTOMER"},
+		},
+		Procedures: []domain.Routine{{
+			Name:       "GET_CUSTOMERS",
+			Outputs:    []domain.ProcedureParameter{{Name: "ID", Type: "INTEGER"}},
+			Selectable: true,
+			Source:     "BEGIN\n  FOR SELECT ID FROM V_TOP INTO :ID DO SUSPEND;\nEND",
+		}},
+		Triggers: []domain.Trigger{{
+			Name: "CUSTOMER_BI", Relation: "CUSTOMER", Kind: "TABLE", Event: "BEFORE INSERT", Active: true,
+			Source: "AS\nBEGIN\nEND",
+		}},
+		Grants: []domain.Grant{{Grantee: "APP", GranteeType: 8, Privilege: "S", Object: "CUSTOMER", ObjectType: 0}},
+	}
+
+	var sb strings.Builder
+	if err := WriteSchemaScript(&sb, schema); err != nil {
+		t.Fatalf("WriteSchemaScript() error = %v", err)
+	}
+	script := sb.String()
+
+	expected := []string{
+		`CREATE DOMAIN "D_ID" AS INTEGER NOT NULL;`,
+		"CREATE OR ALTER PROCEDURE \"GET_CUSTOMERS\"\nRETURNS (\n    \"ID\" INTEGER)\nAS\nBEGIN\n  SUSPEND;\nEND^",
+		"CREATE TABLE \"CUSTOMER\" (\n    \"ID\" \"D_ID\" NOT NULL,\n    \"NAME\" VARCHAR(50) DEFAULT '',\n    CONSTRAINT \"PK_CUSTOMER\" PRIMARY KEY (\"ID\")\n);",
+		"CREATE VIEW \"V_BASE\" (\"ID\") AS\nSELECT ID FROM CUSTOMER;",
+		"CREATE VIEW \"V_TOP\" (\"ID\") AS\nSELECT ID FROM V_BASE;",
+		`ALTER TABLE "ORDERS" ADD CONSTRAINT "FK_ORDERS" FOREIGN KEY ("CUSTOMER_ID") REFERENCES "CUSTOMER" ("ID") ON DELETE CASCADE;`,
+		"FOR SELECT ID FROM V_TOP INTO :ID DO SUSPEND;\nEND^",
+		"CREATE OR ALTER TRIGGER \"CUSTOMER_BI\" FOR \"CUSTOMER\"\nACTIVE BEFORE INSERT POSITION 0\nAS\nBEGIN\nEND^",
+		`GRANT SELECT ON "CUSTOMER" TO USER "APP";`,
+	}
+
+	// Every statement must be present and appear in dependency order.
+	last := -1
+	for _, e := range expected {
+		idx := strings.Index(script, e)
+		if idx == -1 {
+			t.Fatalf("script does not contain %q\n%s", e, script)
+		}
+		if idx < last {
+			t.Errorf("statement %q is out of order\n%s", e, script)
+		}
+		last = idx
+	}
+}
@@ -0,0 +1,427 @@
+package repository
+
+import (
+	"database/sql"
+	"firebird-web-admin/internal/domain"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+)
+
+// objectKey identifies an object in the dependency graph.
+type objectKey struct {
+	typ  string
+	name string
+}
+
+func (k objectKey) id() string {
+	return k.typ + ":" + k.name
+}
+
+// dependency states that from uses to, or the column of to when column is set.
+type dependency struct {
+	from       objectKey
+	to         objectKey
+	column     string
+	fromColumn string
+}
+
+// dependencyIndex holds all user dependencies of a database.
+type dependencyIndex struct {
+	deps             []dependency
+	views            map[string]bool
+	triggerRelations map[string]string // Table triggers by name, with their relation
+}
+
+// relationKey returns the key of a table or view, whichever the relation is.
+func (ix *dependencyIndex) relationKey(name string) objectKey {
+	if ix.views[name] {
+		return objectKey{"VIEW", name}
+	}
+	return objectKey{"TABLE", name}
+}
+
+// GetDependencies returns the graph of objects the root object uses ("uses"), the objects
+// that use it ("used_by") or both. Without transitive only direct dependencies are returned.
+// A column restricts the first level to dependencies on, or of, that column.
+func (r *FirebirdRepository) GetDependencies(params domain.ConnectionParams, objectType, name, column, direction string, transitive bool) (*domain.DependencyGraph, error) {
+	var uses, usedBy bool
+	switch direction {
+	case "uses":
+		uses = true
+	case "used_by":
+		usedBy = true
+	case "", "both":
+		uses, usedBy = true, true
+	default:
+		return nil, invalidf("direction must be uses, used_by or both")
+	}
+
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	ix, err := loadDependencyIndex(db)
+	if err != nil {
+		log.Printf("GetDependencies error: %v", err)
+		return nil, err
+	}
+	root := objectKey{strings.ToUpper(objectType), name}
+	if root.typ == "TABLE" || root.typ == "VIEW" {
+		root = ix.relationKey(name)
+	}
+	return buildDependencyGraph(ix.deps, root, column, uses, usedBy, transitive), nil
+}
+
+// CheckDDLDependencies returns warnings when stmt drops or replaces an object, or drops or
+// alters a column, that other objects depend on. Other statements return no warnings.
+func (r *FirebirdRepository) CheckDDLDependencies(params domain.ConnectionParams, stmt string) ([]domain.DependencyWarning, error) {
+	targets := parseDDLTargets(stmt)
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	ix, err := loadDependencyIndex(db)
+	if err != nil {
+		log.Printf("CheckDDLDependencies error: %v", err)
+		return nil, err
+	}
+	var warnings []domain.DependencyWarning
+	for _, t := range targets {
+		if w := ix.ddlWarning(t); w != nil {
+			warnings = append(warnings, *w)
+		}
+	}
+	return warnings, nil
+}
+
+// buildDependencyGraph walks the dependencies from root breadth-first in the requested directions.
+func buildDependencyGraph(deps []dependency, root objectKey, column string, uses, usedBy, transitive bool) *domain.DependencyGraph {
+	graph := &domain.DependencyGraph{Root: root.id(), Nodes: []domain.DependencyNode{}, Edges: []domain.DependencyEdge{}}
+	depths := map[string]int{root.id(): 0}
+	order := []objectKey{root}
+	edges := make(map[domain.DependencyEdge]bool)
+
+	walk := func(forward bool) {
+		visited := map[string]bool{root.id(): true}
+		queue := []objectKey{root}
+		for depth := 1; len(queue) > 0 && (transitive || depth == 1); depth++ {
+			var next []objectKey
+			for _, cur := range queue {
+				for _, d := range deps {
+					var other objectKey
+					switch {
+					case forward && d.from == cur && (depth > 1 || column == "" || d.fromColumn == column):
+						other = d.to
+					case !forward && d.to == cur && (depth > 1 || column == "" || d.column == column):
+						other = d.from
+					default:
+						continue
+					}
+					edges[domain.DependencyEdge{From: d.from.id(), To: d.to.id(), Column: d.column, FromColumn: d.fromColumn}] = true
+					if visited[other.id()] {
+						continue
+					}
+					visited[other.id()] = true
+					next = append(next, other)
+					if old, ok := depths[other.id()]; !ok || depth < old {
+						if !ok {
+							order = append(order, other)
+						}
+						depths[other.id()] = depth
+					}
+				}
+			}
+			queue = next
+		}
+	}
+	if uses {
+		walk(true)
+	}
+	if usedBy {
+		walk(false)
+	}
+
+	for _, k := range order {
+		graph.Nodes = append(graph.Nodes, domain.DependencyNode{ID: k.id(), Type: k.typ, Name: k.name, Depth: depths[k.id()]})
+	}
+	for _, d := range deps {
+		e := domain.DependencyEdge{From: d.from.id(), To: d.to.id(), Column: d.column, FromColumn: d.fromColumn}
+		if edges[e] {
+			graph.Edges = append(graph.Edges, e)
+			delete(edges, e)
+		}
+	}
+	return graph
+}
+
+// ddlTarget is the object, and optionally the column, a DDL statement drops or replaces.
+type ddlTarget struct {
+	action string
+	typ    string
+	name   string
+	column string
+}
+
+var (
+	ddlObjectStatement = regexp.MustCompile(`(?is)^\s*(DROP|RECREATE)\s+(TABLE|VIEW|PROCEDURE|FUNCTION|TRIGGER|DOMAIN|EXCEPTION|SEQUENCE|GENERATOR|PACKAGE)\s+("(?:[^"]|"")+"|[A-Z][A-Z0-9_$]*)`)
+	ddlAlterTable      = regexp.MustCompile(`(?is)^\s*ALTER\s+TABLE\s+("(?:[^"]|"")+"|[A-Z][A-Z0-9_$]*)\s+`)
+	ddlColumnClause    = regexp.MustCompile(`(?is)^\s*(DROP|ALTER)\s+(?:COLUMN\s+)?("(?:[^"]|"")+"|[A-Z][A-Z0-9_$]*)(?:\s+(TO|TYPE)\b)?`)
+)
+
+// parseDDLTargets recognizes DROP and RECREATE of objects and the ALTER TABLE clauses that
+// drop, rename or retype a column, in every clause of the statement. Defaults, nullability
+// and position leave dependents intact.
+func parseDDLTargets(stmt string) []ddlTarget {
+	if m := ddlObjectStatement.FindStringSubmatch(stmt); m != nil {
+		typ := strings.ToUpper(m[2])
+		if typ == "GENERATOR" {
+			typ = "SEQUENCE"
+		}
+		if typ == "PACKAGE" && strings.EqualFold(m[3], "BODY") {
+			// Dependents use the package header, which a body change keeps.
+			return nil
+		}
+		return []ddlTarget{{action: strings.ToUpper(m[1]), typ: typ, name: identName(m[3])}}
+	}
+	m := ddlAlterTable.FindStringSubmatch(stmt)
+	if m == nil {
+		return nil
+	}
+	var targets []ddlTarget
+	for _, clause := range splitClauses(stmt[len(m[0]):]) {
+		c := ddlColumnClause.FindStringSubmatch(clause)
+		if c == nil {
+			continue
+		}
+		column := identName(c[2])
+		if !strings.HasPrefix(c[2], "\"") && (column == "CONSTRAINT" || column == "SQL") {
+			// DROP CONSTRAINT and ALTER SQL SECURITY do not touch columns.
+			continue
+		}
+		if strings.EqualFold(c[1], "ALTER") && c[3] == "" {
+			continue
+		}
+		action := "DROP COLUMN"
+		switch strings.ToUpper(c[3]) {
+		case "TO":
+			action = "RENAME COLUMN"
+		case "TYPE":
+			action = "ALTER COLUMN"
+		}
+		targets = append(targets, ddlTarget{action: action, typ: "TABLE", name: identName(m[1]), column: column})
+	}
+	return targets
+}
+
+// splitClauses splits a clause list at the commas outside parentheses, string literals and
+// delimited identifiers.
+func splitClauses(s string) []string {
+	var clauses []string
+	depth, start := 0, 0
+	var quote rune
+	for i, ch := range s {
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case ch == ',' && depth == 0:
+			clauses = append(clauses, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(clauses, s[start:])
+}
+
+// identName unquotes a delimited identifier and upper-cases a regular one.
+func identName(ident string) string {
+	if strings.HasPrefix(ident, "\"") {
+		return strings.ReplaceAll(ident[1:len(ident)-1], "\"\"", "\"")
+	}
+	return strings.ToUpper(ident)
+}
+
+// ddlWarning lists the direct dependents that target would break. Dropping a whole object
+// does not break the object itself, its constraints or, for tables, its own triggers.
+func (ix *dependencyIndex) ddlWarning(t ddlTarget) *domain.DependencyWarning {
+	key := objectKey{t.typ, t.name}
+	if t.typ == "TABLE" || t.typ == "VIEW" {
+		key = ix.relationKey(t.name)
+	}
+	seen := make(map[domain.ObjectUsage]bool)
+	var dependents []domain.ObjectUsage
+	for _, d := range ix.deps {
+		if d.to != key || (t.column != "" && d.column != t.column) {
+			continue
+		}
+		if t.column == "" && (d.from == key || ix.triggerRelations[d.from.name] == t.name && d.from.typ == "TRIGGER") {
+			continue
+		}
+		if t.column != "" && d.from == key && d.fromColumn == t.column {
+			continue
+		}
+		u := domain.ObjectUsage{ObjectType: d.from.typ, Name: d.from.name, Column: d.fromColumn}
+		if !seen[u] {
+			seen[u] = true
+			dependents = append(dependents, u)
+		}
+	}
+	if len(dependents) == 0 {
+		return nil
+	}
+
+	object := key.typ + " " + key.name
+	if t.column != "" {
+		object += "." + t.column
+	}
+	names := make([]string, len(dependents))
+	for i, u := range dependents {
+		names[i] = u.ObjectType + " " + u.Name
+		if u.Column != "" {
+			names[i] += "." + u.Column
+		}
+	}
+	return &domain.DependencyWarning{
+		Action:     t.action,
+		ObjectType: key.typ,
+		Name:       key.name,
+		Column:     t.column,
+		Dependents: dependents,
+		Message:    fmt.Sprintf("%s %s would break %d dependent object(s): %s", t.action, object, len(dependents), strings.Join(names, ", ")),
+	}
+}
+
+// loadDependencyIndex loads RDB$DEPENDENCIES together with the columns based on user domains.
+// Computed columns and CHECK constraints are attributed to their table.
+func loadDependencyIndex(db *sql.DB) (*dependencyIndex, error) {
+	ix := &dependencyIndex{views: make(map[string]bool), triggerRelations: make(map[string]string)}
+
+	relRows, err := db.Query("SELECT RDB$RELATION_NAME FROM RDB$RELATIONS WHERE RDB$VIEW_BLR IS NOT NULL")
+	if err != nil {
+		return nil, err
+	}
+	defer relRows.Close()
+	for relRows.Next() {
+		var name string
+		if err := relRows.Scan(&name); err != nil {
+			return nil, err
+		}
+		ix.views[strings.TrimSpace(name)] = true
+	}
+	if err := relRows.Err(); err != nil {
+		return nil, err
+	}
+
+	trgRows, err := db.Query("SELECT RDB$TRIGGER_NAME, RDB$RELATION_NAME FROM RDB$TRIGGERS WHERE RDB$RELATION_NAME IS NOT NULL")
+	if err != nil {
+		return nil, err
+	}
+	defer trgRows.Close()
+	for trgRows.Next() {
+		var trigger, relation string
+		if err := trgRows.Scan(&trigger, &relation); err != nil {
+			return nil, err
+		}
+		ix.triggerRelations[strings.TrimSpace(trigger)] = strings.TrimSpace(relation)
+	}
+	if err := trgRows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT d.RDB$DEPENDENT_NAME, d.RDB$DEPENDENT_TYPE, d.RDB$DEPENDED_ON_NAME, d.RDB$DEPENDED_ON_TYPE, d.RDB$FIELD_NAME,
+			cf.RDB$RELATION_NAME, cf.RDB$FIELD_NAME, cc.RDB$TRIGGER_NAME
+		FROM RDB$DEPENDENCIES d
+		LEFT JOIN RDB$RELATION_FIELDS cf ON d.RDB$DEPENDENT_TYPE = 3 AND cf.RDB$FIELD_SOURCE = d.RDB$DEPENDENT_NAME
+		LEFT JOIN RDB$CHECK_CONSTRAINTS cc ON d.RDB$DEPENDENT_TYPE IN (2, 4) AND cc.RDB$TRIGGER_NAME = d.RDB$DEPENDENT_NAME
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seen := make(map[dependency]bool)
+	add := func(d dependency) {
+		if isSystemObjectName(d.from.name) || isSystemObjectName(d.to.name) || seen[d] {
+			return
+		}
+		seen[d] = true
+		ix.deps = append(ix.deps, d)
+	}
+	for rows.Next() {
+		var dependent, dependedOn string
+		var dependentType, dependedOnType int64
+		var field, computedRelation, computedField, checkTrigger sql.NullString
+		if err := rows.Scan(&dependent, &dependentType, &dependedOn, &dependedOnType, &field, &computedRelation, &computedField, &checkTrigger); err != nil {
+			return nil, err
+		}
+		d := dependency{
+			from:   objectKey{dependencyTypeName(dependentType), strings.TrimSpace(dependent)},
+			to:     objectKey{dependencyTypeName(dependedOnType), strings.TrimSpace(dependedOn)},
+			column: strings.TrimSpace(field.String),
+		}
+		switch {
+		case computedRelation.Valid:
+			d.from = ix.relationKey(strings.TrimSpace(computedRelation.String))
+			d.fromColumn = strings.TrimSpace(computedField.String)
+		case checkTrigger.Valid:
+			d.from = ix.relationKey(ix.triggerRelations[d.from.name])
+		case dependentType == 0:
+			d.from = ix.relationKey(d.from.name)
+		}
+		if dependedOnType == 0 {
+			d.to = ix.relationKey(d.to.name)
+		}
+		add(d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	fieldRows, err := db.Query(`
+		SELECT RDB$RELATION_NAME, RDB$FIELD_NAME, RDB$FIELD_SOURCE
+		FROM RDB$RELATION_FIELDS
+		WHERE RDB$FIELD_SOURCE NOT STARTING WITH 'RDB$'
+		AND (RDB$SYSTEM_FLAG IS NULL OR RDB$SYSTEM_FLAG = 0)
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer fieldRows.Close()
+	for fieldRows.Next() {
+		var relation, field, source string
+		if err := fieldRows.Scan(&relation, &field, &source); err != nil {
+			return nil, err
+		}
+		add(dependency{
+			from:       ix.relationKey(strings.TrimSpace(relation)),
+			to:         objectKey{"DOMAIN", strings.TrimSpace(source)},
+			fromColumn: strings.TrimSpace(field),
+		})
+	}
+	return ix, fieldRows.Err()
+}
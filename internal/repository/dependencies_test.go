@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDDLTargets(t *testing.T) {
+	tests := []struct {
+		name     string
+		stmt     string
+		expected []ddlTarget
+	}{
+		{name: "Drop table", stmt: "drop table customer", expected: []ddlTarget{{action: "DROP", typ: "TABLE", name: "CUSTOMER"}}},
+		{name: "Recreate view", stmt: "RECREATE VIEW \"Sales\" AS SELECT 1 FROM RDB$DATABASE", expected: []ddlTarget{{action: "RECREATE", typ: "VIEW", name: "Sales"}}},
+		{name: "Drop generator", stmt: "DROP GENERATOR GEN_ID", expected: []ddlTarget{{action: "DROP", typ: "SEQUENCE", name: "GEN_ID"}}},
+		{name: "Drop package body", stmt: "DROP PACKAGE BODY PKG"},
+		{name: "Drop package", stmt: "DROP PACKAGE BODYGUARD", expected: []ddlTarget{{action: "DROP", typ: "PACKAGE", name: "BODYGUARD"}}},
+		{name: "Drop column", stmt: "ALTER TABLE CUSTOMER DROP CITY", expected: []ddlTarget{{action: "DROP COLUMN", typ: "TABLE", name: "CUSTOMER", column: "CITY"}}},
+		{name: "Alter column", stmt: "ALTER TABLE CUSTOMER ALTER COLUMN \"City\" TYPE VARCHAR(40)", expected: []ddlTarget{{action: "ALTER COLUMN", typ: "TABLE", name: "CUSTOMER", column: "City"}}},
+		{name: "Rename column", stmt: "alter table customer alter city to town", expected: []ddlTarget{{action: "RENAME COLUMN", typ: "TABLE", name: "CUSTOMER", column: "CITY"}}},
+		{name: "Set default", stmt: "ALTER TABLE CUSTOMER ALTER COLUMN CITY SET DEFAULT 'Berlin'"},
+		{name: "Drop default", stmt: "ALTER TABLE CUSTOMER ALTER CITY DROP DEFAULT"},
+		{name: "Position", stmt: "ALTER TABLE CUSTOMER ALTER CITY POSITION 2"},
+		{name: "Drop not null", stmt: "ALTER TABLE CUSTOMER ALTER CITY DROP NOT NULL"},
+		{name: "Column named like a keyword prefix", stmt: "ALTER TABLE CUSTOMER ALTER TYPES TYPE INTEGER", expected: []ddlTarget{{action: "ALTER COLUMN", typ: "TABLE", name: "CUSTOMER", column: "TYPES"}}},
+		{name: "Drop constraint", stmt: "ALTER TABLE CUSTOMER DROP CONSTRAINT PK_CUSTOMER"},
+		{name: "Add column", stmt: "ALTER TABLE CUSTOMER ADD CITY VARCHAR(20)"},
+		{name: "Select", stmt: "SELECT * FROM CUSTOMER"},
+		{
+			name: "Every clause",
+			stmt: "ALTER TABLE CUSTOMER ADD RATE NUMERIC(10, 2), DROP CITY, ALTER NOTE SET DEFAULT 'a, b', ALTER ZIP TYPE CHAR(5)",
+			expected: []ddlTarget{
+				{action: "DROP COLUMN", typ: "TABLE", name: "CUSTOMER", column: "CITY"},
+				{action: "ALTER COLUMN", typ: "TABLE", name: "CUSTOMER", column: "ZIP"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseDDLTargets(tt.stmt)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("parseDDLTargets() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBuildDependencyGraph(t *testing.T) {
+	table := objectKey{"TABLE", "CUSTOMER"}
+	view := objectKey{"VIEW", "V_CUSTOMER"}
+	proc := objectKey{"PROCEDURE", "REPORT"}
+	dom := objectKey{"DOMAIN", "D_NAME"}
+	deps := []dependency{
+		{from: view, to: table, column: "NAME"},
+		{from: view, to: table, column: "CITY"},
+		{from: proc, to: view, column: "NAME"},
+		{from: table, to: dom, fromColumn: "NAME"},
+	}
+
+	tests := []struct {
+		name       string
+		root       objectKey
+		column     string
+		uses       bool
+		usedBy     bool
+		transitive bool
+		nodes      []string
+		edges      int
+	}{
+		{name: "Direct dependents", root: table, usedBy: true, nodes: []string{"TABLE:CUSTOMER", "VIEW:V_CUSTOMER"}, edges: 2},
+		{name: "Transitive dependents", root: table, usedBy: true, transitive: true, nodes: []string{"TABLE:CUSTOMER", "VIEW:V_CUSTOMER", "PROCEDURE:REPORT"}, edges: 3},
+		{name: "Column dependents", root: table, column: "CITY", usedBy: true, transitive: true, nodes: []string{"TABLE:CUSTOMER", "VIEW:V_CUSTOMER", "PROCEDURE:REPORT"}, edges: 2},
+		{name: "Uses", root: proc, uses: true, transitive: true, nodes: []string{"PROCEDURE:REPORT", "VIEW:V_CUSTOMER", "TABLE:CUSTOMER", "DOMAIN:D_NAME"}, edges: 4},
+		{name: "Both", root: view, uses: true, usedBy: true, nodes: []string{"VIEW:V_CUSTOMER", "TABLE:CUSTOMER", "PROCEDURE:REPORT"}, edges: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := buildDependencyGraph(deps, tt.root, tt.column, tt.uses, tt.usedBy, tt.transitive)
+			var nodes []string
+			for _, n := range graph.Nodes {
+				nodes = append(nodes, n.ID)
+			}
+			if !reflect.DeepEqual(nodes, tt.nodes) {
+				t.Errorf("nodes = %v, want %v", nodes, tt.nodes)
+			}
+			if len(graph.Edges) != tt.edges {
+				t.Errorf("edges = %v, want %d", graph.Edges, tt.edges)
+			}
+		})
+	}
+}
+
+func TestDDLWarning(t *testing.T) {
+	ix := &dependencyIndex{
+		views:            map[string]bool{"V_CUSTOMER": true},
+		triggerRelations: map[string]string{"CUSTOMER_BI": "CUSTOMER"},
+		deps: []dependency{
+			{from: objectKey{"VIEW", "V_CUSTOMER"}, to: objectKey{"TABLE", "CUSTOMER"}, column: "NAME"},
+			{from: objectKey{"TRIGGER", "CUSTOMER_BI"}, to: objectKey{"TABLE", "CUSTOMER"}, column: "CITY"},
+		},
+	}
+
+	if w := ix.ddlWarning(ddlTarget{action: "DROP", typ: "TABLE", name: "CUSTOMER"}); w == nil || len(w.Dependents) != 1 || w.Dependents[0].Name != "V_CUSTOMER" {
+		t.Errorf("drop table warning = %+v, want only V_CUSTOMER", w)
+	}
+	if w := ix.ddlWarning(ddlTarget{action: "DROP COLUMN", typ: "TABLE", name: "CUSTOMER", column: "CITY"}); w == nil || w.Dependents[0].Name != "CUSTOMER_BI" {
+		t.Errorf("drop column warning = %+v, want CUSTOMER_BI", w)
+	}
+	if w := ix.ddlWarning(ddlTarget{action: "DROP", typ: "VIEW", name: "V_CUSTOMER"}); w != nil {
+		t.Errorf("drop view warning = %+v, want none", w)
+	}
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"database/sql"
+	"firebird-web-admin/internal/domain"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// ListDomains returns user domains with the columns, parameters and PSQL objects that use them.
+func (r *FirebirdRepository) ListDomains(params domain.ConnectionParams) ([]domain.Domain, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	charset, err := loadDefaultCharset(db)
+	if err != nil {
+		log.Printf("ListDomains error: %v", err)
+		return nil, err
+	}
+	domains, err := loadDomains(db, charset)
+	if err != nil {
+		log.Printf("ListDomains error: %v", err)
+		return nil, err
+	}
+	usage, err := loadDomainUsage(db)
+	if err != nil {
+		log.Printf("ListDomains usage error: %v", err)
+		return nil, err
+	}
+	for i := range domains {
+		domains[i].UsedBy = usage[domains[i].Name]
+	}
+	return domains, nil
+}
+
+func (r *FirebirdRepository) GetDomain(params domain.ConnectionParams, name string) (*domain.Domain, error) {
+	name = objectName(name)
+	domains, err := r.ListDomains(params)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range domains {
+		if d.Name == name {
+			return &d, nil
+		}
+	}
+	return nil, &domain.NotFoundError{ObjectType: "domain", Name: name}
+}
+
+// GetDomainDDL returns the CREATE DOMAIN statement of a domain.
+func (r *FirebirdRepository) GetDomainDDL(params domain.ConnectionParams, name string) (string, error) {
+	d, err := r.GetDomain(params, name)
+	if err != nil {
+		return "", err
+	}
+	return domainSQL(*d), nil
+}
+
+func (r *FirebirdRepository) CreateDomain(params domain.ConnectionParams, d domain.Domain) error {
+	d.Name = strings.TrimSpace(d.Name)
+	switch {
+	case d.Name == "":
+		return invalidf("domain name is required")
+	case strings.TrimSpace(d.Type) == "":
+		return invalidf("domain type is required")
+	}
+	return r.execDDL(params, domainSQL(d))
+}
+
+// AlterDomain changes the attributes of a domain that req sets with ALTER DOMAIN
+// statements, renaming it last when the name differs. The statements run one by one,
+// so a failure leaves the earlier changes applied.
+func (r *FirebirdRepository) AlterDomain(params domain.ConnectionParams, name string, req domain.DomainRequest) error {
+	current, err := r.GetDomain(params, name)
+	if err != nil {
+		return err
+	}
+	stmts, err := domainAlterStatements(*current, req)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range stmts {
+		if err := r.execDDL(params, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *FirebirdRepository) DropDomain(params domain.ConnectionParams, name string) error {
+	return r.execDDL(params, "DROP DOMAIN "+quoteIdent(objectName(name)))
+}
+
+// domainAlterStatements returns the ALTER DOMAIN statements that apply req to current,
+// using the same rules as the schema compare.
+func domainAlterStatements(current domain.Domain, req domain.DomainRequest) ([]string, error) {
+	desired := current
+	if req.Type != nil {
+		if strings.TrimSpace(*req.Type) == "" {
+			return nil, invalidf("domain type cannot be empty")
+		}
+		desired.Type = *req.Type
+	}
+	if req.NotNull != nil {
+		desired.NotNull = *req.NotNull
+	}
+	if req.Default != nil {
+		desired.Default = *req.Default
+	}
+	if req.Check != nil {
+		desired.Check = *req.Check
+	}
+	if req.Collation != nil && *req.Collation != "" {
+		// The collation cannot be altered, so it is only compared when given.
+		desired.Collation = *req.Collation
+	}
+
+	m := &migration{}
+	diffDomains(m, []domain.Domain{desired}, []domain.Domain{current})
+	if len(m.warnings) > 0 {
+		return nil, invalidf("%s", m.warnings[0])
+	}
+	var stmts []string
+	for _, stmt := range m.phases[phaseDomains] {
+		stmts = append(stmts, stmt.sql)
+	}
+	if req.Name != nil {
+		if newName := strings.TrimSpace(*req.Name); newName != "" && newName != current.Name {
+			stmts = append(stmts, fmt.Sprintf("ALTER DOMAIN %s TO %s", quoteIdent(current.Name), quoteIdent(newName)))
+		}
+	}
+	return stmts, nil
+}
+
+// loadDomainUsage returns, for each domain, the table and view columns and the routine
+// parameters based on it, followed by the other objects whose source references it.
+func loadDomainUsage(db *sql.DB) (map[string][]domain.ObjectUsage, error) {
+	rows, err := db.Query(`
+		SELECT rf.RDB$FIELD_SOURCE, IIF(r.RDB$VIEW_BLR IS NULL, 'TABLE', 'VIEW'), rf.RDB$RELATION_NAME, rf.RDB$FIELD_NAME
+		FROM RDB$RELATION_FIELDS rf
+		JOIN RDB$RELATIONS r ON r.RDB$RELATION_NAME = rf.RDB$RELATION_NAME
+		WHERE rf.RDB$FIELD_SOURCE NOT STARTING WITH 'RDB$'
+		UNION ALL
+		SELECT pp.RDB$FIELD_SOURCE, 'PROCEDURE', COALESCE(TRIM(pp.RDB$PACKAGE_NAME) || '.', '') || TRIM(pp.RDB$PROCEDURE_NAME), pp.RDB$PARAMETER_NAME
+		FROM RDB$PROCEDURE_PARAMETERS pp
+		WHERE pp.RDB$FIELD_SOURCE NOT STARTING WITH 'RDB$'
+		UNION ALL
+		SELECT fa.RDB$FIELD_SOURCE, 'FUNCTION', COALESCE(TRIM(fa.RDB$PACKAGE_NAME) || '.', '') || TRIM(fa.RDB$FUNCTION_NAME), fa.RDB$ARGUMENT_NAME
+		FROM RDB$FUNCTION_ARGUMENTS fa
+		WHERE fa.RDB$FIELD_SOURCE NOT STARTING WITH 'RDB$'
+		ORDER BY 1, 2, 3
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := make(map[string][]domain.ObjectUsage)
+	for rows.Next() {
+		var domainName, objectType, objectName string
+		var column sql.NullString
+		if err := rows.Scan(&domainName, &objectType, &objectName, &column); err != nil {
+			return nil, err
+		}
+		domainName = strings.TrimSpace(domainName)
+		usage[domainName] = append(usage[domainName], domain.ObjectUsage{
+			ObjectType: strings.TrimSpace(objectType),
+			Name:       strings.TrimSpace(objectName),
+			Column:     strings.TrimSpace(column.String),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Variables declared with a domain type only show up in RDB$DEPENDENCIES.
+	dependents, err := loadDependentObjects(db, objDomain)
+	if err != nil {
+		return nil, err
+	}
+	for domainName, objects := range dependents {
+		for _, o := range objects {
+			if !hasObjectUsage(usage[domainName], o) {
+				usage[domainName] = append(usage[domainName], o)
+			}
+		}
+	}
+	return usage, nil
+}
+
+func hasObjectUsage(usage []domain.ObjectUsage, o domain.ObjectUsage) bool {
+	for _, u := range usage {
+		if u.ObjectType == o.ObjectType && u.Name == o.Name {
+			return true
+		}
+	}
+	return false
+}
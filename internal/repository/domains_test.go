@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"firebird-web-admin/internal/domain"
+	"reflect"
+	"testing"
+)
+
+func TestDomainAlterStatements(t *testing.T) {
+	current := domain.Domain{Name: "D_PRICE", Type: "NUMERIC(10, 2)", Default: "DEFAULT 0", Check: "CHECK (VALUE >= 0)", Collation: ""}
+
+	str := func(s string) *string { return &s }
+	yes := true
+
+	tests := []struct {
+		name     string
+		req      domain.DomainRequest
+		expected []string
+		hasError bool
+	}{
+		{
+			name: "Empty request",
+		},
+		{
+			name: "Unchanged values",
+			req:  domain.DomainRequest{Name: str("D_PRICE"), Type: str("NUMERIC(10, 2)"), Default: str("DEFAULT 0")},
+		},
+		{
+			name: "Type, default and check",
+			req:  domain.DomainRequest{Type: str("NUMERIC(12, 2)"), NotNull: &yes, Default: str(""), Check: str("CHECK (VALUE > 0)")},
+			expected: []string{
+				`ALTER DOMAIN "D_PRICE" TYPE NUMERIC(12, 2)`,
+				`ALTER DOMAIN "D_PRICE" DROP DEFAULT`,
+				`ALTER DOMAIN "D_PRICE" SET NOT NULL`,
+				`ALTER DOMAIN "D_PRICE" DROP CONSTRAINT`,
+				`ALTER DOMAIN "D_PRICE" ADD CONSTRAINT CHECK (VALUE > 0)`,
+			},
+		},
+		{
+			name:     "Partial update keeps default and check",
+			req:      domain.DomainRequest{Type: str("NUMERIC(12, 2)")},
+			expected: []string{`ALTER DOMAIN "D_PRICE" TYPE NUMERIC(12, 2)`},
+		},
+		{
+			name:     "Drop check only",
+			req:      domain.DomainRequest{Check: str("")},
+			expected: []string{`ALTER DOMAIN "D_PRICE" DROP CONSTRAINT`},
+		},
+		{
+			name:     "Rename keeps the other attributes",
+			req:      domain.DomainRequest{Name: str("D_AMOUNT")},
+			expected: []string{`ALTER DOMAIN "D_PRICE" TO "D_AMOUNT"`},
+		},
+		{
+			name:     "Empty type",
+			req:      domain.DomainRequest{Type: str(" ")},
+			hasError: true,
+		},
+		{
+			name:     "Collation",
+			req:      domain.DomainRequest{Collation: str("UNICODE_CI")},
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := domainAlterStatements(current, tt.req)
+			if (err != nil) != tt.hasError {
+				t.Fatalf("domainAlterStatements() error = %v, hasError %v", err, tt.hasError)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("domainAlterStatements() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCollationSQL(t *testing.T) {
+	c := domain.Collation{Name: "UNICODE_DE_CI", CharacterSet: "UTF8", BaseCollation: "UNICODE", PadSpace: true, CaseInsensitive: true, SpecificAttributes: "LOCALE=de_DE"}
+	expected := `CREATE COLLATION "UNICODE_DE_CI" FOR "UTF8" FROM "UNICODE" PAD SPACE CASE INSENSITIVE 'LOCALE=de_DE'`
+	if got := collationSQL(c); got != expected {
+		t.Errorf("collationSQL() = %v, want %v", got, expected)
+	}
+}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// defaultPort is the port of the Firebird server and of its gds_db service name.
+const defaultPort = "3050"
+
+var (
+	hostName    = regexp.MustCompile(`^[A-Za-z0-9_]([A-Za-z0-9_.-]*[A-Za-z0-9_])?$`)
+	serviceName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+	urlScheme   = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+)
+
+// databaseLocation is a parsed Firebird connection string. Exactly one of Path and Alias
+// is set.
+type databaseLocation struct {
+	Protocol string // "inet", "inet4" or "inet6" for URLs, empty for host:path
+	Host     string // Without brackets, empty for a local database
+	Port     string // Port number or service name, empty for the default
+	Path     string
+	Alias    string
+}
+
+// parseDatabase parses the connection strings isql accepts over TCP/IP:
+//
+//	employee                      alias on localhost
+//	/data/app.fdb, C:\data\app.fdb
+//	host:employee, host:C:\data\app.fdb
+//	host/3051:employee, host/gds_db:employee
+//	[::1]:employee, [::1]/3051:employee
+//	inet://host:3051/employee, inet://host//data/app.fdb, inet6://[::1]/employee
+//
+// A letter followed by :\ or :/ is a drive, not a host. XNET and WNET connection strings
+// are rejected, the driver only speaks TCP/IP.
+func parseDatabase(s string) (databaseLocation, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return databaseLocation{}, fmt.Errorf("database is required")
+	}
+	if i := strings.Index(s, "://"); i > 0 && urlScheme.MatchString(s[:i]) {
+		return parseDatabaseURL(strings.ToLower(s[:i]), s[i+3:])
+	}
+	if strings.HasPrefix(s, `\\`) {
+		return databaseLocation{}, fmt.Errorf("named pipe connection strings (\\\\server\\path) are not supported, use server:path")
+	}
+	if strings.HasPrefix(s, "/") || isDrivePath(s) {
+		return newDatabaseLocation("", "", "", s)
+	}
+
+	end := 0
+	if strings.HasPrefix(s, "[") {
+		if end = strings.Index(s, "]"); end == -1 {
+			return databaseLocation{}, fmt.Errorf("missing ] after IPv6 address in %q", s)
+		}
+	}
+	colon := strings.Index(s[end:], ":")
+	if colon == -1 {
+		if end > 0 {
+			return databaseLocation{}, fmt.Errorf("missing database path or alias after %q", s)
+		}
+		return newDatabaseLocation("", "", "", s)
+	}
+	colon += end
+	server, path := s[:colon], s[colon+1:]
+	if strings.HasPrefix(path, ":") {
+		return databaseLocation{}, fmt.Errorf("IPv6 addresses must be enclosed in brackets, e.g. [::1]:employee")
+	}
+
+	host, port := server, ""
+	if host == "" {
+		return databaseLocation{}, fmt.Errorf("missing host before %q", s[colon:])
+	}
+	if slash := strings.LastIndex(server, "/"); slash > end {
+		host, port = server[:slash], server[slash+1:]
+		if port == "" {
+			return databaseLocation{}, fmt.Errorf("missing port after %q", host+"/")
+		}
+	}
+	if end > 0 {
+		if host[len(host)-1] != ']' {
+			return databaseLocation{}, fmt.Errorf("unexpected %q after IPv6 address", host[end+1:])
+		}
+		if host = host[1 : len(host)-1]; !isIPv6(host) {
+			return databaseLocation{}, fmt.Errorf("invalid IPv6 address %q", host)
+		}
+	}
+	return newDatabaseLocation("", host, port, path)
+}
+
+// parseDatabaseURL parses the part after "scheme://": [host[:port]]/database. The database
+// starts after the first slash, so absolute POSIX paths take two.
+func parseDatabaseURL(scheme, rest string) (databaseLocation, error) {
+	switch scheme {
+	case "inet", "inet4", "inet6":
+	case "xnet", "wnet":
+		return databaseLocation{}, fmt.Errorf("%s:// is not supported, the driver only connects over TCP/IP (inet://)", scheme)
+	default:
+		return databaseLocation{}, fmt.Errorf("unknown protocol %s://, use inet://, inet4:// or inet6://", scheme)
+	}
+
+	from := 0
+	if strings.HasPrefix(rest, "[") {
+		if from = strings.Index(rest, "]"); from == -1 {
+			return databaseLocation{}, fmt.Errorf("missing ] after IPv6 address in %s://%s", scheme, rest)
+		}
+	}
+	slash := strings.Index(rest[from:], "/")
+	if slash == -1 {
+		return databaseLocation{}, fmt.Errorf("missing database path or alias in %s://%s", scheme, rest)
+	}
+	authority, path := rest[:from+slash], rest[from+slash+1:]
+
+	host, port := authority, ""
+	if from > 0 {
+		after := authority[from+1:]
+		if after != "" && !strings.HasPrefix(after, ":") {
+			return databaseLocation{}, fmt.Errorf("unexpected %q after IPv6 address", after)
+		}
+		host, port = authority[1:from], strings.TrimPrefix(after, ":")
+		if !isIPv6(host) {
+			return databaseLocation{}, fmt.Errorf("invalid IPv6 address %q", host)
+		}
+	} else if i := strings.Index(authority, ":"); i != -1 {
+		if strings.Count(authority, ":") > 1 {
+			return databaseLocation{}, fmt.Errorf("IPv6 addresses must be enclosed in brackets, e.g. %s://[::1]/employee", scheme)
+		}
+		host, port = authority[:i], authority[i+1:]
+	}
+	if strings.HasSuffix(authority, ":") {
+		return databaseLocation{}, fmt.Errorf("missing port after %q", authority)
+	}
+
+	loc, err := newDatabaseLocation(scheme, host, port, path)
+	if err != nil {
+		return loc, err
+	}
+	if ip := net.ParseIP(loc.Host); ip != nil {
+		if scheme == "inet4" && ip.To4() == nil {
+			return databaseLocation{}, fmt.Errorf("inet4:// needs an IPv4 address, got %s", loc.Host)
+		}
+		if scheme == "inet6" && ip.To4() != nil {
+			return databaseLocation{}, fmt.Errorf("inet6:// needs an IPv6 address, got %s", loc.Host)
+		}
+	}
+	return loc, nil
+}
+
+// newDatabaseLocation validates the parts of a connection string. The database is an alias
+// unless it has a path separator or a drive letter.
+func newDatabaseLocation(protocol, host, port, database string) (databaseLocation, error) {
+	if host != "" && !validHost(host) {
+		return databaseLocation{}, fmt.Errorf("invalid host %q", host)
+	}
+	if port != "" {
+		if n, err := strconv.Atoi(port); err == nil {
+			if n < 1 || n > 65535 {
+				return databaseLocation{}, fmt.Errorf("port %s is out of range 1-65535", port)
+			}
+		} else if !serviceName.MatchString(port) {
+			return databaseLocation{}, fmt.Errorf("invalid port or service name %q", port)
+		}
+	}
+	if strings.TrimSpace(database) == "" {
+		return databaseLocation{}, fmt.Errorf("missing database path or alias")
+	}
+	loc := databaseLocation{Protocol: protocol, Host: host, Port: port}
+	if strings.ContainsAny(database, `/\`) || isDrivePath(database) {
+		loc.Path = database
+	} else {
+		loc.Alias = database
+	}
+	return loc, nil
+}
+
+func validHost(host string) bool {
+	if strings.Contains(host, ":") {
+		return isIPv6(host)
+	}
+	return hostName.MatchString(host)
+}
+
+func isIPv6(host string) bool {
+	ip := net.ParseIP(host)
+	return ip != nil && ip.To4() == nil
+}
+
+// isDrivePath reports whether s starts with a Windows drive letter, e.g. C: or C:\.
+func isDrivePath(s string) bool {
+	return len(s) >= 2 && s[1] == ':' && ('A' <= s[0] && s[0] <= 'Z' || 'a' <= s[0] && s[0] <= 'z') &&
+		(len(s) == 2 || s[2] == '\\' || s[2] == '/')
+}
+
+// address is host:port for the driver and the Services Manager, localhost for a local
+// database. Service names are resolved to port numbers, gds_db being 3050. Otherwise the
+// port is left to the driver default unless the host is an IPv6 address, which the driver
+// only recognises with one.
+func (l databaseLocation) address() (string, error) {
+	host := l.Host
+	if host == "" {
+		host = "localhost"
+	}
+	port := l.Port
+	if strings.EqualFold(port, "gds_db") || port == "" && strings.Contains(host, ":") {
+		port = defaultPort
+	} else if port != "" {
+		n, err := net.LookupPort("tcp", port)
+		if err != nil {
+			return "", fmt.Errorf("unknown service name %q", port)
+		}
+		port = strconv.Itoa(n)
+	}
+	if port == "" {
+		return host, nil
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// database is the path or alias to open on the server.
+func (l databaseLocation) database() string {
+	if l.Path != "" {
+		return l.Path
+	}
+	return l.Alias
+}
+
+// dsnPath is the database as the URL path of a driver DSN. The driver drops the leading
+// slash of single-segment paths (aliases), and of Windows paths, so absolute POSIX paths
+// with one segment keep an extra slash. Characters the URL parser treats specially are
+// escaped.
+func (l databaseLocation) dsnPath() string {
+	path := strings.NewReplacer("%", "%25", "?", "%3F", "#", "%23").Replace(l.database())
+	if strings.HasPrefix(path, "/") && strings.Contains(path[1:], "/") {
+		return path
+	}
+	return "/" + path
+}
+
+// dsnDatabase returns the host[:port]/database part of a driver DSN.
+func dsnDatabase(database string) (string, error) {
+	loc, err := parseDatabase(database)
+	if err != nil {
+		return "", err
+	}
+	addr, err := loc.address()
+	if err != nil {
+		return "", err
+	}
+	return addr + loc.dsnPath(), nil
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"firebird-web-admin/internal/domain"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// sqlErrorPosition matches both "Token unknown - line 3, column 5" and "At line 3, column 5".
+var sqlErrorPosition = regexp.MustCompile(`(?i)line (\d+), column (\d+)`)
+
+// sqlError converts a server error that reports a position into a *domain.SQLError.
+// lineOffset is the number of generated lines preceding the user's text, so the position
+// refers to what the user wrote. Errors without a position are returned unchanged.
+func sqlError(err error, lineOffset int) error {
+	if err == nil {
+		return nil
+	}
+	m := sqlErrorPosition.FindStringSubmatch(err.Error())
+	if m == nil {
+		return err
+	}
+	line, _ := strconv.Atoi(m[1])
+	column, _ := strconv.Atoi(m[2])
+	if line > lineOffset {
+		line -= lineOffset
+	}
+	return &domain.SQLError{
+		Message: strings.TrimSpace(err.Error()),
+		Line:    line,
+		Column:  column,
+	}
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"database/sql"
+	"firebird-web-admin/internal/domain"
+	"log"
+	"strings"
+)
+
+// ListExceptions returns user exceptions with their message text and the routines and
+// triggers that raise them.
+func (r *FirebirdRepository) ListExceptions(params domain.ConnectionParams) ([]domain.DatabaseException, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	exceptions, err := loadExceptions(db)
+	if err != nil {
+		log.Printf("ListExceptions error: %v", err)
+		return nil, err
+	}
+	usage, err := loadDependentObjects(db, objException)
+	if err != nil {
+		log.Printf("ListExceptions usage error: %v", err)
+		return nil, err
+	}
+	for i := range exceptions {
+		exceptions[i].UsedBy = usage[exceptions[i].Name]
+	}
+	return exceptions, nil
+}
+
+func (r *FirebirdRepository) GetException(params domain.ConnectionParams, name string) (*domain.DatabaseException, error) {
+	name = objectName(name)
+	exceptions, err := r.ListExceptions(params)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range exceptions {
+		if e.Name == name {
+			return &e, nil
+		}
+	}
+	return nil, &domain.NotFoundError{ObjectType: "exception", Name: name}
+}
+
+// GetExceptionDDL returns the CREATE OR ALTER EXCEPTION statement of an exception.
+func (r *FirebirdRepository) GetExceptionDDL(params domain.ConnectionParams, name string) (string, error) {
+	e, err := r.GetException(params, name)
+	if err != nil {
+		return "", err
+	}
+	return "CREATE OR ALTER" + strings.TrimPrefix(exceptionSQL(*e), "CREATE"), nil
+}
+
+func (r *FirebirdRepository) CreateException(params domain.ConnectionParams, e domain.DatabaseException) error {
+	e.Name = strings.TrimSpace(e.Name)
+	if e.Name == "" {
+		return invalidf("exception name is required")
+	}
+	return r.execDDL(params, exceptionSQL(e))
+}
+
+// AlterException changes the message text of an exception.
+func (r *FirebirdRepository) AlterException(params domain.ConnectionParams, name string, message string) error {
+	return r.execDDL(params, alterExceptionSQL(objectName(name), message))
+}
+
+func (r *FirebirdRepository) DropException(params domain.ConnectionParams, name string) error {
+	return r.execDDL(params, "DROP EXCEPTION "+quoteIdent(objectName(name)))
+}
+
+// loadDependentObjects returns, for each object of the given RDB$DEPENDENCIES type,
+// the objects whose source references it.
+func loadDependentObjects(db *sql.DB, objectType int) (map[string][]domain.ObjectUsage, error) {
+	rows, err := db.Query(`
+		SELECT DISTINCT RDB$DEPENDED_ON_NAME, RDB$DEPENDENT_TYPE, RDB$DEPENDENT_NAME
+		FROM RDB$DEPENDENCIES
+		WHERE RDB$DEPENDED_ON_TYPE = ?
+		ORDER BY 1, 2, 3
+	`, objectType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := make(map[string][]domain.ObjectUsage)
+	for rows.Next() {
+		var object, dependent string
+		var dependentType int64
+		if err := rows.Scan(&object, &dependentType, &dependent); err != nil {
+			return nil, err
+		}
+		object = strings.TrimSpace(object)
+		usage[object] = append(usage[object], domain.ObjectUsage{
+			ObjectType: dependencyTypeName(dependentType),
+			Name:       strings.TrimSpace(dependent),
+		})
+	}
+	return usage, rows.Err()
+}
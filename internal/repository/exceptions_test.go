@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"firebird-web-admin/internal/domain"
+	"testing"
+)
+
+func TestExceptionSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		got      string
+		expected string
+	}{
+		{name: "Create", got: exceptionSQL(domain.DatabaseException{Name: "E_NO_STOCK", Message: "Out of stock"}), expected: `CREATE EXCEPTION "E_NO_STOCK" 'Out of stock'`},
+		{name: "Create with quotes", got: exceptionSQL(domain.DatabaseException{Name: "e\"x", Message: "Can't ship"}), expected: `CREATE EXCEPTION "e""x" 'Can''t ship'`},
+		{name: "Create with parameter placeholders", got: exceptionSQL(domain.DatabaseException{Name: "E_LIMIT", Message: "Limit @1 exceeded by @2"}), expected: `CREATE EXCEPTION "E_LIMIT" 'Limit @1 exceeded by @2'`},
+		{name: "Create with empty message", got: exceptionSQL(domain.DatabaseException{Name: "E_EMPTY"}), expected: `CREATE EXCEPTION "E_EMPTY" ''`},
+		{name: "Alter", got: alterExceptionSQL("E_NO_STOCK", "Item is out of stock"), expected: `ALTER EXCEPTION "E_NO_STOCK" 'Item is out of stock'`},
+		{name: "Alter with quotes", got: alterExceptionSQL("E_NO_STOCK", "'quoted'"), expected: `ALTER EXCEPTION "E_NO_STOCK" '''quoted'''`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.expected {
+				t.Errorf("got %q, want %q", tt.got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"firebird-web-admin/internal/domain"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+)
+
+// planObject matches an explained plan line that accesses a named object, e.g.
+// `Table "EMPLOYEE" as "E" Full Scan` or `Index "RDB$PRIMARY7" Unique Scan`.
+var planObject = regexp.MustCompile(`^(Table|Index|Procedure|Function) ("(?:[^"]|"")*"|[^\s"]+)(?: as ((?:"(?:[^"]|"")*"\s*)+))?\s*(.*)$`)
+
+// planIdent matches one identifier of an alias chain such as `"V" "E"`.
+var planIdent = regexp.MustCompile(`"(?:[^"]|"")*"`)
+
+// ExplainQuery prepares a statement without executing it and returns its plan.
+//
+// The driver does not expose the isc_info_sql_get_plan / isc_info_sql_explain_plan
+// requests, so the statement is prepared on a dedicated connection and its explained plan
+// is read back from MON$STATEMENTS of that attachment (Firebird 3 and later). The legacy
+// PLAN clause is rebuilt from the explained plan.
+func (r *FirebirdRepository) ExplainQuery(params domain.ConnectionParams, query string) (*domain.QueryPlan, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, invalidf("SQL statement is required")
+	}
+
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	stmt, err := conn.PrepareContext(ctx, query)
+	if err != nil {
+		log.Printf("ExplainQuery prepare error: %v", err)
+		return nil, sqlError(err, 0)
+	}
+	defer stmt.Close()
+
+	rows, err := conn.QueryContext(ctx, `
+		SELECT MON$SQL_TEXT, MON$EXPLAINED_PLAN
+		FROM MON$STATEMENTS
+		WHERE MON$ATTACHMENT_ID = CURRENT_CONNECTION AND MON$STATE = 0
+		ORDER BY MON$STATEMENT_ID DESC
+	`)
+	if err != nil {
+		log.Printf("ExplainQuery monitoring error: %v", err)
+		if strings.Contains(err.Error(), "MON$EXPLAINED_PLAN") {
+			return nil, &domain.UnsupportedError{Message: "explained plans require Firebird 3 or later"}
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	// The statement is matched by its text. Another statement's plan is never returned.
+	var explained string
+	found := false
+	for rows.Next() {
+		var text, plan sql.NullString
+		if err := rows.Scan(&text, &plan); err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(text.String) == query {
+			explained, found = plan.String, true
+			break
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("the prepared statement was not found in MON$STATEMENTS, its plan is not available")
+	}
+	return explainPlan(explained), nil
+}
+
+// explainPlan parses an explained plan into a QueryPlan.
+func explainPlan(explained string) *domain.QueryPlan {
+	explained = strings.Trim(explained, "\r\n")
+	plan := &domain.QueryPlan{
+		ExplainedPlan: explained,
+		Tree:          parseExplainedPlan(explained),
+		NaturalScans:  []string{},
+		Indexes:       []string{},
+	}
+	if plan.Tree == nil {
+		plan.Tree = []domain.PlanNode{}
+	}
+
+	var lines []string
+	for _, root := range plan.Tree {
+		if items := legacyPlanItems(root.Children); len(items) > 0 {
+			lines = append(lines, "PLAN "+planStreams(items))
+		}
+	}
+	plan.Plan = strings.Join(lines, "\n")
+
+	seen := make(map[string]bool)
+	var walk func(nodes []domain.PlanNode)
+	walk = func(nodes []domain.PlanNode) {
+		for _, n := range nodes {
+			switch {
+			case n.Kind == "Table" && n.Access == "Full Scan":
+				plan.NaturalScans = append(plan.NaturalScans, planStreamName(n))
+			case n.Kind == "Index" && !seen[n.Object]:
+				seen[n.Object] = true
+				plan.Indexes = append(plan.Indexes, n.Object)
+			}
+			walk(n.Children)
+		}
+	}
+	walk(plan.Tree)
+	return plan
+}
+
+type planLine struct {
+	level int
+	node  domain.PlanNode
+}
+
+// parseExplainedPlan builds the operation tree from the indentation of the explained plan.
+// Each level is indented by four more spaces and starts with "-> ".
+func parseExplainedPlan(explained string) []domain.PlanNode {
+	var lines []planLine
+	for _, raw := range strings.Split(explained, "\n") {
+		raw = strings.TrimRight(raw, "\r \t")
+		text := strings.TrimLeft(raw, " \t")
+		if text == "" {
+			continue
+		}
+		level := len(raw) - len(text)
+		text = strings.TrimSpace(strings.TrimPrefix(text, "->"))
+		lines = append(lines, planLine{level: level, node: parsePlanNode(text)})
+	}
+	nodes, _ := planChildren(lines, 0, -1)
+	return nodes
+}
+
+// planChildren returns the nodes starting at lines[i] that are indented deeper than parent.
+func planChildren(lines []planLine, i int, parent int) ([]domain.PlanNode, int) {
+	var nodes []domain.PlanNode
+	for i < len(lines) && lines[i].level > parent {
+		level := lines[i].level
+		n := lines[i].node
+		n.Children, i = planChildren(lines, i+1, level)
+		nodes = append(nodes, n)
+	}
+	return nodes, i
+}
+
+func parsePlanNode(text string) domain.PlanNode {
+	n := domain.PlanNode{Operation: text, Kind: text}
+	if m := planObject.FindStringSubmatch(text); m != nil {
+		n.Kind = m[1]
+		n.Object = planName(m[2])
+		var aliases []string
+		for _, a := range planIdent.FindAllString(m[3], -1) {
+			aliases = append(aliases, planName(a))
+		}
+		n.Alias = strings.Join(aliases, " ")
+		n.Access = m[4]
+		return n
+	}
+	if i := strings.Index(text, " ("); i > 0 && strings.HasSuffix(text, ")") {
+		n.Kind = text[:i]
+		n.Details = text[i+2 : len(text)-1]
+	}
+	return n
+}
+
+// planName strips the quotes of a delimited name in an explained plan.
+func planName(name string) string {
+	if strings.HasPrefix(name, "\"") && strings.HasSuffix(name, "\"") && len(name) > 1 {
+		return strings.ReplaceAll(name[1:len(name)-1], "\"\"", "\"")
+	}
+	return name
+}
+
+// planStreamName returns the name a stream has in the legacy plan: its alias or table name.
+func planStreamName(n domain.PlanNode) string {
+	if n.Alias != "" {
+		return n.Alias
+	}
+	return n.Object
+}
+
+// legacyPlanItems renders the streams below nodes in legacy PLAN syntax.
+func legacyPlanItems(nodes []domain.PlanNode) []string {
+	var items []string
+	for _, n := range nodes {
+		switch {
+		case n.Kind == "Table" || n.Kind == "Procedure" || n.Kind == "Function":
+			items = append(items, legacyPlanAccess(n))
+		case n.Kind == "Index" || n.Kind == "Bitmap" || strings.HasPrefix(n.Kind, "Bitmap "):
+			// Index access is rendered with its table.
+		case strings.HasPrefix(n.Kind, "Nested Loop Join"):
+			items = append(items, "JOIN ("+strings.Join(legacyPlanItems(n.Children), ", ")+")")
+		case strings.HasPrefix(n.Kind, "Hash Join"):
+			items = append(items, "HASH ("+strings.Join(legacyPlanItems(n.Children), ", ")+")")
+		case strings.HasPrefix(n.Kind, "Merge Join"):
+			items = append(items, "MERGE ("+strings.Join(legacyPlanItems(n.Children), ", ")+")")
+		case n.Kind == "Sort":
+			if inner := legacyPlanItems(n.Children); len(inner) > 0 {
+				items = append(items, "SORT ("+planStreams(inner)+")")
+			}
+		default:
+			items = append(items, legacyPlanItems(n.Children)...)
+		}
+	}
+	return items
+}
+
+// legacyPlanAccess renders a table access: NATURAL, INDEX (...) for bitmap lookups or
+// ORDER for navigation along an index.
+func legacyPlanAccess(n domain.PlanNode) string {
+	name := planStreamName(n)
+	var bitmap, order []string
+	var walk func(nodes []domain.PlanNode, inBitmap bool)
+	walk = func(nodes []domain.PlanNode, inBitmap bool) {
+		for _, c := range nodes {
+			switch {
+			case c.Kind == "Index" && inBitmap:
+				bitmap = append(bitmap, c.Object)
+			case c.Kind == "Index":
+				order = append(order, c.Object)
+			}
+			walk(c.Children, inBitmap || strings.HasPrefix(c.Kind, "Bitmap"))
+		}
+	}
+	walk(n.Children, false)
+
+	switch {
+	case len(order) > 0 && len(bitmap) > 0:
+		return fmt.Sprintf("%s ORDER %s INDEX (%s)", name, order[0], strings.Join(bitmap, ", "))
+	case len(order) > 0:
+		return fmt.Sprintf("%s ORDER %s", name, order[0])
+	case len(bitmap) > 0:
+		return fmt.Sprintf("%s INDEX (%s)", name, strings.Join(bitmap, ", "))
+	}
+	return name + " NATURAL"
+}
+
+// planStreams renders a stream list; a single join or sort needs no extra parentheses.
+func planStreams(items []string) string {
+	if len(items) == 1 {
+		for _, prefix := range []string{"JOIN (", "HASH (", "MERGE (", "SORT ("} {
+			if strings.HasPrefix(items[0], prefix) {
+				return items[0]
+			}
+		}
+	}
+	return "(" + strings.Join(items, ", ") + ")"
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExplainPlan(t *testing.T) {
+	tests := []struct {
+		name     string
+		explain  string
+		plan     string
+		natural  []string
+		indexes  []string
+		rootKind string
+	}{
+		{
+			name:     "Natural scan",
+			explain:  "Select Expression\n    -> Table \"EMPLOYEE\" Full Scan",
+			plan:     "PLAN (EMPLOYEE NATURAL)",
+			natural:  []string{"EMPLOYEE"},
+			indexes:  []string{},
+			rootKind: "Select Expression",
+		},
+		{
+			name: "Join with index lookup",
+			explain: "Select Expression\n" +
+				"    -> Nested Loop Join (inner)\n" +
+				"        -> Table \"EMPLOYEE\" as \"E\" Full Scan\n" +
+				"        -> Filter\n" +
+				"            -> Table \"DEPARTMENT\" as \"D\" Access By ID\n" +
+				"                -> Bitmap\n" +
+				"                    -> Index \"RDB$PRIMARY5\" Unique Scan",
+			plan:     "PLAN JOIN (E NATURAL, D INDEX (RDB$PRIMARY5))",
+			natural:  []string{"E"},
+			indexes:  []string{"RDB$PRIMARY5"},
+			rootKind: "Select Expression",
+		},
+		{
+			name: "Sort and navigation",
+			explain: "Select Expression\n" +
+				"    -> Sort (record length: 52, key length: 8)\n" +
+				"        -> Table \"COUNTRY\" Full Scan\n" +
+				"Select Expression\n" +
+				"    -> Table \"EMPLOYEE\" Access By ID\n" +
+				"        -> Index \"NAMEX\" Full Scan",
+			plan:     "PLAN SORT ((COUNTRY NATURAL))\nPLAN (EMPLOYEE ORDER NAMEX)",
+			natural:  []string{"COUNTRY"},
+			indexes:  []string{"NAMEX"},
+			rootKind: "Select Expression",
+		},
+		{
+			name:    "No plan",
+			explain: "",
+			natural: []string{},
+			indexes: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := explainPlan(tt.explain)
+			if got.Plan != tt.plan {
+				t.Errorf("Plan = %q, want %q", got.Plan, tt.plan)
+			}
+			if !reflect.DeepEqual(got.NaturalScans, tt.natural) {
+				t.Errorf("NaturalScans = %v, want %v", got.NaturalScans, tt.natural)
+			}
+			if !reflect.DeepEqual(got.Indexes, tt.indexes) {
+				t.Errorf("Indexes = %v, want %v", got.Indexes, tt.indexes)
+			}
+			if tt.rootKind != "" && (len(got.Tree) == 0 || got.Tree[0].Kind != tt.rootKind) {
+				t.Errorf("Tree = %+v, want root %s", got.Tree, tt.rootKind)
+			}
+		})
+	}
+}
+
+func TestParsePlanNode(t *testing.T) {
+	n := parsePlanNode(`Table "EMPLOYEE" as "V" "E" Access By ID`)
+	if n.Kind != "Table" || n.Object != "EMPLOYEE" || n.Alias != "V E" || n.Access != "Access By ID" {
+		t.Errorf("parsePlanNode() = %+v", n)
+	}
+	n = parsePlanNode("Nested Loop Join (outer)")
+	if n.Kind != "Nested Loop Join" || n.Details != "outer" {
+		t.Errorf("parsePlanNode() = %+v", n)
+	}
+}
@@ -1,11 +1,15 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"firebird-web-admin/internal/domain"
 	"fmt"
+	"io"
 	"log"
+	"net/url"
 	"strings"
+	"time"
 
 	_ "github.com/nakagami/firebirdsql"
 )
@@ -20,12 +24,102 @@ type Repository interface {
 	ListProcedures(params domain.ConnectionParams) ([]domain.Table, error)
 	GetProcedureSource(params domain.ConnectionParams, procName string) (string, error)
 	GetProcedureParameters(params domain.ConnectionParams, procName string) ([]domain.ProcedureParameter, error)
-	ExecuteProcedure(params domain.ConnectionParams, procName string, inputParams map[string]interface{}) ([]map[string]interface{}, []domain.Column, error)
-	ExecuteQuery(params domain.ConnectionParams, query string) ([]map[string]interface{}, []domain.Column, error)
+	ExecuteProcedure(params domain.ConnectionParams, procName string, inputParams map[string]interface{}, selectable *bool) ([]map[string]interface{}, []domain.Column, error)
+	ExecuteQuery(params domain.ConnectionParams, query string, withStats bool) ([]map[string]interface{}, []domain.Column, *domain.QueryStats, error)
 	GetAllMetadata(params domain.ConnectionParams) ([]domain.TableMetadata, error)
 	InsertData(params domain.ConnectionParams, tableName string, data map[string]interface{}) error
 	DeleteData(params domain.ConnectionParams, tableName string, dbKey string) error
 	GetTableDDL(params domain.ConnectionParams, tableName string) (string, error)
+	GetSchema(params domain.ConnectionParams) (*domain.Schema, error)
+	CompileProcedure(params domain.ConnectionParams, procName string, source string) error
+	ListTriggers(params domain.ConnectionParams) ([]domain.Trigger, error)
+	GetTrigger(params domain.ConnectionParams, name string) (*domain.Trigger, error)
+	GetTriggerSource(params domain.ConnectionParams, name string) (string, error)
+	CreateTrigger(params domain.ConnectionParams, t domain.Trigger) error
+	AlterTrigger(params domain.ConnectionParams, name string, t domain.Trigger) error
+	SetTriggerActive(params domain.ConnectionParams, name string, active bool) error
+	DropTrigger(params domain.ConnectionParams, name string) error
+	ListSequences(params domain.ConnectionParams) ([]domain.Sequence, error)
+	GetSequence(params domain.ConnectionParams, name string) (*domain.Sequence, error)
+	CreateSequence(params domain.ConnectionParams, s domain.Sequence) error
+	RestartSequence(params domain.ConnectionParams, name string, value *int64) error
+	SetSequenceValue(params domain.ConnectionParams, name string, value int64) error
+	DropSequence(params domain.ConnectionParams, name string) error
+	CompareTableData(source, target domain.ConnectionParams, opts domain.DataCompareOptions, emit func(*domain.DataDiff, domain.RowDifference) error) (*domain.DataDiff, error)
+	ListFunctions(params domain.ConnectionParams) ([]domain.Routine, error)
+	GetFunction(params domain.ConnectionParams, name string) (*domain.Routine, error)
+	GetFunctionSource(params domain.ConnectionParams, name string) (string, error)
+	ExecuteFunction(params domain.ConnectionParams, name string, args map[string]interface{}) ([]map[string]interface{}, []domain.Column, error)
+	ListPackages(params domain.ConnectionParams) ([]domain.Package, error)
+	GetPackage(params domain.ConnectionParams, name string) (*domain.Package, error)
+	GetPackageSource(params domain.ConnectionParams, name string) (string, string, error)
+	CompilePackageHeader(params domain.ConnectionParams, name string, source string) error
+	CompilePackageBody(params domain.ConnectionParams, name string, source string) error
+	ExecutePackageProcedure(params domain.ConnectionParams, pkg string, procName string, inputParams map[string]interface{}, selectable *bool) ([]map[string]interface{}, []domain.Column, error)
+	ExecutePackageFunction(params domain.ConnectionParams, pkg string, name string, args map[string]interface{}) ([]map[string]interface{}, []domain.Column, error)
+	GetView(params domain.ConnectionParams, name string) (*domain.ViewDefinition, error)
+	GetViewSource(params domain.ConnectionParams, name string) (string, error)
+	AlterView(params domain.ConnectionParams, name string, source string) error
+	ListExceptions(params domain.ConnectionParams) ([]domain.DatabaseException, error)
+	GetException(params domain.ConnectionParams, name string) (*domain.DatabaseException, error)
+	GetExceptionDDL(params domain.ConnectionParams, name string) (string, error)
+	CreateException(params domain.ConnectionParams, e domain.DatabaseException) error
+	AlterException(params domain.ConnectionParams, name string, message string) error
+	DropException(params domain.ConnectionParams, name string) error
+	ListDomains(params domain.ConnectionParams) ([]domain.Domain, error)
+	GetDomain(params domain.ConnectionParams, name string) (*domain.Domain, error)
+	GetDomainDDL(params domain.ConnectionParams, name string) (string, error)
+	CreateDomain(params domain.ConnectionParams, d domain.Domain) error
+	AlterDomain(params domain.ConnectionParams, name string, req domain.DomainRequest) error
+	DropDomain(params domain.ConnectionParams, name string) error
+	ListCharacterSets(params domain.ConnectionParams) ([]domain.CharacterSet, error)
+	GetCharacterSet(params domain.ConnectionParams, name string) (*domain.CharacterSet, error)
+	GetCharacterSetDDL(params domain.ConnectionParams, name string) (string, error)
+	SetDefaultCollation(params domain.ConnectionParams, charset string, collation string) error
+	ListCollations(params domain.ConnectionParams) ([]domain.Collation, error)
+	GetCollation(params domain.ConnectionParams, name string) (*domain.Collation, error)
+	GetCollationDDL(params domain.ConnectionParams, name string) (string, error)
+	CreateCollation(params domain.ConnectionParams, c domain.Collation) error
+	DropCollation(params domain.ConnectionParams, name string) error
+	GetDependencies(params domain.ConnectionParams, objectType, name, column, direction string, transitive bool) (*domain.DependencyGraph, error)
+	CheckDDLDependencies(params domain.ConnectionParams, stmt string) ([]domain.DependencyWarning, error)
+	GetTableDesign(params domain.ConnectionParams, name string) (*domain.TableDesign, error)
+	CreateTable(params domain.ConnectionParams, design domain.TableDesign, dryRun bool) (*domain.TableDesignResult, error)
+	AlterTable(params domain.ConnectionParams, name string, design domain.TableDesign, dryRun bool) (*domain.TableDesignResult, error)
+	ListIndexes(params domain.ConnectionParams, table string) ([]domain.Index, error)
+	GetIndex(params domain.ConnectionParams, name string) (*domain.Index, error)
+	CreateIndex(params domain.ConnectionParams, i domain.Index) error
+	DropIndex(params domain.ConnectionParams, name string) error
+	SetIndexActive(params domain.ConnectionParams, name string, active bool) error
+	SetIndexStatistics(params domain.ConnectionParams, name string) (*domain.Index, error)
+	GetIndexReport(params domain.ConnectionParams, maxSelectivity float64) ([]domain.IndexIssue, error)
+	ExplainQuery(params domain.ConnectionParams, query string) (*domain.QueryPlan, error)
+	GetMonitorOverview(params domain.ConnectionParams) (*domain.MonitorOverview, error)
+	ListAttachments(params domain.ConnectionParams) ([]domain.Attachment, error)
+	ListMonitoredTransactions(params domain.ConnectionParams) ([]domain.MonitoredTransaction, error)
+	ListMonitoredStatements(params domain.ConnectionParams, activeOnly bool) ([]domain.MonitoredStatement, error)
+	KillAttachment(params domain.ConnectionParams, id int64) error
+	CancelStatement(params domain.ConnectionParams, id int64) error
+	GetDatabaseHealth(params domain.ConnectionParams, thresholds domain.HealthThresholds) (*domain.DatabaseHealth, error)
+	Backup(params domain.ConnectionParams, req domain.BackupRequest, w io.Writer) error
+	Restore(params domain.ConnectionParams, req domain.RestoreRequest, w io.Writer) error
+	ValidateDatabase(params domain.ConnectionParams, req domain.ValidationRequest) (*domain.ValidationReport, error)
+	SweepDatabase(params domain.ConnectionParams) (*domain.ServiceResult, error)
+	ShutdownDatabase(params domain.ConnectionParams, req domain.ShutdownRequest) (*domain.ServiceResult, error)
+	BringOnline(params domain.ConnectionParams, state string) (*domain.ServiceResult, error)
+	SetDatabaseProperties(params domain.ConnectionParams, props domain.DatabaseProperties) (*domain.ServiceResult, error)
+	ListUsers(params domain.ConnectionParams) ([]domain.User, error)
+	CreateUser(params domain.ConnectionParams, req domain.UserRequest) error
+	AlterUser(params domain.ConnectionParams, name string, req domain.UserRequest) error
+	DropUser(params domain.ConnectionParams, name string, plugin string) error
+	ListRoles(params domain.ConnectionParams) ([]domain.Role, error)
+	CreateRole(params domain.ConnectionParams, name string) error
+	DropRole(params domain.ConnectionParams, name string) error
+	ListGrants(params domain.ConnectionParams, object string, grantee string) ([]domain.Grant, error)
+	GrantPrivileges(params domain.ConnectionParams, grants []domain.Grant) error
+	RevokePrivileges(params domain.ConnectionParams, grants []domain.Grant) error
+	GetPrivilegeMatrix(params domain.ConnectionParams, objectType string, name string) (*domain.PrivilegeMatrix, error)
+	ApplyPrivilegeMatrix(params domain.ConnectionParams, objectType string, name string, desired domain.PrivilegeMatrix, dryRun bool) (*domain.PrivilegeChanges, error)
 }
 
 type FirebirdRepository struct{}
@@ -34,36 +128,49 @@ func NewFirebirdRepository() *FirebirdRepository {
 	return &FirebirdRepository{}
 }
 
-func (r *FirebirdRepository) getConnectionString(params domain.ConnectionParams) string {
-	db := params.Database
-	if colonIdx := strings.Index(db, ":"); colonIdx != -1 {
-		if slashIdx := strings.LastIndex(db[:colonIdx], "/"); slashIdx != -1 {
-			host := db[:slashIdx]
-			port := db[slashIdx+1 : colonIdx]
-			path := db[colonIdx+1:]
-			db = fmt.Sprintf("%s:%s/%s", host, port, path)
-		} else {
-			host := db[:colonIdx]
-			path := db[colonIdx+1:]
-			db = fmt.Sprintf("%s/%s", host, path)
-		}
+// getConnectionString builds the driver DSN after checking the database and the connection
+// options, so every connection is validated and not only the one made by TestConnection.
+func (r *FirebirdRepository) getConnectionString(params domain.ConnectionParams) (string, error) {
+	db, err := dsnDatabase(params.Database)
+	if err != nil {
+		return "", invalidf("%v", err)
+	}
+	if err := validateConnectionOptions(params); err != nil {
+		return "", err
 	}
-	return fmt.Sprintf("%s:%s@%s", params.User, params.Password, db)
+	// The driver parses the DSN as a URL, so reserved characters in the credentials are escaped.
+	connStr := url.UserPassword(params.User, params.Password).String() + "@" + db
+	if opts := connectionOptions(params); len(opts) > 0 {
+		connStr += "?" + opts.Encode()
+	}
+	return connStr, nil
 }
 
 func (r *FirebirdRepository) TestConnection(params domain.ConnectionParams) error {
-	connStr := r.getConnectionString(params)
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return err
+	}
 	db, err := sql.Open("firebirdsql", connStr)
 	if err != nil {
 		log.Printf("Error opening connection: %v", err)
 		return err
 	}
 	defer db.Close()
-	return db.Ping()
+	if err := db.Ping(); err != nil {
+		return err
+	}
+	if params.WireCrypt == "required" {
+		return checkWireEncryption(db)
+	}
+	return nil
 }
 
 func (r *FirebirdRepository) ListTables(params domain.ConnectionParams) ([]domain.Table, error) {
-	connStr := r.getConnectionString(params)
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
 	db, err := sql.Open("firebirdsql", connStr)
 	if err != nil {
 		return nil, err
@@ -96,7 +203,10 @@ func (r *FirebirdRepository) ListTables(params domain.ConnectionParams) ([]domai
 }
 
 func (r *FirebirdRepository) GetData(params domain.ConnectionParams, tableName string, limit, offset int, sortField string, sortOrder string) ([]map[string]interface{}, []domain.Column, error) {
-	connStr := r.getConnectionString(params)
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, nil, err
+	}
 	db, err := sql.Open("firebirdsql", connStr)
 	if err != nil {
 		return nil, nil, err
@@ -133,8 +243,17 @@ func (r *FirebirdRepository) GetData(params domain.ConnectionParams, tableName s
 
 	rows, err := db.Query(query)
 	if err != nil {
-		log.Printf("GetData DB Error: %v", err)
-		return nil, nil, err
+		// Views without a DB_KEY (e.g. aggregating views) are read without it and cannot be edited.
+		if isView, viewErr := relationIsView(db, tableName); viewErr != nil || !isView {
+			log.Printf("GetData DB Error: %v", err)
+			return nil, nil, err
+		}
+		query = fmt.Sprintf("SELECT FIRST %d SKIP %d t.* FROM \"%s\" t %s", limit, offset, tableName, orderByClause)
+		log.Printf("GetData Query: %s", query)
+		if rows, err = db.Query(query); err != nil {
+			log.Printf("GetData DB Error: %v", err)
+			return nil, nil, err
+		}
 	}
 	defer rows.Close()
 
@@ -238,7 +357,10 @@ func (r *FirebirdRepository) scanRows(rows *sql.Rows, relationName string, db *s
 
 
 func (r *FirebirdRepository) GetTotalCount(params domain.ConnectionParams, tableName string) (int, error) {
-	connStr := r.getConnectionString(params)
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return 0, err
+	}
 	db, err := sql.Open("firebirdsql", connStr)
 	if err != nil {
 		return 0, err
@@ -256,7 +378,10 @@ func (r *FirebirdRepository) GetTotalCount(params domain.ConnectionParams, table
 }
 
 func (r *FirebirdRepository) UpdateData(params domain.ConnectionParams, tableName string, dbKey string, data map[string]interface{}) error {
-	connStr := r.getConnectionString(params)
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return err
+	}
 	db, err := sql.Open("firebirdsql", connStr)
 	if err != nil {
 		return err
@@ -280,10 +405,9 @@ func (r *FirebirdRepository) UpdateData(params domain.ConnectionParams, tableNam
 	}
 
 	// Convert hex string dbKey back to bytes
-	var keyBytes []byte
-	_, err = fmt.Sscanf(dbKey, "%x", &keyBytes)
+	keyBytes, err := parseDBKey(dbKey)
 	if err != nil {
-		return fmt.Errorf("invalid db_key format")
+		return err
 	}
 	args = append(args, keyBytes)
 
@@ -298,7 +422,10 @@ func (r *FirebirdRepository) UpdateData(params domain.ConnectionParams, tableNam
 }
 
 func (r *FirebirdRepository) InsertData(params domain.ConnectionParams, tableName string, data map[string]interface{}) error {
-	connStr := r.getConnectionString(params)
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return err
+	}
 	db, err := sql.Open("firebirdsql", connStr)
 	if err != nil {
 		return err
@@ -330,7 +457,10 @@ func (r *FirebirdRepository) InsertData(params domain.ConnectionParams, tableNam
 }
 
 func (r *FirebirdRepository) DeleteData(params domain.ConnectionParams, tableName string, dbKey string) error {
-	connStr := r.getConnectionString(params)
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return err
+	}
 	db, err := sql.Open("firebirdsql", connStr)
 	if err != nil {
 		return err
@@ -338,10 +468,9 @@ func (r *FirebirdRepository) DeleteData(params domain.ConnectionParams, tableNam
 	defer db.Close()
 
 	// Convert hex string dbKey back to bytes
-	var keyBytes []byte
-	_, err = fmt.Sscanf(dbKey, "%x", &keyBytes)
+	keyBytes, err := parseDBKey(dbKey)
 	if err != nil {
-		return fmt.Errorf("invalid db_key format")
+		return err
 	}
 
 	query := fmt.Sprintf("DELETE FROM \"%s\" WHERE RDB$DB_KEY = ?", tableName)
@@ -355,13 +484,25 @@ func (r *FirebirdRepository) DeleteData(params domain.ConnectionParams, tableNam
 }
 
 func (r *FirebirdRepository) GetTableDDL(params domain.ConnectionParams, tableName string) (string, error) {
-	connStr := r.getConnectionString(params)
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return "", err
+	}
 	db, err := sql.Open("firebirdsql", connStr)
 	if err != nil {
 		return "", err
 	}
 	defer db.Close()
 
+	// Views are not tables; return their CREATE OR ALTER VIEW statement instead.
+	if isView, err := relationIsView(db, strings.ToUpper(tableName)); err == nil && isView {
+		v, err := loadView(db, strings.ToUpper(tableName))
+		if err != nil {
+			return "", err
+		}
+		return viewSourceSQL(*v), nil
+	}
+
 	// Fetch columns and basic types
 	// This is a simplified DDL generator
 	query := `
@@ -483,7 +624,10 @@ func (r *FirebirdRepository) GetTableDDL(params domain.ConnectionParams, tableNa
 }
 
 func (r *FirebirdRepository) ListViews(params domain.ConnectionParams) ([]domain.Table, error) {
-	connStr := r.getConnectionString(params)
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
 	db, err := sql.Open("firebirdsql", connStr)
 	if err != nil {
 		return nil, err
@@ -516,7 +660,10 @@ func (r *FirebirdRepository) ListViews(params domain.ConnectionParams) ([]domain
 }
 
 func (r *FirebirdRepository) ListProcedures(params domain.ConnectionParams) ([]domain.Table, error) {
-	connStr := r.getConnectionString(params)
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
 	db, err := sql.Open("firebirdsql", connStr)
 	if err != nil {
 		return nil, err
@@ -527,6 +674,7 @@ func (r *FirebirdRepository) ListProcedures(params domain.ConnectionParams) ([]d
 		SELECT RDB$PROCEDURE_NAME
 		FROM RDB$PROCEDURES
 		WHERE (RDB$SYSTEM_FLAG IS NULL OR RDB$SYSTEM_FLAG = 0)
+		AND RDB$PACKAGE_NAME IS NULL
 		ORDER BY RDB$PROCEDURE_NAME
 	`
 	rows, err := db.Query(query)
@@ -547,130 +695,92 @@ func (r *FirebirdRepository) ListProcedures(params domain.ConnectionParams) ([]d
 	return tables, nil
 }
 
+// GetProcedureSource reconstructs the complete CREATE OR ALTER PROCEDURE statement,
+// including parameters, RETURNS and SQL SECURITY.
 func (r *FirebirdRepository) GetProcedureSource(params domain.ConnectionParams, procName string) (string, error) {
-	connStr := r.getConnectionString(params)
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return "", err
+	}
 	db, err := sql.Open("firebirdsql", connStr)
 	if err != nil {
 		return "", err
 	}
 	defer db.Close()
 
-	query := `
-		SELECT RDB$PROCEDURE_SOURCE
-		FROM RDB$PROCEDURES
-		WHERE RDB$PROCEDURE_NAME = ?
-	`
-	var source sql.NullString
-	if err := db.QueryRow(query, strings.ToUpper(procName)).Scan(&source); err != nil {
+	proc, err := loadProcedure(db, "", procName)
+	if err != nil {
 		log.Printf("GetProcedureSource error: %v", err)
 		return "", err
 	}
-
-	if source.Valid {
-		return source.String, nil
-	}
-	return "", nil
+	return procedureSQL(*proc, false), nil
 }
 
+// GetProcedureParameters returns the input parameters followed by the output parameters, with their types.
 func (r *FirebirdRepository) GetProcedureParameters(params domain.ConnectionParams, procName string) ([]domain.ProcedureParameter, error) {
-	connStr := r.getConnectionString(params)
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
 	db, err := sql.Open("firebirdsql", connStr)
 	if err != nil {
 		return nil, err
 	}
 	defer db.Close()
 
-	// RDB$PARAMETER_TYPE: 0 = Input, 1 = Output
-	query := `
-		SELECT RDB$PARAMETER_NAME
-		FROM RDB$PROCEDURE_PARAMETERS
-		WHERE RDB$PROCEDURE_NAME = ?
-		AND RDB$PARAMETER_TYPE = 0
-		ORDER BY RDB$PARAMETER_NUMBER
-	`
-	rows, err := db.Query(query, strings.ToUpper(procName))
+	proc, err := loadProcedure(db, "", procName)
 	if err != nil {
 		log.Printf("GetProcedureParameters error: %v", err)
 		return nil, err
 	}
-	defer rows.Close()
+	return append(proc.Inputs, proc.Outputs...), nil
+}
 
-	var paramsList []domain.ProcedureParameter
-	for rows.Next() {
-		var name string
-		if err := rows.Scan(&name); err != nil {
-			return nil, err
-		}
-		paramsList = append(paramsList, domain.ProcedureParameter{
-			Name: strings.TrimSpace(name),
-			Type: "STRING", // Defaulting to string for input UI, can be enhanced by joining RDB$FIELDS
-		})
-	}
-	return paramsList, nil
+// ExecuteProcedure runs a procedure with SELECT for selectable procedures and EXECUTE PROCEDURE otherwise.
+// The mode follows RDB$PROCEDURE_TYPE unless selectable is set by the caller.
+// Output parameters of executable procedures are returned as a single row.
+func (r *FirebirdRepository) ExecuteProcedure(params domain.ConnectionParams, procName string, inputParams map[string]interface{}, selectable *bool) ([]map[string]interface{}, []domain.Column, error) {
+	return r.executeProcedure(params, "", procName, inputParams, selectable)
 }
 
-func (r *FirebirdRepository) ExecuteProcedure(params domain.ConnectionParams, procName string, inputParams map[string]interface{}) ([]map[string]interface{}, []domain.Column, error) {
-	connStr := r.getConnectionString(params)
+// executeProcedure executes a standalone procedure, or a packaged one when pkg is set.
+func (r *FirebirdRepository) executeProcedure(params domain.ConnectionParams, pkg string, procName string, inputParams map[string]interface{}, selectable *bool) ([]map[string]interface{}, []domain.Column, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, nil, err
+	}
 	db, err := sql.Open("firebirdsql", connStr)
 	if err != nil {
 		return nil, nil, err
 	}
 	defer db.Close()
 
-	// 1. Determine execution mode by checking source for "SUSPEND"
-	source, err := r.GetProcedureSource(params, procName)
+	proc, err := loadProcedure(db, pkg, procName)
 	if err != nil {
-		// If fails, default to selectable or try to execute anyway
-		log.Printf("ExecuteProcedure: could not get source, proceeding cautiously. Error: %v", err)
-	}
-
-	isSelectable := false
-	if strings.Contains(strings.ToUpper(source), "SUSPEND") {
-		isSelectable = true
+		log.Printf("ExecuteProcedure error: %v", err)
+		return nil, nil, err
 	}
 
-	// 2. Fetch parameter order to construct query correctly
-	// We need ALL input parameters in order
-	// Actually, we need to bind them in order.
-	paramOrderQuery := `
-		SELECT RDB$PARAMETER_NAME
-		FROM RDB$PROCEDURE_PARAMETERS
-		WHERE RDB$PROCEDURE_NAME = ?
-		AND RDB$PARAMETER_TYPE = 0
-		ORDER BY RDB$PARAMETER_NUMBER
-	`
-	pRows, err := db.Query(paramOrderQuery, strings.ToUpper(procName))
-	if err != nil {
-		return nil, nil, err
+	// 1. Determine execution mode: RDB$PROCEDURE_TYPE 1 = selectable, 2 = executable, or SUSPEND in legacy source
+	isSelectable := proc.Selectable
+	if selectable != nil {
+		isSelectable = *selectable
 	}
-	defer pRows.Close()
 
+	// 2. Bind input parameters in declaration order, converted to their declared types
 	var orderedParams []interface{}
 	var paramPlaceholders []string
 
-	for pRows.Next() {
-		var pName string
-		if err := pRows.Scan(&pName); err != nil {
-			return nil, nil, err
-		}
-		pName = strings.TrimSpace(pName)
-		val, ok := inputParams[pName]
-		if !ok {
-			// Handle missing param? pass null or error?
-			// For now pass nil
-			orderedParams = append(orderedParams, nil)
-		} else {
-			orderedParams = append(orderedParams, val)
+	for _, p := range proc.Inputs {
+		val, err := convertParameterValue(inputParams[p.Name], p.DataType)
+		if err != nil {
+			return nil, nil, invalidf("parameter %s: %v", p.Name, err)
 		}
+		orderedParams = append(orderedParams, val)
 		paramPlaceholders = append(paramPlaceholders, "?")
 	}
 
-	var query string
-	if isSelectable {
-		query = fmt.Sprintf("SELECT * FROM \"%s\"(%s)", strings.ToUpper(procName), strings.Join(paramPlaceholders, ", "))
-	} else {
-		query = fmt.Sprintf("EXECUTE PROCEDURE \"%s\"(%s)", strings.ToUpper(procName), strings.Join(paramPlaceholders, ", "))
-	}
+	query := procedureCallSQL(*proc, isSelectable, paramPlaceholders)
 
 	log.Printf("ExecuteProcedure Query: %s, Args: %v", query, orderedParams)
 
@@ -681,63 +791,109 @@ func (r *FirebirdRepository) ExecuteProcedure(params domain.ConnectionParams, pr
 	}
 	defer rows.Close()
 
-	return r.scanRows(rows, "", db)
+	data, cols, err := r.scanRows(rows, "", db)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Report the declared output types instead of the wire types.
+	outputTypes := make(map[string]string, len(proc.Outputs))
+	for _, p := range proc.Outputs {
+		outputTypes[p.Name] = p.DataType
+	}
+	for i := range cols {
+		if t, ok := outputTypes[cols[i].Name]; ok {
+			cols[i].Type = t
+		}
+	}
+	if data == nil {
+		data = []map[string]interface{}{}
+	}
+	return data, cols, nil
 }
 
-func (r *FirebirdRepository) ExecuteQuery(params domain.ConnectionParams, query string) ([]map[string]interface{}, []domain.Column, error) {
-	connStr := r.getConnectionString(params)
+// ExecuteQuery runs a statement typed by the user and reports its prepare, execute and
+// fetch times and, with withStats, the I/O and record counters it caused. The statement runs on
+// a dedicated connection so that the attachment's monitoring counters only cover it.
+// Statistics are best effort: when they cannot be read, the result is returned without them.
+func (r *FirebirdRepository) ExecuteQuery(params domain.ConnectionParams, query string, withStats bool) ([]map[string]interface{}, []domain.Column, *domain.QueryStats, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 	db, err := sql.Open("firebirdsql", connStr)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	defer db.Close()
 
 	log.Printf("ExecuteQuery: %s", query)
 
-	// Naive check for SELECT vs others to decide Query or Exec
-	// However, Exec doesn't return rows.
-	// Firebird driver `firebirdsql` supports `Query` for SELECT and `Exec` for others.
-	// But `Query` on an INSERT/UPDATE might fail or return no rows depending on driver implementation.
-	// `firebirdsql` driver seems to return nil rows for non-select queries if used with Query, or maybe error.
-	// Safer approach: Check if it starts with SELECT.
-	// But it could be "WITH ... SELECT" or "EXECUTE BLOCK ... RETURNS".
-
-	// Try Query first. If it returns rows, great.
-	// If it's an Update/Insert, Query might succeed but return no rows, or fail.
-
-	rows, err := db.Query(query)
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
 	if err != nil {
-		// If error is not fatal, maybe it was a command that needs Exec?
-		// Actually, standard SQL drivers usually return error if you call Query on something that doesn't return rows?
-		// Or they return a Result object?
-		// Let's try Exec if Query fails, or just rely on Query behavior.
-		// For simplicity, let's assume Query works for SELECT/RETURNING, and if not, we try Exec.
+		return nil, nil, nil, err
+	}
+	defer conn.Close()
 
-		// Actually, let's just return the error. The user is writing SQL, they should know.
-		return nil, nil, err
+	var before *executionSnapshot
+	if withStats {
+		if before, err = readExecutionSnapshot(ctx, conn); err != nil {
+			log.Printf("ExecuteQuery stats error: %v", err)
+		}
 	}
-	defer rows.Close()
 
-	// If we have rows, scan them
-	cols, err := rows.Columns()
+	start := time.Now()
+	stmt, err := conn.PrepareContext(ctx, query)
 	if err != nil {
-		// This likely means it was a query without result set (INSERT/UPDATE without RETURNING)
-		// But db.Query succeeded.
-		// We can return an empty result with a message?
-		// Or maybe we should have used Exec.
-		// Let's return empty data/cols and nil error.
-		return []map[string]interface{}{}, []domain.Column{}, nil
+		return nil, nil, nil, err
 	}
+	defer stmt.Close()
+	prepareMs := milliseconds(start)
 
-	if len(cols) == 0 {
-		return []map[string]interface{}{}, []domain.Column{}, nil
+	// Query is used for every statement: it returns the rows of SELECT, EXECUTE BLOCK and
+	// RETURNING statements and an empty result for the others.
+	start = time.Now()
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		// The user is writing SQL, they should know; return the error as is.
+		return nil, nil, nil, err
 	}
-
-	return r.scanRows(rows, "", db)
+	executeMs := milliseconds(start)
+
+	start = time.Now()
+	data, cols := []map[string]interface{}{}, []domain.Column{}
+	// Columns fails or is empty for statements without a result set (INSERT/UPDATE without RETURNING).
+	if names, err := rows.Columns(); err == nil && len(names) > 0 {
+		if data, cols, err = r.scanRows(rows, "", db); err != nil {
+			rows.Close()
+			return nil, nil, nil, err
+		}
+	}
+	rows.Close()
+	fetchMs := milliseconds(start)
+
+	var stats *domain.QueryStats
+	if before != nil {
+		after, err := readExecutionSnapshot(ctx, conn)
+		if err != nil {
+			log.Printf("ExecuteQuery stats error: %v", err)
+		} else {
+			stats = executionStats(before, after)
+		}
+	}
+	if stats == nil {
+		stats = &domain.QueryStats{}
+	}
+	stats.PrepareMs, stats.ExecuteMs, stats.FetchMs = prepareMs, executeMs, fetchMs
+	stats.RowsFetched = len(data)
+	return data, cols, stats, nil
 }
 
 func (r *FirebirdRepository) GetAllMetadata(params domain.ConnectionParams) ([]domain.TableMetadata, error) {
-	connStr := r.getConnectionString(params)
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
 	db, err := sql.Open("firebirdsql", connStr)
 	if err != nil {
 		return nil, err
@@ -802,7 +958,9 @@ func (r *FirebirdRepository) GetAllMetadata(params domain.ConnectionParams) ([]d
 			pp.RDB$PARAMETER_NAME
 		FROM RDB$PROCEDURES p
 		LEFT JOIN RDB$PROCEDURE_PARAMETERS pp ON p.RDB$PROCEDURE_NAME = pp.RDB$PROCEDURE_NAME AND pp.RDB$PARAMETER_TYPE = 0
+			AND pp.RDB$PACKAGE_NAME IS NULL
 		WHERE (p.RDB$SYSTEM_FLAG IS NULL OR p.RDB$SYSTEM_FLAG = 0)
+		AND p.RDB$PACKAGE_NAME IS NULL
 		ORDER BY p.RDB$PROCEDURE_NAME
 	`
 	pRows, err := db.Query(procQuery)
@@ -836,6 +994,49 @@ func (r *FirebirdRepository) GetAllMetadata(params domain.ConnectionParams) ([]d
 		}
 	}
 
+	// Standalone functions, with their arguments except the return argument.
+	funcQuery := `
+		SELECT
+			f.RDB$FUNCTION_NAME,
+			fa.RDB$ARGUMENT_NAME
+		FROM RDB$FUNCTIONS f
+		LEFT JOIN RDB$FUNCTION_ARGUMENTS fa ON f.RDB$FUNCTION_NAME = fa.RDB$FUNCTION_NAME
+			AND fa.RDB$PACKAGE_NAME IS NULL
+			AND fa.RDB$ARGUMENT_POSITION <> COALESCE(f.RDB$RETURN_ARGUMENT, 0)
+		WHERE (f.RDB$SYSTEM_FLAG IS NULL OR f.RDB$SYSTEM_FLAG = 0)
+		AND f.RDB$PACKAGE_NAME IS NULL
+		ORDER BY f.RDB$FUNCTION_NAME, fa.RDB$ARGUMENT_POSITION
+	`
+	fRows, err := db.Query(funcQuery)
+	if err != nil {
+		log.Printf("Metadata Functions Error: %v", err)
+	} else {
+		defer fRows.Close()
+		for fRows.Next() {
+			var funcName string
+			var argNameNull sql.NullString
+
+			if err := fRows.Scan(&funcName, &argNameNull); err != nil {
+				continue
+			}
+			funcName = strings.TrimSpace(funcName)
+
+			if _, exists := metadataMap[funcName]; !exists {
+				metadataMap[funcName] = &domain.TableMetadata{
+					Name: funcName,
+					Type: "FUNCTION",
+					Columns: []string{},
+				}
+				orderedNames = append(orderedNames, funcName)
+			}
+
+			// Legacy UDF arguments have no names.
+			if argNameNull.Valid && strings.TrimSpace(argNameNull.String) != "" {
+				metadataMap[funcName].Columns = append(metadataMap[funcName].Columns, strings.TrimSpace(argNameNull.String))
+			}
+		}
+	}
+
 	var result []domain.TableMetadata
 	for _, name := range orderedNames {
 		result = append(result, *metadataMap[name])
@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"errors"
 	"firebird-web-admin/internal/domain"
+	"strings"
 	"testing"
 )
 
@@ -47,16 +49,203 @@ func TestGetConnectionString(t *testing.T) {
 				Password: "password",
 				Database: "alias",
 			},
-			expected: "sysdba:password@alias",
+			expected: "sysdba:password@localhost/alias",
+		},
+		{
+			name: "Charset and role",
+			params: domain.ConnectionParams{
+				User:     "sysdba",
+				Password: "password",
+				Database: "localhost:legacy",
+				Charset:  "win1251",
+				Role:     "READ ONLY",
+			},
+			expected: "sysdba:password@localhost/legacy?charset=WIN1251&role=READ+ONLY",
+		},
+		{
+			name: "Legacy auth without wire crypt",
+			params: domain.ConnectionParams{
+				User:       "sysdba",
+				Password:   "password",
+				Database:   "10.0.0.5:employee",
+				AuthPlugin: "Legacy",
+				WireCrypt:  "disabled",
+			},
+			expected: "sysdba:password@10.0.0.5/employee?auth_plugin_name=Legacy_Auth&wire_crypt=false",
+		},
+		{
+			name: "Required wire crypt uses the driver default",
+			params: domain.ConnectionParams{
+				User:       "sysdba",
+				Password:   "password",
+				Database:   "alias",
+				AuthPlugin: "Srp",
+				WireCrypt:  "required",
+			},
+			expected: "sysdba:password@localhost/alias?auth_plugin_name=Srp",
+		},
+		{
+			name: "Time zone and lower-case column names",
+			params: domain.ConnectionParams{
+				User:              "sysdba",
+				Password:          "password",
+				Database:          "alias",
+				Timezone:          "Europe/Berlin",
+				ColumnNameToLower: true,
+			},
+			expected: "sysdba:password@localhost/alias?column_name_to_lower=true&timezone=Europe%2FBerlin",
+		},
+		{
+			name: "Reserved characters in the password",
+			params: domain.ConnectionParams{
+				User:     "sysdba",
+				Password: "p@ss/w?rd#1:%",
+				Database: "server:employee",
+				Charset:  "UTF8",
+			},
+			expected: "sysdba:p%40ss%2Fw%3Frd%231%3A%25@server/employee?charset=UTF8",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := repo.getConnectionString(tt.params)
+			got, err := repo.getConnectionString(tt.params)
+			if err != nil {
+				t.Fatalf("getConnectionString() error = %v", err)
+			}
 			if got != tt.expected {
 				t.Errorf("getConnectionString() = %v, want %v", got, tt.expected)
 			}
 		})
 	}
+
+	databases := []struct {
+		name     string
+		database string
+		expected string
+	}{
+		{name: "Local POSIX path", database: "/var/lib/firebird/data/employee.fdb", expected: "localhost/var/lib/firebird/data/employee.fdb"},
+		{name: "Local POSIX path in the root", database: "/employee.fdb", expected: "localhost//employee.fdb"},
+		{name: "Local Windows path", database: `C:\data\app.fdb`, expected: `localhost/C:\data\app.fdb`},
+		{name: "Local Windows path with slashes", database: "d:/data/app.fdb", expected: "localhost/d:/data/app.fdb"},
+		{name: "Host and POSIX path", database: "server:/data/app.fdb", expected: "server/data/app.fdb"},
+		{name: "Host, port and Windows path", database: `server/3051:C:\data\app.fdb`, expected: `server:3051/C:\data\app.fdb`},
+		{name: "gds_db service name", database: "server/gds_db:employee", expected: "server:3050/employee"},
+		{name: "Other service name", database: "server/http:employee", expected: "server:80/employee"},
+		{name: "IPv6 host", database: "[::1]:employee", expected: "[::1]:3050/employee"},
+		{name: "IPv6 host and port", database: "[2001:db8::5]/3051:/data/app.fdb", expected: "[2001:db8::5]:3051/data/app.fdb"},
+		{name: "inet URL with alias", database: "inet://server/employee", expected: "server/employee"},
+		{name: "inet URL with port and POSIX path", database: "inet://server:3051//data/app.fdb", expected: "server:3051/data/app.fdb"},
+		{name: "inet URL with Windows path", database: "INET://server/C:/data/app.fdb", expected: "server/C:/data/app.fdb"},
+		{name: "inet URL without host", database: "inet:///employee", expected: "localhost/employee"},
+		{name: "inet4 URL", database: "inet4://10.0.0.5/employee", expected: "10.0.0.5/employee"},
+		{name: "inet6 URL", database: "inet6://[fe80::1]:3051/employee", expected: "[fe80::1]:3051/employee"},
+		{name: "Surrounding spaces", database: " server:employee ", expected: "server/employee"},
+		{name: "URL characters in path", database: "server:/data/50%#1?.fdb", expected: "server/data/50%25%231%3F.fdb"},
+	}
+	for _, tt := range databases {
+		t.Run(tt.name, func(t *testing.T) {
+			params := domain.ConnectionParams{User: "sysdba", Password: "password", Database: tt.database}
+			got, err := repo.getConnectionString(params)
+			if err != nil {
+				t.Fatalf("getConnectionString() error = %v", err)
+			}
+			if got != "sysdba:password@"+tt.expected {
+				t.Errorf("getConnectionString() = %v, want %v", got, "sysdba:password@"+tt.expected)
+			}
+		})
+	}
+
+	invalid := []domain.ConnectionParams{
+		{User: "sysdba", Password: "password", Database: "xnet://employee"},
+		{User: "sysdba", Password: "password", Database: "server/no_such_service:employee"},
+		{User: "sysdba", Password: "password", Database: "server:employee", WireCrypt: "sometimes"},
+	}
+	for _, params := range invalid {
+		var invalidErr *domain.ValidationError
+		if _, err := repo.getConnectionString(params); !errors.As(err, &invalidErr) {
+			t.Errorf("getConnectionString(%+v) error = %v, want a validation error", params, err)
+		}
+	}
+}
+
+func TestValidateConnectionOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  domain.ConnectionParams
+		wantErr bool
+	}{
+		{name: "Defaults", params: domain.ConnectionParams{}},
+		{name: "All options", params: domain.ConnectionParams{Charset: "WIN1251", Role: "R", AuthPlugin: "srp256", WireCrypt: "required", Timezone: "+03:00", ColumnNameToLower: true}},
+		{name: "Invalid charset", params: domain.ConnectionParams{Charset: "UTF8&role=X"}, wantErr: true},
+		{name: "Unknown auth plugin", params: domain.ConnectionParams{AuthPlugin: "Win_Sspi"}, wantErr: true},
+		{name: "Unknown wire crypt mode", params: domain.ConnectionParams{WireCrypt: "always"}, wantErr: true},
+		{name: "Required wire crypt with legacy auth", params: domain.ConnectionParams{AuthPlugin: "Legacy_Auth", WireCrypt: "required"}, wantErr: true},
+		{name: "Wire compression", params: domain.ConnectionParams{WireCompression: true}, wantErr: true},
+		{name: "Invalid time zone", params: domain.ConnectionParams{Timezone: "Europe Berlin"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateConnectionOptions(tt.params); (err != nil) != tt.wantErr {
+				t.Errorf("validateConnectionOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseDatabase(t *testing.T) {
+	tests := []struct {
+		name     string
+		database string
+		want     databaseLocation
+		wantErr  string
+	}{
+		{name: "Alias", database: "employee", want: databaseLocation{Alias: "employee"}},
+		{name: "Windows path", database: `C:\db.fdb`, want: databaseLocation{Path: `C:\db.fdb`}},
+		{name: "Host and alias", database: "db.example.com:employee", want: databaseLocation{Host: "db.example.com", Alias: "employee"}},
+		{name: "Host, service and path", database: "fb_5/gds_db:/db.fdb", want: databaseLocation{Host: "fb_5", Port: "gds_db", Path: "/db.fdb"}},
+		{name: "Single-letter host", database: "c:employee", want: databaseLocation{Host: "c", Alias: "employee"}},
+		{name: "IPv6 host and port", database: "[::1]/3051:C:/db.fdb", want: databaseLocation{Host: "::1", Port: "3051", Path: "C:/db.fdb"}},
+		{name: "inet6 URL", database: "inet6://[::1]:3051/employee", want: databaseLocation{Protocol: "inet6", Host: "::1", Port: "3051", Alias: "employee"}},
+		{name: "Empty", database: "  ", wantErr: "database is required"},
+		{name: "Missing path", database: "server:", wantErr: "missing database path or alias"},
+		{name: "Missing host", database: ":employee", wantErr: "missing host"},
+		{name: "Missing port", database: "server/:employee", wantErr: "missing port"},
+		{name: "Port out of range", database: "server/70000:employee", wantErr: "out of range"},
+		{name: "Port zero", database: "inet://server:0/employee", wantErr: "out of range"},
+		{name: "Invalid service name", database: "server/3050x:employee", wantErr: "invalid port or service name"},
+		{name: "Invalid host", database: "my server:employee", wantErr: "invalid host"},
+		{name: "IPv6 without brackets", database: "::1:employee", wantErr: "enclosed in brackets"},
+		{name: "IPv6 without brackets in URL", database: "inet://::1/employee", wantErr: "enclosed in brackets"},
+		{name: "Unclosed bracket", database: "[::1:employee", wantErr: "missing ]"},
+		{name: "Bracketed IPv4", database: "[10.0.0.5]:employee", wantErr: "invalid IPv6 address"},
+		{name: "Text after bracket", database: "[::1]x:employee", wantErr: "after IPv6 address"},
+		{name: "IPv6 without database", database: "[::1]", wantErr: "missing database path or alias"},
+		{name: "URL without database", database: "inet://server", wantErr: "missing database path or alias"},
+		{name: "URL with empty port", database: "inet://server:/employee", wantErr: "missing port"},
+		{name: "inet4 with IPv6 address", database: "inet4://[::1]/employee", wantErr: "needs an IPv4 address"},
+		{name: "inet6 with IPv4 address", database: "inet6://10.0.0.5/employee", wantErr: "needs an IPv6 address"},
+		{name: "XNET", database: "xnet://employee", wantErr: "not supported"},
+		{name: "WNET", database: `\\server\employee`, wantErr: "named pipe"},
+		{name: "Unknown protocol", database: "http://server/employee", wantErr: "unknown protocol"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDatabase(tt.database)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseDatabase() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseDatabase() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("parseDatabase() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
 }
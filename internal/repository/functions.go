@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"database/sql"
+	"firebird-web-admin/internal/domain"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// ListFunctions returns standalone PSQL, UDR and legacy UDF functions with their
+// arguments and return types, without their source.
+func (r *FirebirdRepository) ListFunctions(params domain.ConnectionParams) ([]domain.Routine, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	charset, err := loadDefaultCharset(db)
+	if err != nil {
+		log.Printf("ListFunctions error: %v", err)
+		return nil, err
+	}
+	functions, err := loadFunctions(db, charset, "", "")
+	if err != nil {
+		log.Printf("ListFunctions error: %v", err)
+		return nil, err
+	}
+	for i := range functions {
+		functions[i].Source = ""
+	}
+	return functions, nil
+}
+
+func (r *FirebirdRepository) GetFunction(params domain.ConnectionParams, name string) (*domain.Routine, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	fn, err := loadFunction(db, "", name)
+	if err != nil {
+		log.Printf("GetFunction error: %v", err)
+		return nil, err
+	}
+	return fn, nil
+}
+
+// GetFunctionSource returns the CREATE OR ALTER FUNCTION statement of a function,
+// or the DECLARE EXTERNAL FUNCTION statement of a legacy UDF.
+func (r *FirebirdRepository) GetFunctionSource(params domain.ConnectionParams, name string) (string, error) {
+	fn, err := r.GetFunction(params, name)
+	if err != nil {
+		return "", err
+	}
+	if fn.Legacy {
+		return legacyFunctionSQL(*fn), nil
+	}
+	return functionSQL(*fn, false), nil
+}
+
+// ExecuteFunction evaluates a function with SELECT ... FROM RDB$DATABASE. Arguments are
+// bound in declaration order and converted to their declared types.
+func (r *FirebirdRepository) ExecuteFunction(params domain.ConnectionParams, name string, args map[string]interface{}) ([]map[string]interface{}, []domain.Column, error) {
+	return r.executeFunction(params, "", name, args)
+}
+
+// executeFunction evaluates a standalone function, or a packaged one when pkg is set.
+func (r *FirebirdRepository) executeFunction(params domain.ConnectionParams, pkg string, name string, args map[string]interface{}) ([]map[string]interface{}, []domain.Column, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer db.Close()
+
+	fn, err := loadFunction(db, pkg, name)
+	if err != nil {
+		log.Printf("ExecuteFunction error: %v", err)
+		return nil, nil, err
+	}
+
+	query, values, err := functionCall(fn, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Printf("ExecuteFunction Query: %s, Args: %v", query, values)
+
+	rows, err := db.Query(query, values...)
+	if err != nil {
+		log.Printf("ExecuteFunction DB Error: %v", err)
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	data, cols, err := r.scanRows(rows, "", db)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range cols {
+		if fn.ReturnType != "" {
+			cols[i].Type = fn.ReturnType
+		}
+	}
+	if data == nil {
+		data = []map[string]interface{}{}
+	}
+	return data, cols, nil
+}
+
+// loadFunction loads a single standalone or packaged function with its typed arguments.
+// Names are looked up upper-cased, like procedure names.
+func loadFunction(db *sql.DB, pkg string, name string) (*domain.Routine, error) {
+	charset, err := loadDefaultCharset(db)
+	if err != nil {
+		return nil, err
+	}
+	functions, err := loadFunctions(db, charset, objectName(pkg), objectName(name))
+	if err != nil {
+		return nil, err
+	}
+	if len(functions) == 0 {
+		return nil, &domain.NotFoundError{ObjectType: "function", Name: routineRef(domain.Routine{Package: pkg, Name: name})}
+	}
+	return &functions[0], nil
+}
+
+// functionCall builds the statement that evaluates fn and its converted arguments.
+// The argument a legacy UDF returns its result in is not passed by the caller.
+func functionCall(fn *domain.Routine, args map[string]interface{}) (string, []interface{}, error) {
+	var values []interface{}
+	var placeholders []string
+	for i, p := range fn.Inputs {
+		if p.Direction == "OUT" {
+			continue
+		}
+		key := p.Name
+		if key == "" {
+			// Legacy UDF arguments have no names; they are addressed by position.
+			key = fmt.Sprintf("%d", i+1)
+		}
+		val, err := convertParameterValue(args[key], p.DataType)
+		if err != nil {
+			return "", nil, invalidf("argument %s: %v", key, err)
+		}
+		values = append(values, val)
+		placeholders = append(placeholders, "?")
+	}
+	query := fmt.Sprintf("SELECT %s(%s) AS %s FROM RDB$DATABASE", routineRef(*fn), strings.Join(placeholders, ", "), quoteIdent("RESULT"))
+	return query, values, nil
+}
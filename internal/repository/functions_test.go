@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"encoding/json"
+	"firebird-web-admin/internal/domain"
+	"reflect"
+	"testing"
+)
+
+func TestFunctionCall(t *testing.T) {
+	tests := []struct {
+		name     string
+		fn       domain.Routine
+		args     map[string]interface{}
+		query    string
+		values   []interface{}
+		hasError bool
+	}{
+		{
+			name: "PSQL function",
+			fn: domain.Routine{Name: "ADD_DAYS", Inputs: []domain.ProcedureParameter{
+				{Name: "D", DataType: "DATE", Direction: "IN"},
+				{Name: "N", DataType: "INTEGER", Direction: "IN"},
+			}},
+			args:   map[string]interface{}{"N": json.Number("3")},
+			query:  `SELECT "ADD_DAYS"(?, ?) AS "RESULT" FROM RDB$DATABASE`,
+			values: []interface{}{nil, int64(3)},
+		},
+		{
+			name: "Legacy UDF returning in an argument",
+			fn: domain.Routine{Name: "SUBSTRLEN", Legacy: true, Returns: "PARAMETER 1", Inputs: []domain.ProcedureParameter{
+				{DataType: "CSTRING(255)", Direction: "OUT"},
+				{DataType: "CSTRING(255)", Direction: "IN"},
+				{DataType: "SMALLINT", Direction: "IN"},
+			}},
+			args:   map[string]interface{}{"2": "abc", "3": json.Number("2")},
+			query:  `SELECT "SUBSTRLEN"(?, ?) AS "RESULT" FROM RDB$DATABASE`,
+			values: []interface{}{"abc", int64(2)},
+		},
+		{
+			name:  "Packaged function",
+			fn:    domain.Routine{Package: "APP", Name: "VERSION"},
+			query: `SELECT "APP"."VERSION"() AS "RESULT" FROM RDB$DATABASE`,
+		},
+		{
+			name:     "Invalid argument",
+			fn:       domain.Routine{Name: "F", Inputs: []domain.ProcedureParameter{{Name: "X", DataType: "INTEGER", Direction: "IN"}}},
+			args:     map[string]interface{}{"X": "abc"},
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, values, err := functionCall(&tt.fn, tt.args)
+			if (err != nil) != tt.hasError {
+				t.Fatalf("functionCall() error = %v, hasError %v", err, tt.hasError)
+			}
+			if tt.hasError {
+				return
+			}
+			if query != tt.query {
+				t.Errorf("functionCall() query = %v, want %v", query, tt.query)
+			}
+			if !reflect.DeepEqual(values, tt.values) {
+				t.Errorf("functionCall() values = %#v, want %#v", values, tt.values)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"database/sql"
+	"firebird-web-admin/internal/domain"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// defaultHealthThresholds are the limits used for thresholds left at zero.
+var defaultHealthThresholds = domain.HealthThresholds{
+	OITGap:         100000,
+	OATGap:         100000,
+	TransactionAge: 3600,
+}
+
+var shutdownModes = map[int64]string{0: "online", 1: "multi", 2: "single", 3: "full"}
+
+var backupStates = map[int64]string{0: "normal", 1: "stalled", 2: "merge"}
+
+// GetDatabaseHealth returns the header and transaction state of the database with warnings
+// for the limits exceeded.
+//
+// The driver does not expose isc_database_info, so the header values are read from
+// MON$DATABASE, which reports the same fields.
+func (r *FirebirdRepository) GetDatabaseHealth(params domain.ConnectionParams, thresholds domain.HealthThresholds) (*domain.DatabaseHealth, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	h := &domain.DatabaseHealth{}
+	var odsMajor, odsMinor, shutdown, backup, forcedWrites, readOnly int64
+	err = db.QueryRow(`
+		SELECT MON$DATABASE_NAME, MON$ODS_MAJOR, MON$ODS_MINOR, MON$PAGE_SIZE, MON$PAGES, MON$SQL_DIALECT,
+			MON$OLDEST_TRANSACTION, MON$OLDEST_ACTIVE, MON$OLDEST_SNAPSHOT, MON$NEXT_TRANSACTION,
+			MON$SWEEP_INTERVAL, MON$PAGE_BUFFERS, MON$FORCED_WRITES, MON$READ_ONLY, MON$SHUTDOWN_MODE, MON$BACKUP_STATE
+		FROM MON$DATABASE
+	`).Scan(&h.Database, &odsMajor, &odsMinor, &h.PageSize, &h.Pages, &h.SQLDialect,
+		&h.OldestTransaction, &h.OldestActive, &h.OldestSnapshot, &h.NextTransaction,
+		&h.SweepInterval, &h.PageBuffers, &forcedWrites, &readOnly, &shutdown, &backup)
+	if err != nil {
+		log.Printf("GetDatabaseHealth error: %v", err)
+		return nil, err
+	}
+	h.Database = strings.TrimSpace(h.Database)
+	h.ODSVersion = fmt.Sprintf("%d.%d", odsMajor, odsMinor)
+	h.ForcedWrites = forcedWrites == 1
+	h.ReadOnly = readOnly == 1
+	h.ShutdownMode = shutdownModes[shutdown]
+	h.BackupState = backupStates[backup]
+	h.OITGap = h.OldestActive - h.OldestTransaction
+	h.OATGap = h.NextTransaction - h.OldestActive
+	h.OSTGap = h.NextTransaction - h.OldestSnapshot
+
+	txs, err := loadMonitoredTransactions(db, "ORDER BY t.MON$TRANSACTION_ID ROWS 1")
+	if err != nil {
+		log.Printf("GetDatabaseHealth transactions error: %v", err)
+		return nil, err
+	}
+	if len(txs) > 0 {
+		h.OldestActiveTx = &txs[0]
+	}
+
+	h.Warnings = healthWarnings(h, thresholds)
+	return h, nil
+}
+
+// healthWarnings checks the report against the thresholds:
+//   - OIT_GAP: transactions rolled back or left in limbo keep the OIT behind; a sweep
+//     moves it forward;
+//   - OAT_GAP and LONG_TRANSACTION: a transaction that stays open keeps every record
+//     version created since it started, which a sweep cannot remove;
+//   - header flags that put data at risk or block normal use.
+func healthWarnings(h *domain.DatabaseHealth, t domain.HealthThresholds) []domain.HealthWarning {
+	if t.OITGap <= 0 {
+		t.OITGap = defaultHealthThresholds.OITGap
+	}
+	if t.OATGap <= 0 {
+		t.OATGap = defaultHealthThresholds.OATGap
+	}
+	if t.TransactionAge <= 0 {
+		t.TransactionAge = defaultHealthThresholds.TransactionAge
+	}
+
+	warnings := []domain.HealthWarning{}
+	add := func(level, code, format string, args ...interface{}) {
+		warnings = append(warnings, domain.HealthWarning{Level: level, Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if h.OITGap > t.OITGap {
+		msg := "OIT gap %d > %d, sweep recommended"
+		if h.SweepInterval == 0 {
+			msg += " (automatic sweep is disabled)"
+		}
+		add("warning", "OIT_GAP", msg, h.OITGap, t.OITGap)
+	}
+	if h.OATGap > t.OATGap {
+		msg := fmt.Sprintf("OAT gap %d > %d, a long-running transaction keeps old record versions from being garbage collected", h.OATGap, t.OATGap)
+		if tx := h.OldestActiveTx; tx != nil {
+			msg += fmt.Sprintf("; oldest transaction %d of attachment %d (%s)", tx.ID, tx.AttachmentID, transactionOwner(tx))
+		}
+		add("critical", "OAT_GAP", "%s", msg)
+	}
+	if tx := h.OldestActiveTx; tx != nil && tx.DurationMs/1000 > t.TransactionAge {
+		add("warning", "LONG_TRANSACTION", "Transaction %d of attachment %d (%s) has been open for %s",
+			tx.ID, tx.AttachmentID, transactionOwner(tx), time.Duration(tx.DurationMs)*time.Millisecond)
+	}
+	if !h.ForcedWrites {
+		add("warning", "FORCED_WRITES_OFF", "Forced writes are off, a server or OS crash can corrupt the database")
+	}
+	if h.ShutdownMode != "online" {
+		add("critical", "SHUTDOWN", "Database is shut down (%s)", h.ShutdownMode)
+	}
+	if h.BackupState != "normal" {
+		add("warning", "BACKUP_STATE", "Database is in nbackup %s state, changes go to the delta file until it is unlocked", h.BackupState)
+	}
+	if h.ReadOnly {
+		add("info", "READ_ONLY", "Database is read-only")
+	}
+	if h.SweepInterval == 0 && h.OITGap <= t.OITGap {
+		add("info", "SWEEP_DISABLED", "Automatic sweep is disabled, schedule a manual sweep")
+	}
+	return warnings
+}
+
+// transactionOwner describes who runs a transaction, e.g. "SYSDBA from 10.0.0.5, app.exe".
+func transactionOwner(tx *domain.MonitoredTransaction) string {
+	parts := []string{tx.User}
+	if tx.RemoteAddress != "" {
+		parts[0] += " from " + tx.RemoteAddress
+	}
+	if tx.RemoteProcess != "" {
+		parts = append(parts, tx.RemoteProcess)
+	}
+	return strings.Join(parts, ", ")
+}
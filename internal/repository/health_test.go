@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"firebird-web-admin/internal/domain"
+	"reflect"
+	"testing"
+)
+
+func TestHealthWarnings(t *testing.T) {
+	healthy := domain.DatabaseHealth{SweepInterval: 20000, ForcedWrites: true, ShutdownMode: "online", BackupState: "normal"}
+	oldTx := &domain.MonitoredTransaction{ID: 42, AttachmentID: 7, User: "APP", RemoteAddress: "10.0.0.5", DurationMs: 7200000}
+
+	tests := []struct {
+		name       string
+		modify     func(h *domain.DatabaseHealth)
+		thresholds domain.HealthThresholds
+		want       []string
+	}{
+		{name: "Healthy", modify: func(h *domain.DatabaseHealth) {}, want: []string{}},
+		{name: "OIT gap", modify: func(h *domain.DatabaseHealth) { h.OITGap = 150000 }, want: []string{"OIT_GAP"}},
+		{name: "OIT gap without automatic sweep", modify: func(h *domain.DatabaseHealth) {
+			h.OITGap = 150000
+			h.SweepInterval = 0
+		}, want: []string{"OIT_GAP"}},
+		{name: "Sweep disabled", modify: func(h *domain.DatabaseHealth) { h.SweepInterval = 0 }, want: []string{"SWEEP_DISABLED"}},
+		{name: "Long-running transaction", modify: func(h *domain.DatabaseHealth) {
+			h.OATGap = 250000
+			h.OldestActiveTx = oldTx
+		}, want: []string{"OAT_GAP", "LONG_TRANSACTION"}},
+		{name: "Custom thresholds", modify: func(h *domain.DatabaseHealth) {
+			h.OATGap = 5000
+			h.OldestActiveTx = oldTx
+		}, thresholds: domain.HealthThresholds{OATGap: 1000, TransactionAge: 86400}, want: []string{"OAT_GAP"}},
+		{name: "Header flags", modify: func(h *domain.DatabaseHealth) {
+			h.ForcedWrites = false
+			h.ShutdownMode = "single"
+			h.BackupState = "stalled"
+			h.ReadOnly = true
+		}, want: []string{"FORCED_WRITES_OFF", "SHUTDOWN", "BACKUP_STATE", "READ_ONLY"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := healthy
+			tt.modify(&h)
+			got := []string{}
+			for _, w := range healthWarnings(&h, tt.thresholds) {
+				got = append(got, w.Code)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("healthWarnings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
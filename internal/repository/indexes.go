@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"database/sql"
+	"firebird-web-admin/internal/domain"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// defaultMaxSelectivity is the RDB$STATISTICS value (1 / distinct keys) from which the index
+// report considers an index unselective: fewer than 10 distinct keys.
+const defaultMaxSelectivity = 0.1
+
+// ListIndexes returns the user indexes of a table, or of all tables when table is empty,
+// including the indexes backing constraints.
+func (r *FirebirdRepository) ListIndexes(params domain.ConnectionParams, table string) ([]domain.Index, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	indexes, err := loadIndexes(db, table)
+	if err != nil {
+		log.Printf("ListIndexes error: %v", err)
+		return nil, err
+	}
+	if indexes == nil {
+		indexes = []domain.Index{}
+	}
+	return indexes, nil
+}
+
+func (r *FirebirdRepository) GetIndex(params domain.ConnectionParams, name string) (*domain.Index, error) {
+	name = objectName(name)
+	indexes, err := r.ListIndexes(params, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range indexes {
+		if i.Name == name {
+			return &i, nil
+		}
+	}
+	return nil, &domain.NotFoundError{ObjectType: "index", Name: name}
+}
+
+// CreateIndex creates an index on segments or on an expression, inactive when requested.
+func (r *FirebirdRepository) CreateIndex(params domain.ConnectionParams, i domain.Index) error {
+	i.Name = strings.TrimSpace(i.Name)
+	i.Relation = strings.TrimSpace(i.Relation)
+	switch {
+	case i.Name == "":
+		return invalidf("index name is required")
+	case i.Relation == "":
+		return invalidf("table is required")
+	case len(i.Segments) == 0 && strings.TrimSpace(i.Expression) == "":
+		return invalidf("index needs columns or an expression")
+	}
+	if err := r.execDDL(params, indexSQL(i)); err != nil {
+		return err
+	}
+	if !i.Active {
+		return r.execDDL(params, indexStateSQL(i.Name, false))
+	}
+	return nil
+}
+
+func (r *FirebirdRepository) DropIndex(params domain.ConnectionParams, name string) error {
+	return r.execDDL(params, "DROP INDEX "+quoteIdent(objectName(name)))
+}
+
+// SetIndexActive activates or deactivates an index. Activating an index rebuilds it, also
+// when it already is active, so activate doubles as the rebuild of a fragmented index.
+func (r *FirebirdRepository) SetIndexActive(params domain.ConnectionParams, name string, active bool) error {
+	return r.execDDL(params, indexStateSQL(objectName(name), active))
+}
+
+func indexStateSQL(name string, active bool) string {
+	state := "ACTIVE"
+	if !active {
+		state = "INACTIVE"
+	}
+	return fmt.Sprintf("ALTER INDEX %s %s", quoteIdent(name), state)
+}
+
+// SetIndexStatistics recomputes the selectivity of an index and returns the updated index.
+func (r *FirebirdRepository) SetIndexStatistics(params domain.ConnectionParams, name string) (*domain.Index, error) {
+	name = objectName(name)
+	if err := r.execDDL(params, "SET STATISTICS INDEX "+quoteIdent(name)); err != nil {
+		return nil, err
+	}
+	// The quoted name is looked up as is.
+	return r.GetIndex(params, quoteIdent(name))
+}
+
+// GetIndexReport flags duplicate, redundant and unselective indexes. A maxSelectivity of 0
+// uses the default threshold.
+func (r *FirebirdRepository) GetIndexReport(params domain.ConnectionParams, maxSelectivity float64) ([]domain.IndexIssue, error) {
+	indexes, err := r.ListIndexes(params, "")
+	if err != nil {
+		return nil, err
+	}
+	if maxSelectivity <= 0 {
+		maxSelectivity = defaultMaxSelectivity
+	}
+	return analyzeIndexes(indexes, maxSelectivity), nil
+}
+
+// analyzeIndexes reports, per table:
+//   - DUPLICATE: an index with the same key as an active index that backs a constraint,
+//     enforces uniqueness the other one does not, or otherwise sorts first by name;
+//   - REDUNDANT: a non-unique index whose columns are a leading part of an active index;
+//   - LOW_SELECTIVITY: an active non-unique index with selectivity above maxSelectivity.
+//
+// Only indexes that do not back a constraint are reported as duplicate or redundant,
+// because the others cannot be dropped on their own.
+func analyzeIndexes(indexes []domain.Index, maxSelectivity float64) []domain.IndexIssue {
+	sorted := append([]domain.Index(nil), indexes...)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		if sorted[a].Relation != sorted[b].Relation {
+			return sorted[a].Relation < sorted[b].Relation
+		}
+		return sorted[a].Name < sorted[b].Name
+	})
+
+	issues := []domain.IndexIssue{}
+	for _, a := range sorted {
+		if a.Constraint == "" {
+			if issue, ok := coveringIndex(a, sorted); ok {
+				issues = append(issues, issue)
+			}
+		}
+		unique := a.Unique || a.Constraint == "PRIMARY KEY" || a.Constraint == "UNIQUE"
+		if a.Active && !unique && a.Statistics > maxSelectivity {
+			issues = append(issues, domain.IndexIssue{
+				Index:       a.Name,
+				Relation:    a.Relation,
+				Kind:        "LOW_SELECTIVITY",
+				Selectivity: a.Statistics,
+				Message:     fmt.Sprintf("Index %s has about %.0f distinct keys (selectivity %g), it rarely helps and slows down changes", a.Name, 1/a.Statistics, a.Statistics),
+			})
+		}
+	}
+	return issues
+}
+
+// coveringIndex returns a DUPLICATE or REDUNDANT issue for a when another active index
+// of the same table makes it unnecessary.
+func coveringIndex(a domain.Index, indexes []domain.Index) (domain.IndexIssue, bool) {
+	key := func(i domain.Index) string {
+		if i.Expression != "" {
+			return "(" + normalizeSQL(i.Expression) + ")"
+		}
+		return strings.Join(i.Segments, ",")
+	}
+	for _, b := range indexes {
+		if b.Name == a.Name || b.Relation != a.Relation || !b.Active || b.Descending != a.Descending {
+			continue
+		}
+		if a.Unique && !b.Unique {
+			continue
+		}
+		if key(a) == key(b) {
+			if !(b.Constraint != "" || b.Unique != a.Unique || b.Name < a.Name) {
+				continue
+			}
+			return domain.IndexIssue{
+				Index:     a.Name,
+				Relation:  a.Relation,
+				Kind:      "DUPLICATE",
+				CoveredBy: b.Name,
+				Message:   fmt.Sprintf("Index %s duplicates %s on %s", a.Name, b.Name, key(a)),
+			}, true
+		}
+		if !a.Unique && a.Expression == "" && b.Expression == "" && len(a.Segments) < len(b.Segments) &&
+			strings.Join(b.Segments[:len(a.Segments)], ",") == key(a) {
+			return domain.IndexIssue{
+				Index:     a.Name,
+				Relation:  a.Relation,
+				Kind:      "REDUNDANT",
+				CoveredBy: b.Name,
+				Message:   fmt.Sprintf("Index %s on (%s) is a leading part of %s on (%s)", a.Name, key(a), b.Name, key(b)),
+			}, true
+		}
+	}
+	return domain.IndexIssue{}, false
+}
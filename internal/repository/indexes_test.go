@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"firebird-web-admin/internal/domain"
+	"testing"
+)
+
+func TestAnalyzeIndexes(t *testing.T) {
+	tests := []struct {
+		name     string
+		indexes  []domain.Index
+		expected []domain.IndexIssue
+	}{
+		{
+			name: "Duplicate of primary key",
+			indexes: []domain.Index{
+				{Name: "RDB$PRIMARY1", Relation: "T", Unique: true, Active: true, Segments: []string{"ID"}, Constraint: "PRIMARY KEY"},
+				{Name: "IX_T_ID", Relation: "T", Active: true, Segments: []string{"ID"}},
+			},
+			expected: []domain.IndexIssue{{Index: "IX_T_ID", Relation: "T", Kind: "DUPLICATE", CoveredBy: "RDB$PRIMARY1"}},
+		},
+		{
+			name: "Duplicates reported once",
+			indexes: []domain.Index{
+				{Name: "IX_B", Relation: "T", Active: true, Segments: []string{"A"}},
+				{Name: "IX_A", Relation: "T", Active: true, Segments: []string{"A"}},
+			},
+			expected: []domain.IndexIssue{{Index: "IX_B", Relation: "T", Kind: "DUPLICATE", CoveredBy: "IX_A"}},
+		},
+		{
+			name: "Redundant prefix",
+			indexes: []domain.Index{
+				{Name: "IX_A", Relation: "T", Active: true, Segments: []string{"A"}},
+				{Name: "IX_AB", Relation: "T", Active: true, Segments: []string{"A", "B"}},
+				{Name: "IX_BA", Relation: "T", Active: true, Segments: []string{"B", "A"}},
+			},
+			expected: []domain.IndexIssue{{Index: "IX_A", Relation: "T", Kind: "REDUNDANT", CoveredBy: "IX_AB"}},
+		},
+		{
+			name: "Not covered by other direction, inactive index or other table",
+			indexes: []domain.Index{
+				{Name: "IX_A", Relation: "T", Active: true, Segments: []string{"A"}},
+				{Name: "IX_A_DESC", Relation: "T", Active: true, Descending: true, Segments: []string{"A", "B"}},
+				{Name: "IX_A_OFF", Relation: "T", Active: false, Segments: []string{"A"}},
+				{Name: "IX_U_A", Relation: "U", Active: true, Segments: []string{"A", "B"}},
+			},
+			expected: []domain.IndexIssue{{Index: "IX_A_OFF", Relation: "T", Kind: "DUPLICATE", CoveredBy: "IX_A"}},
+		},
+		{
+			name: "Unique index is not redundant",
+			indexes: []domain.Index{
+				{Name: "UQ_A", Relation: "T", Unique: true, Active: true, Segments: []string{"A"}},
+				{Name: "IX_AB", Relation: "T", Active: true, Segments: []string{"A", "B"}},
+			},
+			expected: []domain.IndexIssue{},
+		},
+		{
+			name: "Low selectivity",
+			indexes: []domain.Index{
+				{Name: "FK_STATUS", Relation: "T", Active: true, Segments: []string{"STATUS"}, Constraint: "FOREIGN KEY", Statistics: 0.5},
+				{Name: "RDB$PRIMARY1", Relation: "T", Unique: true, Active: true, Segments: []string{"ID"}, Constraint: "PRIMARY KEY", Statistics: 0.5},
+				{Name: "IX_NAME", Relation: "T", Active: true, Segments: []string{"NAME"}, Statistics: 0.001},
+			},
+			expected: []domain.IndexIssue{{Index: "FK_STATUS", Relation: "T", Kind: "LOW_SELECTIVITY", Selectivity: 0.5}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := analyzeIndexes(tt.indexes, defaultMaxSelectivity)
+			if len(issues) != len(tt.expected) {
+				t.Fatalf("analyzeIndexes() = %+v, want %+v", issues, tt.expected)
+			}
+			for i := range issues {
+				issues[i].Message = ""
+				if issues[i] != tt.expected[i] {
+					t.Errorf("issue %d = %+v, want %+v", i, issues[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"firebird-web-admin/internal/domain"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nakagami/firebirdsql"
+)
+
+// Services API items the driver only has as unexported constants.
+const (
+	iscActionSvcRepair      = 3
+	iscActionSvcValidate    = 30
+	iscSpbDbname            = 106
+	iscSpbOptions           = 108
+	iscSpbRprValidateDb     = 0x01
+	iscSpbRprCheckDb        = 0x10
+	iscSpbRprIgnoreChecksum = 0x20
+	iscSpbRprFull           = 0x80
+)
+
+var shutdownMethods = map[string]firebirdsql.ShutdownModeEx{
+	"force":        firebirdsql.ShutdownModeExForce,
+	"attachments":  firebirdsql.ShutdownModeExDenyNewAttachments,
+	"transactions": firebirdsql.ShutdownModeExDenyNewTransactions,
+}
+
+var operationModes = map[string]firebirdsql.OperationMode{
+	"normal": firebirdsql.OperationModeNormal,
+	"multi":  firebirdsql.OperationModeMulti,
+	"single": firebirdsql.OperationModeSingle,
+	"full":   firebirdsql.OperationModeFull,
+}
+
+var (
+	// validationCount matches the summary of an offline validation, e.g.
+	// "Number of record level errors	: 3".
+	validationCount = regexp.MustCompile(`(?i)^\s*Number of (.+?) errors\s*:\s*(\d+)`)
+	// validationFound matches an object with errors in an online validation, e.g.
+	// "Relation 128 (EMPLOYEE) : 2 ERRORS found".
+	validationFound = regexp.MustCompile(`^\s*(?:Relation|Index) \d+ \((.+?)\)\s*:\s*(\d+) ERRORS found`)
+)
+
+// ValidateDatabase validates the database and counts the errors reported. Offline
+// validation needs exclusive access. Corrupt structures are only reported, never mended
+// (gfix -mend is not sent); without ReadOnly the server still releases orphan pages.
+func (r *FirebirdRepository) ValidateDatabase(params domain.ConnectionParams, req domain.ValidationRequest) (*domain.ValidationReport, error) {
+	addr, database := serviceTarget(params)
+	action := byte(iscActionSvcRepair)
+	if req.Online {
+		action = iscActionSvcValidate
+	}
+	spb := firebirdsql.NewXPBWriterFromTag(action)
+	spb.PutString(iscSpbDbname, database)
+	if !req.Online {
+		options := int32(iscSpbRprValidateDb)
+		if req.Full {
+			options |= iscSpbRprFull
+		}
+		if req.ReadOnly {
+			options |= iscSpbRprCheckDb
+		}
+		if req.IgnoreChecksums {
+			options |= iscSpbRprIgnoreChecksum
+		}
+		spb.PutInt32(iscSpbOptions, options)
+	}
+
+	start := time.Now()
+	output, err := serviceOutput(params, addr, spb.Bytes())
+	if err != nil {
+		log.Printf("ValidateDatabase error: %v", err)
+		return nil, err
+	}
+	report := parseValidationReport(output)
+	report.ServiceResult = domain.ServiceResult{Action: "validate", Database: database, DurationMs: milliseconds(start), Output: output}
+	return report, nil
+}
+
+// parseValidationReport counts the errors in the output of an offline or online validation.
+func parseValidationReport(output []string) *domain.ValidationReport {
+	report := &domain.ValidationReport{Errors: make(map[string]int64)}
+	for _, line := range output {
+		m := validationCount.FindStringSubmatch(line)
+		if m == nil {
+			m = validationFound.FindStringSubmatch(line)
+		}
+		if m == nil {
+			continue
+		}
+		n, _ := strconv.ParseInt(m[2], 10, 64)
+		if n > 0 {
+			report.Errors[strings.TrimSpace(m[1])] += n
+			report.ErrorCount += n
+		}
+	}
+	report.Valid = report.ErrorCount == 0
+	return report
+}
+
+// serviceOutput runs a service request and returns its output lines.
+func serviceOutput(params domain.ConnectionParams, addr string, spb []byte) ([]string, error) {
+	sm, err := firebirdsql.NewServiceManager(addr, params.User, params.Password, serviceManagerOptions(params))
+	if err != nil {
+		return nil, err
+	}
+	defer sm.Close()
+
+	lines := make(chan string)
+	done := make(chan error, 1)
+	go func() {
+		done <- sm.ServiceAttach(spb, lines)
+		close(lines)
+	}()
+
+	output := []string{}
+	for line := range lines {
+		output = append(output, line)
+	}
+	return output, <-done
+}
+
+// SweepDatabase runs a manual sweep.
+func (r *FirebirdRepository) SweepDatabase(params domain.ConnectionParams) (*domain.ServiceResult, error) {
+	return r.maintain(params, "sweep", func(mm *firebirdsql.MaintenanceManager, database string) ([]string, error) {
+		return []string{}, mm.Sweep(database)
+	})
+}
+
+// ShutdownDatabase shuts the database down to the requested state. Force disconnects the
+// remaining attachments after the timeout; attachments and transactions fail if new
+// attachments or transactions are still active then.
+func (r *FirebirdRepository) ShutdownDatabase(params domain.ConnectionParams, req domain.ShutdownRequest) (*domain.ServiceResult, error) {
+	method, ok := shutdownMethods[req.Mode]
+	if !ok {
+		return nil, invalidf("invalid shutdown mode %q, use force, attachments or transactions", req.Mode)
+	}
+	if req.State == "" {
+		req.State = "multi"
+	}
+	state, ok := operationModes[req.State]
+	if !ok || req.State == "normal" {
+		return nil, invalidf("invalid shutdown state %q, use multi, single or full", req.State)
+	}
+	if req.Timeout < 0 {
+		return nil, invalidf("timeout must not be negative")
+	}
+	return r.maintain(params, "shutdown", func(mm *firebirdsql.MaintenanceManager, database string) ([]string, error) {
+		return []string{fmt.Sprintf("%s shutdown after %d seconds (%s)", req.State, req.Timeout, req.Mode)},
+			mm.ShutdownEx(database, state, method, uint(req.Timeout))
+	})
+}
+
+// BringOnline brings a shut down database online, to normal unless state says otherwise.
+func (r *FirebirdRepository) BringOnline(params domain.ConnectionParams, state string) (*domain.ServiceResult, error) {
+	if state == "" {
+		state = "normal"
+	}
+	mode, ok := operationModes[state]
+	if !ok || state == "full" {
+		return nil, invalidf("invalid online state %q, use normal, multi or single", state)
+	}
+	return r.maintain(params, "online", func(mm *firebirdsql.MaintenanceManager, database string) ([]string, error) {
+		return []string{"database is " + state}, mm.OnlineEx(database, mode)
+	})
+}
+
+// SetDatabaseProperties changes the header settings that are set, one service call each.
+func (r *FirebirdRepository) SetDatabaseProperties(params domain.ConnectionParams, props domain.DatabaseProperties) (*domain.ServiceResult, error) {
+	if props.SweepInterval != nil && *props.SweepInterval < 0 {
+		return nil, invalidf("sweep interval must not be negative")
+	}
+	if props.PageBuffers != nil && *props.PageBuffers < 0 {
+		return nil, invalidf("page buffers must not be negative")
+	}
+	return r.maintain(params, "properties", func(mm *firebirdsql.MaintenanceManager, database string) ([]string, error) {
+		changes := []string{}
+		if props.SweepInterval != nil {
+			if err := mm.SetSweepInterval(database, uint(*props.SweepInterval)); err != nil {
+				return changes, err
+			}
+			changes = append(changes, fmt.Sprintf("sweep interval set to %d", *props.SweepInterval))
+		}
+		if props.PageBuffers != nil {
+			if err := mm.SetPageBuffers(database, int(*props.PageBuffers)); err != nil {
+				return changes, err
+			}
+			changes = append(changes, fmt.Sprintf("page buffers set to %d", *props.PageBuffers))
+		}
+		if props.ForcedWrites != nil {
+			set, mode := mm.SetWriteModeAsync, "off"
+			if *props.ForcedWrites {
+				set, mode = mm.SetWriteModeSync, "on"
+			}
+			if err := set(database); err != nil {
+				return changes, err
+			}
+			changes = append(changes, "forced writes "+mode)
+		}
+		if props.ReadOnly != nil {
+			set, mode := mm.SetAccessModeReadWrite, "read-write"
+			if *props.ReadOnly {
+				set, mode = mm.SetAccessModeReadOnly, "read-only"
+			}
+			if err := set(database); err != nil {
+				return changes, err
+			}
+			changes = append(changes, "database is "+mode)
+		}
+		return changes, nil
+	})
+}
+
+// maintain runs a maintenance action and times it.
+func (r *FirebirdRepository) maintain(params domain.ConnectionParams, action string, run func(*firebirdsql.MaintenanceManager, string) ([]string, error)) (*domain.ServiceResult, error) {
+	addr, database := serviceTarget(params)
+	mm, err := firebirdsql.NewMaintenanceManager(addr, params.User, params.Password, serviceManagerOptions(params))
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	output, err := run(mm, database)
+	if err != nil {
+		log.Printf("Maintenance %s error: %v", action, err)
+		return nil, err
+	}
+	return &domain.ServiceResult{Action: action, Database: database, DurationMs: milliseconds(start), Output: output}, nil
+}
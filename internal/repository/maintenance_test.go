@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseValidationReport(t *testing.T) {
+	tests := []struct {
+		name   string
+		output []string
+		errors map[string]int64
+		count  int64
+	}{
+		{
+			name:   "Clean offline validation",
+			output: []string{},
+			errors: map[string]int64{},
+		},
+		{
+			name: "Offline summary",
+			output: []string{
+				"Summary of validation errors",
+				"",
+				"\tNumber of record level errors\t: 3",
+				"\tNumber of database page errors\t: 1",
+				"\tNumber of index page errors\t: 0",
+			},
+			errors: map[string]int64{"record level": 3, "database page": 1},
+			count:  4,
+		},
+		{
+			name: "Online validation",
+			output: []string{
+				"Validation started",
+				"Relation 128 (COUNTRY)",
+				"Relation 128 (COUNTRY) is ok",
+				"Relation 129 (JOB)",
+				"  Index 1 (RDB$PRIMARY2)",
+				"  Index 1 (RDB$PRIMARY2) : 2 ERRORS found",
+				"Relation 129 (JOB) : 1 ERRORS found",
+				"Validation finished",
+			},
+			errors: map[string]int64{"RDB$PRIMARY2": 2, "JOB": 1},
+			count:  3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := parseValidationReport(tt.output)
+			if !reflect.DeepEqual(report.Errors, tt.errors) || report.ErrorCount != tt.count || report.Valid != (tt.count == 0) {
+				t.Errorf("parseValidationReport() = %v (%d, valid %v), want %v (%d)", report.Errors, report.ErrorCount, report.Valid, tt.errors, tt.count)
+			}
+		})
+	}
+}
@@ -0,0 +1,1177 @@
+package repository
+
+import (
+	"database/sql"
+	"firebird-web-admin/internal/domain"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// fieldInfoColumns selects the RDB$FIELDS columns scanned by fieldInfo.dest().
+// Queries using it must alias RDB$FIELDS as "f" and LEFT JOIN RDB$CHARACTER_SETS as "cs".
+const fieldInfoColumns = `f.RDB$FIELD_TYPE, f.RDB$FIELD_SUB_TYPE, f.RDB$FIELD_LENGTH, f.RDB$FIELD_PRECISION,
+	f.RDB$FIELD_SCALE, f.RDB$CHARACTER_LENGTH, f.RDB$SEGMENT_LENGTH, cs.RDB$CHARACTER_SET_NAME`
+
+// fieldInfo holds the raw type columns of RDB$FIELDS needed to render an SQL type.
+type fieldInfo struct {
+	fieldType     sql.NullInt64
+	subType       sql.NullInt64
+	length        sql.NullInt64
+	precision     sql.NullInt64
+	scale         sql.NullInt64
+	charLength    sql.NullInt64
+	segmentLength sql.NullInt64
+	charset       sql.NullString
+}
+
+func (fi *fieldInfo) dest() []interface{} {
+	return []interface{}{&fi.fieldType, &fi.subType, &fi.length, &fi.precision, &fi.scale, &fi.charLength, &fi.segmentLength, &fi.charset}
+}
+
+func (fi fieldInfo) sqlType(defaultCharset string) string {
+	charLen := fi.length.Int64
+	if fi.charLength.Valid && fi.charLength.Int64 > 0 {
+		charLen = fi.charLength.Int64
+	}
+	return formatFieldType(fi.fieldType.Int64, fi.subType.Int64, charLen, fi.precision.Int64, fi.scale.Int64,
+		fi.segmentLength.Int64, strings.TrimSpace(fi.charset.String), defaultCharset)
+}
+
+// formatFieldType renders a Firebird type from the RDB$FIELDS type code and its modifiers.
+// The character set is only printed when it differs from the database default.
+func formatFieldType(fieldType, subType, charLen, precision, scale, segmentLength int64, charset, defaultCharset string) string {
+	withCharset := func(t string) string {
+		if charset != "" && charset != "NONE" && !strings.EqualFold(charset, defaultCharset) {
+			return t + " CHARACTER SET " + charset
+		}
+		if charset == "NONE" && defaultCharset != "" && defaultCharset != "NONE" {
+			return t + " CHARACTER SET NONE"
+		}
+		return t
+	}
+	exact := func(name string, defaultPrecision int64) string {
+		if subType == 0 && scale == 0 {
+			return name
+		}
+		if precision == 0 {
+			precision = defaultPrecision
+		}
+		kind := "NUMERIC"
+		if subType == 2 {
+			kind = "DECIMAL"
+		}
+		return fmt.Sprintf("%s(%d, %d)", kind, precision, -scale)
+	}
+
+	switch fieldType {
+	case 7:
+		return exact("SMALLINT", 4)
+	case 8:
+		return exact("INTEGER", 9)
+	case 16:
+		return exact("BIGINT", 18)
+	case 26:
+		return exact("INT128", 38)
+	case 10:
+		return "FLOAT"
+	case 27:
+		return "DOUBLE PRECISION"
+	case 12:
+		return "DATE"
+	case 13:
+		return "TIME"
+	case 28:
+		return "TIME WITH TIME ZONE"
+	case 35:
+		return "TIMESTAMP"
+	case 29:
+		return "TIMESTAMP WITH TIME ZONE"
+	case 23:
+		return "BOOLEAN"
+	case 24:
+		return "DECFLOAT(16)"
+	case 25:
+		return "DECFLOAT(34)"
+	case 14:
+		return withCharset(fmt.Sprintf("CHAR(%d)", charLen))
+	case 37:
+		return withCharset(fmt.Sprintf("VARCHAR(%d)", charLen))
+	case 40:
+		return withCharset(fmt.Sprintf("CSTRING(%d)", charLen))
+	case 261:
+		sub := fmt.Sprintf("%d", subType)
+		switch subType {
+		case 0:
+			sub = "BINARY"
+		case 1:
+			sub = "TEXT"
+		}
+		t := "BLOB SUB_TYPE " + sub
+		if segmentLength > 0 && segmentLength != 80 {
+			t += fmt.Sprintf(" SEGMENT SIZE %d", segmentLength)
+		}
+		if subType == 1 {
+			return withCharset(t)
+		}
+		return t
+	default:
+		return fmt.Sprintf("TYPE_%d", fieldType)
+	}
+}
+
+// parameterRow is a routine parameter row shared by procedure and function parameter queries.
+type parameterRow struct {
+	routine         string
+	name            sql.NullString
+	order           int64 // RDB$PARAMETER_TYPE for procedures, RDB$ARGUMENT_POSITION for functions
+	source          sql.NullString
+	field           fieldInfo
+	nullFlag        sql.NullInt64
+	defaultSource   sql.NullString
+	mechanism       sql.NullInt64
+	typeOfRelation  sql.NullString
+	typeOfField     sql.NullString
+	collationID     sql.NullInt64
+	baseCollationID sql.NullInt64
+	collation       sql.NullString
+	description     sql.NullString
+}
+
+func (p *parameterRow) dest() []interface{} {
+	d := []interface{}{&p.routine, &p.name, &p.order, &p.source}
+	d = append(d, p.field.dest()...)
+	return append(d, &p.nullFlag, &p.defaultSource, &p.mechanism, &p.typeOfRelation, &p.typeOfField,
+		&p.collationID, &p.baseCollationID, &p.collation, &p.description)
+}
+
+// parameter converts the row to a domain.ProcedureParameter, resolving domain and TYPE OF references.
+func (p *parameterRow) parameter(defaultCharset string) domain.ProcedureParameter {
+	source := strings.TrimSpace(p.source.String)
+	var typ string
+	switch {
+	case p.typeOfRelation.Valid && p.typeOfField.Valid:
+		typ = fmt.Sprintf("TYPE OF COLUMN %s.%s", quoteIdent(strings.TrimSpace(p.typeOfRelation.String)), quoteIdent(strings.TrimSpace(p.typeOfField.String)))
+	case source != "" && !strings.HasPrefix(source, "RDB$"):
+		typ = quoteIdent(source)
+		if p.mechanism.Valid && p.mechanism.Int64 == 1 {
+			typ = "TYPE OF " + typ
+		}
+	default:
+		typ = p.field.sqlType(defaultCharset)
+	}
+	param := domain.ProcedureParameter{
+		Name:        strings.TrimSpace(p.name.String),
+		Type:        typ,
+		DataType:    p.field.sqlType(defaultCharset),
+		NotNull:     p.nullFlag.Valid && p.nullFlag.Int64 == 1,
+		Default:     strings.TrimSpace(p.defaultSource.String),
+		Description: p.description.String,
+	}
+	if p.collationID.Valid && p.collationID.Int64 > 0 && p.collationID != p.baseCollationID {
+		param.Collation = strings.TrimSpace(p.collation.String)
+	}
+	return param
+}
+
+// GetSchema loads all user-defined metadata of the database in one connection.
+func (r *FirebirdRepository) GetSchema(params domain.ConnectionParams) (*domain.Schema, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	s := &domain.Schema{}
+	var charset, description sql.NullString
+	if err := db.QueryRow("SELECT RDB$CHARACTER_SET_NAME, RDB$DESCRIPTION FROM RDB$DATABASE").Scan(&charset, &description); err != nil {
+		log.Printf("GetSchema database info error: %v", err)
+		return nil, err
+	}
+	s.DefaultCharset = strings.TrimSpace(charset.String)
+	s.Description = description.String
+
+	steps := []struct {
+		name string
+		load func() error
+	}{
+		{"domains", func() (err error) { s.Domains, err = loadDomains(db, s.DefaultCharset); return }},
+		{"sequences", func() (err error) { s.Sequences, err = loadSequences(db, false, ""); return }},
+		{"exceptions", func() (err error) { s.Exceptions, err = loadExceptions(db); return }},
+		{"tables", func() (err error) { s.Tables, s.Views, err = loadRelations(db, s.DefaultCharset); return }},
+		{"procedures", func() (err error) { s.Procedures, err = loadProcedures(db, s.DefaultCharset, "", ""); return }},
+		{"functions", func() (err error) { s.Functions, err = loadFunctions(db, s.DefaultCharset, "", ""); return }},
+		{"packages", func() (err error) { s.Packages, err = loadPackages(db, ""); return }},
+		{"triggers", func() (err error) { s.Triggers, err = loadTriggers(db, "", ""); return }},
+		{"indexes", func() (err error) { s.Indexes, err = loadIndexes(db, ""); return }},
+		{"roles", func() (err error) { s.Roles, err = loadRoles(db); return }},
+		{"grants", func() (err error) { s.Grants, err = loadGrants(db); return }},
+	}
+	for _, step := range steps {
+		if err := step.load(); err != nil {
+			log.Printf("GetSchema %s error: %v", step.name, err)
+			return nil, fmt.Errorf("loading %s: %w", step.name, err)
+		}
+	}
+	return s, nil
+}
+
+// loadDefaultCharset returns the default character set of the database.
+func loadDefaultCharset(db *sql.DB) (string, error) {
+	var charset sql.NullString
+	if err := db.QueryRow("SELECT RDB$CHARACTER_SET_NAME FROM RDB$DATABASE").Scan(&charset); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(charset.String), nil
+}
+
+func loadDomains(db *sql.DB, defaultCharset string) ([]domain.Domain, error) {
+	query := `
+		SELECT f.RDB$FIELD_NAME, ` + fieldInfoColumns + `,
+			f.RDB$NULL_FLAG, f.RDB$DEFAULT_SOURCE, f.RDB$VALIDATION_SOURCE,
+			f.RDB$COLLATION_ID, co.RDB$COLLATION_NAME, f.RDB$DESCRIPTION
+		FROM RDB$FIELDS f
+		LEFT JOIN RDB$CHARACTER_SETS cs ON cs.RDB$CHARACTER_SET_ID = f.RDB$CHARACTER_SET_ID
+		LEFT JOIN RDB$COLLATIONS co ON co.RDB$COLLATION_ID = f.RDB$COLLATION_ID AND co.RDB$CHARACTER_SET_ID = f.RDB$CHARACTER_SET_ID
+		WHERE f.RDB$FIELD_NAME NOT STARTING WITH 'RDB$'
+		AND (f.RDB$SYSTEM_FLAG IS NULL OR f.RDB$SYSTEM_FLAG = 0)
+		ORDER BY f.RDB$FIELD_NAME
+	`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var domains []domain.Domain
+	for rows.Next() {
+		var name string
+		var fi fieldInfo
+		var nullFlag, collationID sql.NullInt64
+		var defaultSource, checkSource, collation, description sql.NullString
+		dest := append([]interface{}{&name}, fi.dest()...)
+		dest = append(dest, &nullFlag, &defaultSource, &checkSource, &collationID, &collation, &description)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		d := domain.Domain{
+			Name:        strings.TrimSpace(name),
+			Type:        fi.sqlType(defaultCharset),
+			NotNull:     nullFlag.Valid && nullFlag.Int64 == 1,
+			Default:     strings.TrimSpace(defaultSource.String),
+			Check:       strings.TrimSpace(checkSource.String),
+			Description: description.String,
+		}
+		if collationID.Valid && collationID.Int64 > 0 {
+			d.Collation = strings.TrimSpace(collation.String)
+		}
+		domains = append(domains, d)
+	}
+	return domains, rows.Err()
+}
+
+// loadSequences loads user sequences. With identity set, the internal generators
+// of identity columns (system flag 6) are included as well. A name loads only that sequence.
+func loadSequences(db *sql.DB, identity bool, name string) ([]domain.Sequence, error) {
+	query := `
+		SELECT RDB$GENERATOR_NAME, RDB$INITIAL_VALUE, RDB$GENERATOR_INCREMENT, RDB$DESCRIPTION, RDB$SYSTEM_FLAG
+		FROM RDB$GENERATORS
+		WHERE (RDB$SYSTEM_FLAG IS NULL OR RDB$SYSTEM_FLAG = 0 OR (? = 1 AND RDB$SYSTEM_FLAG = 6))
+		AND (CAST(? AS VARCHAR(63)) = '' OR RDB$GENERATOR_NAME = ?)
+		ORDER BY RDB$GENERATOR_NAME
+	`
+	withIdentity := 0
+	if identity {
+		withIdentity = 1
+	}
+	rows, err := db.Query(query, withIdentity, name, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sequences []domain.Sequence
+	for rows.Next() {
+		var name string
+		var initial, increment, systemFlag sql.NullInt64
+		var description sql.NullString
+		if err := rows.Scan(&name, &initial, &increment, &description, &systemFlag); err != nil {
+			return nil, err
+		}
+		seq := domain.Sequence{
+			Name:         strings.TrimSpace(name),
+			InitialValue: initial.Int64,
+			Increment:    1,
+			Identity:     systemFlag.Int64 == 6,
+			Description:  description.String,
+		}
+		if increment.Valid {
+			seq.Increment = increment.Int64
+		}
+		sequences = append(sequences, seq)
+	}
+	return sequences, rows.Err()
+}
+
+func loadExceptions(db *sql.DB) ([]domain.DatabaseException, error) {
+	query := `
+		SELECT RDB$EXCEPTION_NAME, RDB$NUMBER, RDB$MESSAGE, RDB$DESCRIPTION
+		FROM RDB$EXCEPTIONS
+		WHERE (RDB$SYSTEM_FLAG IS NULL OR RDB$SYSTEM_FLAG = 0)
+		ORDER BY RDB$EXCEPTION_NAME
+	`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exceptions []domain.DatabaseException
+	for rows.Next() {
+		var name string
+		var number sql.NullInt64
+		var message, description sql.NullString
+		if err := rows.Scan(&name, &number, &message, &description); err != nil {
+			return nil, err
+		}
+		exceptions = append(exceptions, domain.DatabaseException{
+			Name:        strings.TrimSpace(name),
+			Number:      number.Int64,
+			Message:     message.String,
+			Description: description.String,
+		})
+	}
+	return exceptions, rows.Err()
+}
+
+// loadRelations loads user tables and views together with their columns.
+func loadRelations(db *sql.DB, defaultCharset string) ([]domain.TableDefinition, []domain.ViewDefinition, error) {
+	columns, err := loadRelationColumns(db, defaultCharset, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	constraints, err := loadConstraints(db, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	viewDeps, err := loadViewDependencies(db)
+	if err != nil {
+		return nil, nil, err
+	}
+	viewColumns, err := loadViewColumnDependencies(db)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query := `
+		SELECT RDB$RELATION_NAME, RDB$RELATION_TYPE, RDB$EXTERNAL_FILE, RDB$VIEW_SOURCE,
+			CASE WHEN RDB$VIEW_BLR IS NULL THEN 0 ELSE 1 END, RDB$DESCRIPTION
+		FROM RDB$RELATIONS
+		WHERE (RDB$SYSTEM_FLAG IS NULL OR RDB$SYSTEM_FLAG = 0)
+		ORDER BY RDB$RELATION_NAME
+	`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var tables []domain.TableDefinition
+	var views []domain.ViewDefinition
+	for rows.Next() {
+		var name string
+		var relType, isView sql.NullInt64
+		var externalFile, viewSource, description sql.NullString
+		if err := rows.Scan(&name, &relType, &externalFile, &viewSource, &isView, &description); err != nil {
+			return nil, nil, err
+		}
+		name = strings.TrimSpace(name)
+		if isView.Int64 == 1 {
+			views = append(views, domain.ViewDefinition{
+				Name:        name,
+				Columns:     columns[name],
+				Source:      strings.TrimSpace(viewSource.String),
+				DependsOn:   viewDeps[name],
+				UsesColumns: viewColumns[name],
+				Description: description.String,
+			})
+			continue
+		}
+		tables = append(tables, domain.TableDefinition{
+			Name:         name,
+			Kind:         tableKind(relType.Int64),
+			ExternalFile: strings.TrimSpace(externalFile.String),
+			Columns:      columns[name],
+			Constraints:  constraints[name],
+			Description:  description.String,
+		})
+	}
+	return tables, views, rows.Err()
+}
+
+// tableKind maps RDB$RELATION_TYPE to the kind of a TableDefinition.
+func tableKind(relType int64) string {
+	switch relType {
+	case 2:
+		return "EXTERNAL"
+	case 4:
+		return "GTT_PRESERVE"
+	case 5:
+		return "GTT_DELETE"
+	}
+	return "TABLE"
+}
+
+// loadRelationColumns returns the columns of user relations keyed by relation name.
+// An empty relation loads the columns of all relations.
+func loadRelationColumns(db *sql.DB, defaultCharset string, relation string) (map[string][]domain.TableColumn, error) {
+	query := `
+		SELECT rf.RDB$RELATION_NAME, rf.RDB$FIELD_NAME, rf.RDB$FIELD_SOURCE, ` + fieldInfoColumns + `,
+			rf.RDB$NULL_FLAG, rf.RDB$DEFAULT_SOURCE, f.RDB$COMPUTED_SOURCE, rf.RDB$IDENTITY_TYPE,
+			rf.RDB$COLLATION_ID, f.RDB$COLLATION_ID, co.RDB$COLLATION_NAME, rf.RDB$DESCRIPTION
+		FROM RDB$RELATION_FIELDS rf
+		JOIN RDB$RELATIONS r ON r.RDB$RELATION_NAME = rf.RDB$RELATION_NAME
+		JOIN RDB$FIELDS f ON f.RDB$FIELD_NAME = rf.RDB$FIELD_SOURCE
+		LEFT JOIN RDB$CHARACTER_SETS cs ON cs.RDB$CHARACTER_SET_ID = f.RDB$CHARACTER_SET_ID
+		LEFT JOIN RDB$COLLATIONS co ON co.RDB$COLLATION_ID = rf.RDB$COLLATION_ID AND co.RDB$CHARACTER_SET_ID = f.RDB$CHARACTER_SET_ID
+		WHERE (r.RDB$SYSTEM_FLAG IS NULL OR r.RDB$SYSTEM_FLAG = 0)
+		AND (CAST(? AS VARCHAR(63)) = '' OR rf.RDB$RELATION_NAME = ?)
+		ORDER BY rf.RDB$RELATION_NAME, rf.RDB$FIELD_POSITION
+	`
+	rows, err := db.Query(query, relation, relation)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string][]domain.TableColumn)
+	for rows.Next() {
+		var relation, name, source string
+		var fi fieldInfo
+		var nullFlag, identityType, collationID, baseCollationID sql.NullInt64
+		var defaultSource, computedSource, collation, description sql.NullString
+		dest := append([]interface{}{&relation, &name, &source}, fi.dest()...)
+		dest = append(dest, &nullFlag, &defaultSource, &computedSource, &identityType, &collationID, &baseCollationID, &collation, &description)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		relation = strings.TrimSpace(relation)
+		source = strings.TrimSpace(source)
+
+		col := domain.TableColumn{
+			Name:        strings.TrimSpace(name),
+			Type:        fi.sqlType(defaultCharset),
+			NotNull:     nullFlag.Valid && nullFlag.Int64 == 1,
+			Default:     strings.TrimSpace(defaultSource.String),
+			Computed:    strings.TrimSpace(computedSource.String),
+			Description: description.String,
+		}
+		if !strings.HasPrefix(source, "RDB$") {
+			col.Domain = source
+			if collationID.Valid && collationID.Int64 > 0 && collationID != baseCollationID {
+				col.Collation = strings.TrimSpace(collation.String)
+			}
+		} else if collationID.Valid && collationID.Int64 > 0 {
+			col.Collation = strings.TrimSpace(collation.String)
+		}
+		if identityType.Valid {
+			col.Identity = "BY DEFAULT"
+			if identityType.Int64 == 0 {
+				col.Identity = "ALWAYS"
+			}
+		}
+		result[relation] = append(result[relation], col)
+	}
+	return result, rows.Err()
+}
+
+// loadIndexSegments returns the segment column names of every index keyed by index name.
+func loadIndexSegments(db *sql.DB) (map[string][]string, error) {
+	rows, err := db.Query(`
+		SELECT RDB$INDEX_NAME, RDB$FIELD_NAME
+		FROM RDB$INDEX_SEGMENTS
+		ORDER BY RDB$INDEX_NAME, RDB$FIELD_POSITION
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string][]string)
+	for rows.Next() {
+		var index, field string
+		if err := rows.Scan(&index, &field); err != nil {
+			return nil, err
+		}
+		index = strings.TrimSpace(index)
+		result[index] = append(result[index], strings.TrimSpace(field))
+	}
+	return result, rows.Err()
+}
+
+// loadConstraints returns PRIMARY KEY, UNIQUE, FOREIGN KEY and CHECK constraints keyed by relation name.
+// An empty relation loads constraints of all relations.
+func loadConstraints(db *sql.DB, relation string) (map[string][]domain.Constraint, error) {
+	segments, err := loadIndexSegments(db)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT rc.RDB$RELATION_NAME, rc.RDB$CONSTRAINT_NAME, rc.RDB$CONSTRAINT_TYPE, rc.RDB$INDEX_NAME, i.RDB$INDEX_TYPE,
+			ref.RDB$UPDATE_RULE, ref.RDB$DELETE_RULE, uq.RDB$RELATION_NAME, uq.RDB$INDEX_NAME,
+			(SELECT FIRST 1 t.RDB$TRIGGER_SOURCE
+			 FROM RDB$CHECK_CONSTRAINTS cc
+			 JOIN RDB$TRIGGERS t ON t.RDB$TRIGGER_NAME = cc.RDB$TRIGGER_NAME
+			 WHERE cc.RDB$CONSTRAINT_NAME = rc.RDB$CONSTRAINT_NAME AND rc.RDB$CONSTRAINT_TYPE = 'CHECK')
+		FROM RDB$RELATION_CONSTRAINTS rc
+		LEFT JOIN RDB$INDICES i ON i.RDB$INDEX_NAME = rc.RDB$INDEX_NAME
+		LEFT JOIN RDB$REF_CONSTRAINTS ref ON ref.RDB$CONSTRAINT_NAME = rc.RDB$CONSTRAINT_NAME
+		LEFT JOIN RDB$RELATION_CONSTRAINTS uq ON uq.RDB$CONSTRAINT_NAME = ref.RDB$CONST_NAME_UQ
+		WHERE rc.RDB$CONSTRAINT_TYPE IN ('PRIMARY KEY', 'UNIQUE', 'FOREIGN KEY', 'CHECK')
+		AND (CAST(? AS VARCHAR(63)) = '' OR rc.RDB$RELATION_NAME = ?)
+		ORDER BY rc.RDB$RELATION_NAME,
+			CASE rc.RDB$CONSTRAINT_TYPE WHEN 'PRIMARY KEY' THEN 1 WHEN 'UNIQUE' THEN 2 WHEN 'FOREIGN KEY' THEN 3 ELSE 4 END,
+			rc.RDB$CONSTRAINT_NAME
+	`
+	rows, err := db.Query(query, relation, relation)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string][]domain.Constraint)
+	for rows.Next() {
+		var rel, name, ctype string
+		var index, updateRule, deleteRule, refTable, refIndex, checkSource sql.NullString
+		var indexType sql.NullInt64
+		if err := rows.Scan(&rel, &name, &ctype, &index, &indexType, &updateRule, &deleteRule, &refTable, &refIndex, &checkSource); err != nil {
+			return nil, err
+		}
+		rel = strings.TrimSpace(rel)
+		c := domain.Constraint{
+			Name:       strings.TrimSpace(name),
+			Type:       strings.TrimSpace(ctype),
+			Index:      strings.TrimSpace(index.String),
+			Descending: indexType.Valid && indexType.Int64 == 1,
+		}
+		if c.Type == "CHECK" {
+			c.Check = strings.TrimSpace(checkSource.String)
+		} else {
+			c.Columns = segments[c.Index]
+		}
+		if c.Type == "FOREIGN KEY" {
+			c.RefTable = strings.TrimSpace(refTable.String)
+			c.RefColumns = segments[strings.TrimSpace(refIndex.String)]
+			c.OnUpdate = strings.TrimSpace(updateRule.String)
+			c.OnDelete = strings.TrimSpace(deleteRule.String)
+		}
+		result[rel] = append(result[rel], c)
+	}
+	return result, rows.Err()
+}
+
+// loadViewDependencies returns, for each view, the other views it selects from.
+func loadViewDependencies(db *sql.DB) (map[string][]string, error) {
+	rows, err := db.Query(`
+		SELECT DISTINCT d.RDB$DEPENDENT_NAME, d.RDB$DEPENDED_ON_NAME
+		FROM RDB$DEPENDENCIES d
+		JOIN RDB$RELATIONS r ON r.RDB$RELATION_NAME = d.RDB$DEPENDED_ON_NAME AND r.RDB$VIEW_BLR IS NOT NULL
+		WHERE d.RDB$DEPENDENT_TYPE = 1 AND d.RDB$DEPENDED_ON_TYPE = 0
+		AND d.RDB$DEPENDENT_NAME <> d.RDB$DEPENDED_ON_NAME
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string][]string)
+	for rows.Next() {
+		var view, dependsOn string
+		if err := rows.Scan(&view, &dependsOn); err != nil {
+			return nil, err
+		}
+		view = strings.TrimSpace(view)
+		result[view] = append(result[view], strings.TrimSpace(dependsOn))
+	}
+	return result, rows.Err()
+}
+
+// loadViewColumnDependencies returns, for each view, the table columns it selects, by table.
+func loadViewColumnDependencies(db *sql.DB) (map[string]map[string][]string, error) {
+	rows, err := db.Query(`
+		SELECT DISTINCT d.RDB$DEPENDENT_NAME, d.RDB$DEPENDED_ON_NAME, d.RDB$FIELD_NAME
+		FROM RDB$DEPENDENCIES d
+		JOIN RDB$RELATIONS r ON r.RDB$RELATION_NAME = d.RDB$DEPENDED_ON_NAME AND r.RDB$VIEW_BLR IS NULL
+		WHERE d.RDB$DEPENDENT_TYPE = 1 AND d.RDB$DEPENDED_ON_TYPE = 0 AND d.RDB$FIELD_NAME IS NOT NULL
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]map[string][]string)
+	for rows.Next() {
+		var view, table, column string
+		if err := rows.Scan(&view, &table, &column); err != nil {
+			return nil, err
+		}
+		view, table = strings.TrimSpace(view), strings.TrimSpace(table)
+		if result[view] == nil {
+			result[view] = make(map[string][]string)
+		}
+		result[view][table] = append(result[view][table], strings.TrimSpace(column))
+	}
+	return result, rows.Err()
+}
+
+// procedureParameterColumns selects RDB$PROCEDURE_PARAMETERS in the order expected by parameterRow.dest().
+const procedureParameterColumns = `pp.RDB$PROCEDURE_NAME, pp.RDB$PARAMETER_NAME, pp.RDB$PARAMETER_TYPE, pp.RDB$FIELD_SOURCE, ` + fieldInfoColumns + `,
+	pp.RDB$NULL_FLAG, pp.RDB$DEFAULT_SOURCE, pp.RDB$PARAMETER_MECHANISM, pp.RDB$RELATION_NAME, pp.RDB$FIELD_NAME,
+	pp.RDB$COLLATION_ID, f.RDB$COLLATION_ID, co.RDB$COLLATION_NAME, pp.RDB$DESCRIPTION`
+
+const procedureParameterJoins = `
+	JOIN RDB$FIELDS f ON f.RDB$FIELD_NAME = pp.RDB$FIELD_SOURCE
+	LEFT JOIN RDB$CHARACTER_SETS cs ON cs.RDB$CHARACTER_SET_ID = f.RDB$CHARACTER_SET_ID
+	LEFT JOIN RDB$COLLATIONS co ON co.RDB$COLLATION_ID = pp.RDB$COLLATION_ID AND co.RDB$CHARACTER_SET_ID = f.RDB$CHARACTER_SET_ID`
+
+// loadProcedures loads the procedures of a package with their parameters; an empty package
+// loads standalone procedures. An empty procedure name loads all procedures.
+func loadProcedures(db *sql.DB, defaultCharset string, pkg string, procedure string) ([]domain.Routine, error) {
+	query := `
+		SELECT RDB$PROCEDURE_NAME, RDB$PROCEDURE_SOURCE, RDB$PROCEDURE_TYPE, RDB$ENGINE_NAME,
+			RDB$ENTRYPOINT, RDB$SQL_SECURITY, RDB$PRIVATE_FLAG, RDB$DESCRIPTION
+		FROM RDB$PROCEDURES
+		WHERE (RDB$SYSTEM_FLAG IS NULL OR RDB$SYSTEM_FLAG = 0)
+		AND COALESCE(RDB$PACKAGE_NAME, '') = ?
+		AND (CAST(? AS VARCHAR(63)) = '' OR RDB$PROCEDURE_NAME = ?)
+		ORDER BY RDB$PROCEDURE_NAME
+	`
+	rows, err := db.Query(query, pkg, procedure, procedure)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var procedures []domain.Routine
+	index := make(map[string]int)
+	for rows.Next() {
+		var name string
+		var source, engine, entryPoint, description sql.NullString
+		var procType, private sql.NullInt64
+		var security sql.NullBool
+		if err := rows.Scan(&name, &source, &procType, &engine, &entryPoint, &security, &private, &description); err != nil {
+			return nil, err
+		}
+		p := domain.Routine{
+			Name:        strings.TrimSpace(name),
+			Package:     pkg,
+			Private:     private.Valid && private.Int64 == 1,
+			Source:      strings.TrimSpace(source.String),
+			Kind:        routineKind(false, engine.String),
+			Selectable:  procedureSelectable(procType, source.String),
+			Engine:      strings.TrimSpace(engine.String),
+			EntryPoint:  strings.TrimSpace(entryPoint.String),
+			SQLSecurity: sqlSecurity(security),
+			Description: description.String,
+		}
+		index[p.Name] = len(procedures)
+		procedures = append(procedures, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	paramQuery := `
+		SELECT ` + procedureParameterColumns + `
+		FROM RDB$PROCEDURE_PARAMETERS pp` + procedureParameterJoins + `
+		WHERE COALESCE(pp.RDB$PACKAGE_NAME, '') = ?
+		AND (CAST(? AS VARCHAR(63)) = '' OR pp.RDB$PROCEDURE_NAME = ?)
+		ORDER BY pp.RDB$PROCEDURE_NAME, pp.RDB$PARAMETER_TYPE, pp.RDB$PARAMETER_NUMBER
+	`
+	paramRows, err := db.Query(paramQuery, pkg, procedure, procedure)
+	if err != nil {
+		return nil, err
+	}
+	defer paramRows.Close()
+
+	for paramRows.Next() {
+		var row parameterRow
+		if err := paramRows.Scan(row.dest()...); err != nil {
+			return nil, err
+		}
+		i, ok := index[strings.TrimSpace(row.routine)]
+		if !ok {
+			continue
+		}
+		// RDB$PARAMETER_TYPE: 0 = Input, 1 = Output
+		param := row.parameter(defaultCharset)
+		if row.order == 0 {
+			param.Direction = "IN"
+			procedures[i].Inputs = append(procedures[i].Inputs, param)
+		} else {
+			param.Direction = "OUT"
+			procedures[i].Outputs = append(procedures[i].Outputs, param)
+		}
+	}
+	return procedures, paramRows.Err()
+}
+
+// loadFunctions loads PSQL, UDR and legacy UDF functions with their arguments, either those
+// of a package or, for an empty package, standalone ones. An empty function name loads all functions.
+func loadFunctions(db *sql.DB, defaultCharset string, pkg string, function string) ([]domain.Routine, error) {
+	query := `
+		SELECT RDB$FUNCTION_NAME, RDB$FUNCTION_SOURCE, RDB$MODULE_NAME, RDB$ENTRYPOINT, RDB$ENGINE_NAME,
+			RDB$RETURN_ARGUMENT, RDB$LEGACY_FLAG, RDB$DETERMINISTIC_FLAG, RDB$SQL_SECURITY, RDB$PRIVATE_FLAG,
+			RDB$DESCRIPTION
+		FROM RDB$FUNCTIONS
+		WHERE (RDB$SYSTEM_FLAG IS NULL OR RDB$SYSTEM_FLAG = 0)
+		AND COALESCE(RDB$PACKAGE_NAME, '') = ?
+		AND (CAST(? AS VARCHAR(63)) = '' OR RDB$FUNCTION_NAME = ?)
+		ORDER BY RDB$FUNCTION_NAME
+	`
+	rows, err := db.Query(query, pkg, function, function)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var functions []domain.Routine
+	index := make(map[string]int)
+	returnArgs := make(map[string]int64)
+	for rows.Next() {
+		var name string
+		var source, module, entryPoint, engine, description sql.NullString
+		var returnArg, legacy, deterministic, private sql.NullInt64
+		var security sql.NullBool
+		if err := rows.Scan(&name, &source, &module, &entryPoint, &engine, &returnArg, &legacy, &deterministic, &security, &private, &description); err != nil {
+			return nil, err
+		}
+		f := domain.Routine{
+			Name:          strings.TrimSpace(name),
+			Package:       pkg,
+			Private:       private.Valid && private.Int64 == 1,
+			Source:        strings.TrimSpace(source.String),
+			Legacy:        legacy.Valid && legacy.Int64 == 1,
+			Kind:          routineKind(legacy.Valid && legacy.Int64 == 1, engine.String),
+			Deterministic: deterministic.Valid && deterministic.Int64 == 1,
+			ModuleName:    strings.TrimSpace(module.String),
+			EntryPoint:    strings.TrimSpace(entryPoint.String),
+			Engine:        strings.TrimSpace(engine.String),
+			SQLSecurity:   sqlSecurity(security),
+			Description:   description.String,
+		}
+		index[f.Name] = len(functions)
+		returnArgs[f.Name] = returnArg.Int64
+		functions = append(functions, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Legacy UDF arguments keep their type in RDB$FUNCTION_ARGUMENTS instead of a field source.
+	argQuery := `
+		SELECT a.RDB$FUNCTION_NAME, a.RDB$ARGUMENT_NAME, a.RDB$ARGUMENT_POSITION, a.RDB$FIELD_SOURCE,
+			COALESCE(f.RDB$FIELD_TYPE, a.RDB$FIELD_TYPE), COALESCE(f.RDB$FIELD_SUB_TYPE, a.RDB$FIELD_SUB_TYPE),
+			COALESCE(f.RDB$FIELD_LENGTH, a.RDB$FIELD_LENGTH), COALESCE(f.RDB$FIELD_PRECISION, a.RDB$FIELD_PRECISION),
+			COALESCE(f.RDB$FIELD_SCALE, a.RDB$FIELD_SCALE), COALESCE(f.RDB$CHARACTER_LENGTH, a.RDB$CHARACTER_LENGTH),
+			f.RDB$SEGMENT_LENGTH, cs.RDB$CHARACTER_SET_NAME,
+			a.RDB$NULL_FLAG, a.RDB$DEFAULT_SOURCE, a.RDB$ARGUMENT_MECHANISM, a.RDB$RELATION_NAME, a.RDB$FIELD_NAME,
+			a.RDB$COLLATION_ID, f.RDB$COLLATION_ID, co.RDB$COLLATION_NAME, a.RDB$DESCRIPTION
+		FROM RDB$FUNCTION_ARGUMENTS a
+		LEFT JOIN RDB$FIELDS f ON f.RDB$FIELD_NAME = a.RDB$FIELD_SOURCE
+		LEFT JOIN RDB$CHARACTER_SETS cs ON cs.RDB$CHARACTER_SET_ID = COALESCE(f.RDB$CHARACTER_SET_ID, a.RDB$CHARACTER_SET_ID)
+		LEFT JOIN RDB$COLLATIONS co ON co.RDB$COLLATION_ID = a.RDB$COLLATION_ID AND co.RDB$CHARACTER_SET_ID = cs.RDB$CHARACTER_SET_ID
+		WHERE COALESCE(a.RDB$PACKAGE_NAME, '') = ?
+		AND (CAST(? AS VARCHAR(63)) = '' OR a.RDB$FUNCTION_NAME = ?)
+		ORDER BY a.RDB$FUNCTION_NAME, a.RDB$ARGUMENT_POSITION
+	`
+	argRows, err := db.Query(argQuery, pkg, function, function)
+	if err != nil {
+		return nil, err
+	}
+	defer argRows.Close()
+
+	for argRows.Next() {
+		var row parameterRow
+		if err := argRows.Scan(row.dest()...); err != nil {
+			return nil, err
+		}
+		name := strings.TrimSpace(row.routine)
+		i, ok := index[name]
+		if !ok {
+			continue
+		}
+		fn := &functions[i]
+		param := row.parameter(defaultCharset)
+		param.Direction = "IN"
+		if fn.Legacy {
+			param.Type += legacyMechanism(row.mechanism.Int64)
+		}
+		if row.order == returnArgs[name] {
+			if fn.Legacy && row.order > 0 {
+				// The caller does not pass the argument the result is returned in.
+				fn.Returns = fmt.Sprintf("PARAMETER %d", row.order)
+				fn.ReturnType = param.DataType
+				param.Direction = "OUT"
+				fn.Inputs = append(fn.Inputs, param)
+				continue
+			}
+			fn.Returns = param.Type
+			fn.ReturnType = param.DataType
+			if param.NotNull {
+				fn.Returns += " NOT NULL"
+			}
+			if param.Collation != "" {
+				fn.Returns += " COLLATE " + param.Collation
+			}
+			continue
+		}
+		fn.Inputs = append(fn.Inputs, param)
+	}
+	return functions, argRows.Err()
+}
+
+// routineKind tells PSQL routines from external UDR and legacy UDF ones.
+func routineKind(legacy bool, engine string) string {
+	switch {
+	case legacy:
+		return "UDF"
+	case strings.TrimSpace(engine) != "":
+		return "UDR"
+	}
+	return "PSQL"
+}
+
+// legacyMechanism renders the passing mechanism of a legacy UDF argument.
+// Negative values mark a return value that must be released with FREE_IT.
+func legacyMechanism(mechanism int64) string {
+	suffix := ""
+	if mechanism < 0 {
+		suffix = " FREE_IT"
+		mechanism = -mechanism
+	}
+	switch mechanism {
+	case 0:
+		return " BY VALUE" + suffix
+	case 2:
+		return " BY DESCRIPTOR" + suffix
+	}
+	return suffix
+}
+
+// loadPackages loads package headers and bodies. An empty package name loads all packages.
+func loadPackages(db *sql.DB, pkg string) ([]domain.Package, error) {
+	query := `
+		SELECT RDB$PACKAGE_NAME, RDB$PACKAGE_HEADER_SOURCE, RDB$PACKAGE_BODY_SOURCE, RDB$VALID_BODY_FLAG,
+			RDB$SQL_SECURITY, RDB$DESCRIPTION
+		FROM RDB$PACKAGES
+		WHERE (RDB$SYSTEM_FLAG IS NULL OR RDB$SYSTEM_FLAG = 0)
+		AND (CAST(? AS VARCHAR(63)) = '' OR RDB$PACKAGE_NAME = ?)
+		ORDER BY RDB$PACKAGE_NAME
+	`
+	rows, err := db.Query(query, pkg, pkg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var packages []domain.Package
+	for rows.Next() {
+		var name string
+		var header, body, description sql.NullString
+		var validBody sql.NullInt64
+		var security sql.NullBool
+		if err := rows.Scan(&name, &header, &body, &validBody, &security, &description); err != nil {
+			return nil, err
+		}
+		packages = append(packages, domain.Package{
+			Name:        strings.TrimSpace(name),
+			Header:      strings.TrimSpace(header.String),
+			Body:        strings.TrimSpace(body.String),
+			ValidBody:   validBody.Valid && validBody.Int64 == 1,
+			SQLSecurity: sqlSecurity(security),
+			Description: description.String,
+		})
+	}
+	return packages, rows.Err()
+}
+
+// loadTriggers loads user triggers, skipping the system triggers that implement CHECK constraints.
+// An empty relation loads all triggers, including database and DDL triggers; a name loads
+// only that trigger.
+func loadTriggers(db *sql.DB, relation string, name string) ([]domain.Trigger, error) {
+	query := `
+		SELECT t.RDB$TRIGGER_NAME, t.RDB$RELATION_NAME, t.RDB$TRIGGER_SEQUENCE, t.RDB$TRIGGER_TYPE,
+			t.RDB$TRIGGER_SOURCE, t.RDB$TRIGGER_INACTIVE, t.RDB$ENGINE_NAME, t.RDB$ENTRYPOINT,
+			t.RDB$SQL_SECURITY, t.RDB$DESCRIPTION
+		FROM RDB$TRIGGERS t
+		WHERE (t.RDB$SYSTEM_FLAG IS NULL OR t.RDB$SYSTEM_FLAG = 0)
+		AND NOT EXISTS (SELECT 1 FROM RDB$CHECK_CONSTRAINTS cc WHERE cc.RDB$TRIGGER_NAME = t.RDB$TRIGGER_NAME)
+		AND (CAST(? AS VARCHAR(63)) = '' OR t.RDB$RELATION_NAME = ?)
+		AND (CAST(? AS VARCHAR(63)) = '' OR t.RDB$TRIGGER_NAME = ?)
+		ORDER BY t.RDB$RELATION_NAME, t.RDB$TRIGGER_TYPE, t.RDB$TRIGGER_SEQUENCE, t.RDB$TRIGGER_NAME
+	`
+	rows, err := db.Query(query, relation, relation, name, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var triggers []domain.Trigger
+	for rows.Next() {
+		var name string
+		var rel, source, engine, entryPoint, description sql.NullString
+		var sequence, triggerType, inactive sql.NullInt64
+		var security sql.NullBool
+		if err := rows.Scan(&name, &rel, &sequence, &triggerType, &source, &inactive, &engine, &entryPoint, &security, &description); err != nil {
+			return nil, err
+		}
+		kind, event := decodeTriggerType(triggerType.Int64)
+		triggers = append(triggers, domain.Trigger{
+			Name:        strings.TrimSpace(name),
+			Relation:    strings.TrimSpace(rel.String),
+			Kind:        kind,
+			Event:       event,
+			Position:    int(sequence.Int64),
+			Active:      !(inactive.Valid && inactive.Int64 == 1),
+			Source:      strings.TrimSpace(source.String),
+			Engine:      strings.TrimSpace(engine.String),
+			EntryPoint:  strings.TrimSpace(entryPoint.String),
+			SQLSecurity: sqlSecurity(security),
+			Description: description.String,
+		})
+	}
+	return triggers, rows.Err()
+}
+
+const (
+	triggerTypeDB  = 0x2000
+	triggerTypeDDL = 0x4000
+)
+
+// ddlTriggerEvents lists DDL trigger events by their bit position in RDB$TRIGGER_TYPE.
+// Empty entries are unused bits (13 and 14 overlap the DB/DDL trigger type flags).
+var ddlTriggerEvents = []string{
+	1: "CREATE TABLE", 2: "ALTER TABLE", 3: "DROP TABLE",
+	4: "CREATE PROCEDURE", 5: "ALTER PROCEDURE", 6: "DROP PROCEDURE",
+	7: "CREATE FUNCTION", 8: "ALTER FUNCTION", 9: "DROP FUNCTION",
+	10: "CREATE TRIGGER", 11: "ALTER TRIGGER", 12: "DROP TRIGGER",
+	16: "CREATE EXCEPTION", 17: "ALTER EXCEPTION", 18: "DROP EXCEPTION",
+	19: "CREATE VIEW", 20: "ALTER VIEW", 21: "DROP VIEW",
+	22: "CREATE DOMAIN", 23: "ALTER DOMAIN", 24: "DROP DOMAIN",
+	25: "CREATE ROLE", 26: "ALTER ROLE", 27: "DROP ROLE",
+	28: "CREATE INDEX", 29: "ALTER INDEX", 30: "DROP INDEX",
+	31: "CREATE SEQUENCE", 32: "ALTER SEQUENCE", 33: "DROP SEQUENCE",
+	34: "CREATE USER", 35: "ALTER USER", 36: "DROP USER",
+	37: "CREATE COLLATION", 38: "DROP COLLATION", 39: "ALTER CHARACTER SET",
+	40: "CREATE PACKAGE", 41: "ALTER PACKAGE", 42: "DROP PACKAGE",
+	43: "CREATE PACKAGE BODY", 44: "DROP PACKAGE BODY",
+	45: "CREATE MAPPING", 46: "ALTER MAPPING", 47: "DROP MAPPING",
+}
+
+var dbTriggerEvents = []string{"ON CONNECT", "ON DISCONNECT", "ON TRANSACTION START", "ON TRANSACTION COMMIT", "ON TRANSACTION ROLLBACK"}
+
+// decodeTriggerType decodes RDB$TRIGGER_TYPE into the trigger kind and its event clause.
+func decodeTriggerType(t int64) (kind string, event string) {
+	switch {
+	case t&triggerTypeDDL != 0:
+		phase := "BEFORE"
+		if t&1 == 1 {
+			phase = "AFTER"
+		}
+		var events []string
+		all := true
+		for bit, name := range ddlTriggerEvents {
+			if name == "" {
+				continue
+			}
+			if t&(int64(1)<<uint(bit)) != 0 {
+				events = append(events, name)
+			} else {
+				all = false
+			}
+		}
+		if all {
+			return "DDL", phase + " ANY DDL STATEMENT"
+		}
+		return "DDL", phase + " " + strings.Join(events, " OR ")
+	case t&triggerTypeDB != 0:
+		i := t &^ triggerTypeDB
+		if i >= 0 && int(i) < len(dbTriggerEvents) {
+			return "DATABASE", dbTriggerEvents[i]
+		}
+		return "DATABASE", fmt.Sprintf("TYPE_%d", t)
+	}
+
+	// Table triggers encode up to three actions in pairs of bits after the BEFORE/AFTER flag.
+	phase := "BEFORE"
+	if (t+1)&1 == 1 {
+		phase = "AFTER"
+	}
+	actions := []string{"", "INSERT", "UPDATE", "DELETE"}
+	var events []string
+	for slot := uint(1); slot <= 3; slot++ {
+		action := ((t + 1) >> (slot*2 - 1)) & 3
+		if action > 0 {
+			events = append(events, actions[action])
+		}
+	}
+	return "TABLE", phase + " " + strings.Join(events, " OR ")
+}
+
+// loadIndexes loads user indexes, including the ones backing constraints.
+// An empty relation loads indexes of all relations.
+func loadIndexes(db *sql.DB, relation string) ([]domain.Index, error) {
+	segments, err := loadIndexSegments(db)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT i.RDB$INDEX_NAME, i.RDB$RELATION_NAME, i.RDB$UNIQUE_FLAG, i.RDB$INDEX_TYPE, i.RDB$INDEX_INACTIVE,
+			i.RDB$STATISTICS, i.RDB$EXPRESSION_SOURCE, rc.RDB$CONSTRAINT_TYPE, i.RDB$DESCRIPTION
+		FROM RDB$INDICES i
+		LEFT JOIN RDB$RELATION_CONSTRAINTS rc ON rc.RDB$INDEX_NAME = i.RDB$INDEX_NAME
+		WHERE (i.RDB$SYSTEM_FLAG IS NULL OR i.RDB$SYSTEM_FLAG = 0)
+		AND (CAST(? AS VARCHAR(63)) = '' OR i.RDB$RELATION_NAME = ?)
+		ORDER BY i.RDB$RELATION_NAME, i.RDB$INDEX_NAME
+	`
+	rows, err := db.Query(query, relation, relation)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var indexes []domain.Index
+	for rows.Next() {
+		var name, rel string
+		var unique, indexType, inactive sql.NullInt64
+		var statistics sql.NullFloat64
+		var expression, constraint, description sql.NullString
+		if err := rows.Scan(&name, &rel, &unique, &indexType, &inactive, &statistics, &expression, &constraint, &description); err != nil {
+			return nil, err
+		}
+		name = strings.TrimSpace(name)
+		indexes = append(indexes, domain.Index{
+			Name:        name,
+			Relation:    strings.TrimSpace(rel),
+			Unique:      unique.Valid && unique.Int64 == 1,
+			Descending:  indexType.Valid && indexType.Int64 == 1,
+			Active:      !(inactive.Valid && inactive.Int64 == 1),
+			Segments:    segments[name],
+			Expression:  strings.TrimSpace(expression.String),
+			Constraint:  strings.TrimSpace(constraint.String),
+			Statistics:  statistics.Float64,
+			Description: description.String,
+		})
+	}
+	return indexes, rows.Err()
+}
+
+func loadRoles(db *sql.DB) ([]domain.Role, error) {
+	rows, err := db.Query(`
+		SELECT RDB$ROLE_NAME, RDB$OWNER_NAME, RDB$DESCRIPTION
+		FROM RDB$ROLES
+		WHERE (RDB$SYSTEM_FLAG IS NULL OR RDB$SYSTEM_FLAG = 0)
+		ORDER BY RDB$ROLE_NAME
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []domain.Role
+	for rows.Next() {
+		var name string
+		var owner, description sql.NullString
+		if err := rows.Scan(&name, &owner, &description); err != nil {
+			return nil, err
+		}
+		roles = append(roles, domain.Role{
+			Name:        strings.TrimSpace(name),
+			Owner:       strings.TrimSpace(owner.String),
+			Description: description.String,
+		})
+	}
+	return roles, rows.Err()
+}
+
+// loadGrants loads privileges granted by one user to another on user objects.
+// Implicit owner privileges (grantee = grantor) and privileges on system objects are skipped.
+func loadGrants(db *sql.DB) ([]domain.Grant, error) {
+	rows, err := db.Query(`
+		SELECT RDB$USER, RDB$USER_TYPE, RDB$GRANTOR, RDB$PRIVILEGE, RDB$GRANT_OPTION,
+			RDB$RELATION_NAME, RDB$OBJECT_TYPE, RDB$FIELD_NAME
+		FROM RDB$USER_PRIVILEGES
+		WHERE RDB$USER <> RDB$GRANTOR
+		ORDER BY RDB$RELATION_NAME, RDB$USER, RDB$PRIVILEGE, RDB$FIELD_NAME
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grants []domain.Grant
+	for rows.Next() {
+		var user, grantor, privilege, object string
+		var userType, grantOption, objectType sql.NullInt64
+		var field sql.NullString
+		if err := rows.Scan(&user, &userType, &grantor, &privilege, &grantOption, &object, &objectType, &field); err != nil {
+			return nil, err
+		}
+		g := domain.Grant{
+			Grantee:     strings.TrimSpace(user),
+			GranteeType: int(userType.Int64),
+			Grantor:     strings.TrimSpace(grantor),
+			Privilege:   strings.TrimSpace(privilege),
+			GrantOption: int(grantOption.Int64),
+			Object:      strings.TrimSpace(object),
+			ObjectType:  int(objectType.Int64),
+			Field:       strings.TrimSpace(field.String),
+		}
+		if g.Privilege != "M" && isSystemObjectName(g.Object) {
+			continue
+		}
+		grants = append(grants, g)
+	}
+	return grants, rows.Err()
+}
+
+// dependencyTypeNames maps RDB$DEPENDENCIES object types to names.
+var dependencyTypeNames = map[int64]string{
+	0: "TABLE", 1: "VIEW", 2: "TRIGGER", 3: "COMPUTED COLUMN", 4: "CHECK", 5: "PROCEDURE",
+	6: "INDEX", 7: "EXCEPTION", 8: "USER", 9: "DOMAIN", 10: "INDEX", 14: "SEQUENCE",
+	15: "FUNCTION", 17: "COLLATION", 18: "PACKAGE", 19: "PACKAGE BODY",
+}
+
+func dependencyTypeName(t int64) string {
+	if name, ok := dependencyTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("TYPE_%d", t)
+}
+
+func isSystemObjectName(name string) bool {
+	for _, prefix := range []string{"RDB$", "MON$", "SEC$"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// sqlSecurity renders RDB$SQL_SECURITY (NULL = not set, TRUE = DEFINER, FALSE = INVOKER).
+func sqlSecurity(v sql.NullBool) string {
+	if !v.Valid {
+		return ""
+	}
+	if v.Bool {
+		return "DEFINER"
+	}
+	return "INVOKER"
+}
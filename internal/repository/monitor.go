@@ -0,0 +1,331 @@
+package repository
+
+import (
+	"database/sql"
+	"firebird-web-admin/internal/domain"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// monitorIOColumns selects the I/O and memory counters joined as io and mem.
+const monitorIOColumns = `
+	COALESCE(io.MON$PAGE_READS, 0), COALESCE(io.MON$PAGE_WRITES, 0), COALESCE(io.MON$PAGE_FETCHES, 0),
+	COALESCE(io.MON$PAGE_MARKS, 0), COALESCE(mem.MON$MEMORY_USED, 0), COALESCE(mem.MON$MEMORY_ALLOCATED, 0)`
+
+func ioDest(s *domain.IOStats) []interface{} {
+	return []interface{}{&s.Reads, &s.Writes, &s.Fetches, &s.Marks, &s.MemoryUsed, &s.MemoryAllocated}
+}
+
+// isolationModes maps MON$TRANSACTIONS.MON$ISOLATION_MODE to its SQL name.
+var isolationModes = map[int64]string{
+	0: "SNAPSHOT TABLE STABILITY",
+	1: "SNAPSHOT",
+	2: "READ COMMITTED RECORD_VERSION",
+	3: "READ COMMITTED NO RECORD_VERSION",
+	4: "READ COMMITTED READ CONSISTENCY",
+}
+
+func monitorState(state int64) string {
+	switch state {
+	case 1:
+		return "active"
+	case 2:
+		return "stalled"
+	}
+	return "idle"
+}
+
+// GetMonitorOverview returns the transaction markers of the database, its counters and the
+// oldest open transaction, idle or not, which holds back garbage collection.
+func (r *FirebirdRepository) GetMonitorOverview(params domain.ConnectionParams) (*domain.MonitorOverview, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	o := &domain.MonitorOverview{}
+	dest := []interface{}{&o.Database, &o.OldestTransaction, &o.OldestActive, &o.OldestSnapshot, &o.NextTransaction,
+		&o.PageBuffers, &o.SweepInterval, &o.Attachments, &o.ActiveStatements}
+	err = db.QueryRow(`
+		SELECT d.MON$DATABASE_NAME, d.MON$OLDEST_TRANSACTION, d.MON$OLDEST_ACTIVE, d.MON$OLDEST_SNAPSHOT,
+			d.MON$NEXT_TRANSACTION, d.MON$PAGE_BUFFERS, d.MON$SWEEP_INTERVAL,
+			(SELECT COUNT(*) FROM MON$ATTACHMENTS a WHERE a.MON$SYSTEM_FLAG = 0),
+			(SELECT COUNT(*) FROM MON$STATEMENTS s WHERE s.MON$STATE <> 0 AND s.MON$ATTACHMENT_ID <> CURRENT_CONNECTION),
+			` + monitorIOColumns + `
+		FROM MON$DATABASE d
+		LEFT JOIN MON$IO_STATS io ON io.MON$STAT_ID = d.MON$STAT_ID
+		LEFT JOIN MON$MEMORY_USAGE mem ON mem.MON$STAT_ID = d.MON$STAT_ID
+	`).Scan(append(dest, ioDest(&o.IO)...)...)
+	if err != nil {
+		log.Printf("GetMonitorOverview error: %v", err)
+		return nil, err
+	}
+	o.Database = strings.TrimSpace(o.Database)
+	o.GarbageGap = o.NextTransaction - o.OldestSnapshot
+
+	txs, err := loadMonitoredTransactions(db, "ORDER BY t.MON$TRANSACTION_ID ROWS 1")
+	if err != nil {
+		log.Printf("GetMonitorOverview transactions error: %v", err)
+		return nil, err
+	}
+	if len(txs) > 0 {
+		o.OldestActiveTx = &txs[0]
+	}
+	return o, nil
+}
+
+// ListAttachments returns the user attachments; users without administrator rights only
+// see their own.
+func (r *FirebirdRepository) ListAttachments(params domain.ConnectionParams) ([]domain.Attachment, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		SELECT a.MON$ATTACHMENT_ID, a.MON$USER, a.MON$ROLE, a.MON$STATE, a.MON$REMOTE_PROTOCOL, a.MON$REMOTE_ADDRESS,
+			a.MON$REMOTE_HOST, a.MON$REMOTE_OS_USER, a.MON$REMOTE_PROCESS, a.MON$REMOTE_PID, a.MON$CLIENT_VERSION,
+			a.MON$AUTH_METHOD, a.MON$TIMESTAMP,
+			CASE WHEN a.MON$ATTACHMENT_ID = CURRENT_CONNECTION THEN 1 ELSE 0 END,
+			` + monitorIOColumns + `
+		FROM MON$ATTACHMENTS a
+		LEFT JOIN MON$IO_STATS io ON io.MON$STAT_ID = a.MON$STAT_ID
+		LEFT JOIN MON$MEMORY_USAGE mem ON mem.MON$STAT_ID = a.MON$STAT_ID
+		WHERE a.MON$SYSTEM_FLAG = 0
+		ORDER BY a.MON$ATTACHMENT_ID
+	`)
+	if err != nil {
+		log.Printf("ListAttachments error: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := []domain.Attachment{}
+	for rows.Next() {
+		var a domain.Attachment
+		var user, role, protocol, address, host, osUser, process, clientVersion, authMethod sql.NullString
+		var state, current int64
+		var pid sql.NullInt64
+		dest := []interface{}{&a.ID, &user, &role, &state, &protocol, &address, &host, &osUser, &process, &pid,
+			&clientVersion, &authMethod, &a.Started, &current}
+		if err := rows.Scan(append(dest, ioDest(&a.IO)...)...); err != nil {
+			return nil, err
+		}
+		a.User = strings.TrimSpace(user.String)
+		a.Role = strings.TrimSpace(role.String)
+		if a.Role == "NONE" {
+			a.Role = ""
+		}
+		a.State = monitorState(state)
+		a.RemoteProtocol = strings.TrimSpace(protocol.String)
+		a.RemoteAddress = strings.TrimSpace(address.String)
+		a.RemoteHost = strings.TrimSpace(host.String)
+		a.RemoteOSUser = strings.TrimSpace(osUser.String)
+		a.RemoteProcess = strings.TrimSpace(process.String)
+		a.RemotePID = pid.Int64
+		a.ClientVersion = strings.TrimSpace(clientVersion.String)
+		a.AuthMethod = strings.TrimSpace(authMethod.String)
+		a.Current = current == 1
+		attachments = append(attachments, a)
+	}
+	return attachments, rows.Err()
+}
+
+func (r *FirebirdRepository) ListMonitoredTransactions(params domain.ConnectionParams) ([]domain.MonitoredTransaction, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	txs, err := loadMonitoredTransactions(db, "ORDER BY t.MON$TRANSACTION_ID")
+	if err != nil {
+		log.Printf("ListMonitoredTransactions error: %v", err)
+		return nil, err
+	}
+	return txs, nil
+}
+
+// loadMonitoredTransactions loads the transactions of other attachments; tail filters and
+// orders them.
+func loadMonitoredTransactions(db *sql.DB, tail string) ([]domain.MonitoredTransaction, error) {
+	rows, err := db.Query(`
+		SELECT t.MON$TRANSACTION_ID, t.MON$ATTACHMENT_ID, a.MON$USER, a.MON$REMOTE_ADDRESS, a.MON$REMOTE_PROCESS,
+			t.MON$STATE, t.MON$ISOLATION_MODE, t.MON$LOCK_TIMEOUT, t.MON$READ_ONLY, t.MON$AUTO_COMMIT, t.MON$TIMESTAMP,
+			DATEDIFF(MILLISECOND FROM t.MON$TIMESTAMP TO CURRENT_TIMESTAMP),
+			` + monitorIOColumns + `
+		FROM MON$TRANSACTIONS t
+		JOIN MON$ATTACHMENTS a ON a.MON$ATTACHMENT_ID = t.MON$ATTACHMENT_ID
+		LEFT JOIN MON$IO_STATS io ON io.MON$STAT_ID = t.MON$STAT_ID
+		LEFT JOIN MON$MEMORY_USAGE mem ON mem.MON$STAT_ID = t.MON$STAT_ID
+		WHERE t.MON$ATTACHMENT_ID <> CURRENT_CONNECTION AND a.MON$SYSTEM_FLAG = 0
+		` + tail)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	txs := []domain.MonitoredTransaction{}
+	for rows.Next() {
+		var t domain.MonitoredTransaction
+		var user, address, process sql.NullString
+		var state, isolation, readOnly, autoCommit int64
+		dest := []interface{}{&t.ID, &t.AttachmentID, &user, &address, &process, &state, &isolation, &t.LockTimeout,
+			&readOnly, &autoCommit, &t.Started, &t.DurationMs}
+		if err := rows.Scan(append(dest, ioDest(&t.IO)...)...); err != nil {
+			return nil, err
+		}
+		t.User = strings.TrimSpace(user.String)
+		t.RemoteAddress = strings.TrimSpace(address.String)
+		t.RemoteProcess = strings.TrimSpace(process.String)
+		t.State = monitorState(state)
+		t.Isolation = isolationModes[isolation]
+		t.ReadOnly = readOnly == 1
+		t.AutoCommit = autoCommit == 1
+		txs = append(txs, t)
+	}
+	return txs, rows.Err()
+}
+
+// ListMonitoredStatements returns the statements of other attachments, only the running
+// ones when activeOnly is set, the longest running first.
+func (r *FirebirdRepository) ListMonitoredStatements(params domain.ConnectionParams, activeOnly bool) ([]domain.MonitoredStatement, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		SELECT s.MON$STATEMENT_ID, s.MON$ATTACHMENT_ID, s.MON$TRANSACTION_ID, a.MON$USER, a.MON$REMOTE_ADDRESS,
+			s.MON$STATE, s.MON$SQL_TEXT, s.MON$EXPLAINED_PLAN, s.MON$TIMESTAMP,
+			CASE WHEN s.MON$STATE = 0 THEN 0 ELSE DATEDIFF(MILLISECOND FROM s.MON$TIMESTAMP TO CURRENT_TIMESTAMP) END,
+			`+monitorIOColumns+`
+		FROM MON$STATEMENTS s
+		JOIN MON$ATTACHMENTS a ON a.MON$ATTACHMENT_ID = s.MON$ATTACHMENT_ID
+		LEFT JOIN MON$IO_STATS io ON io.MON$STAT_ID = s.MON$STAT_ID
+		LEFT JOIN MON$MEMORY_USAGE mem ON mem.MON$STAT_ID = s.MON$STAT_ID
+		WHERE s.MON$ATTACHMENT_ID <> CURRENT_CONNECTION AND a.MON$SYSTEM_FLAG = 0
+		AND (CAST(? AS INTEGER) = 0 OR s.MON$STATE <> 0)
+		ORDER BY s.MON$STATE DESC, s.MON$TIMESTAMP
+	`, boolToInt(activeOnly))
+	if err != nil {
+		log.Printf("ListMonitoredStatements error: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	statements := []domain.MonitoredStatement{}
+	for rows.Next() {
+		var s domain.MonitoredStatement
+		var transaction sql.NullInt64
+		var user, address, text, plan sql.NullString
+		var state int64
+		dest := []interface{}{&s.ID, &s.AttachmentID, &transaction, &user, &address, &state, &text, &plan, &s.Started, &s.DurationMs}
+		if err := rows.Scan(append(dest, ioDest(&s.IO)...)...); err != nil {
+			return nil, err
+		}
+		s.TransactionID = transaction.Int64
+		s.User = strings.TrimSpace(user.String)
+		s.RemoteAddress = strings.TrimSpace(address.String)
+		s.State = monitorState(state)
+		s.SQL = text.String
+		s.ExplainedPlan = plan.String
+		statements = append(statements, s)
+	}
+	return statements, rows.Err()
+}
+
+// KillAttachment disconnects an attachment by deleting it from MON$ATTACHMENTS.
+func (r *FirebirdRepository) KillAttachment(params domain.ConnectionParams, id int64) error {
+	return r.killMonitored(params, "attachment", id,
+		"SELECT a.MON$USER FROM MON$ATTACHMENTS a WHERE a.MON$ATTACHMENT_ID = ? AND a.MON$SYSTEM_FLAG = 0",
+		"DELETE FROM MON$ATTACHMENTS WHERE MON$ATTACHMENT_ID = ?")
+}
+
+// CancelStatement cancels a running statement by deleting it from MON$STATEMENTS. The
+// attachment stays connected.
+func (r *FirebirdRepository) CancelStatement(params domain.ConnectionParams, id int64) error {
+	return r.killMonitored(params, "statement", id, `
+		SELECT a.MON$USER FROM MON$STATEMENTS s
+		JOIN MON$ATTACHMENTS a ON a.MON$ATTACHMENT_ID = s.MON$ATTACHMENT_ID
+		WHERE s.MON$STATEMENT_ID = ?`,
+		"DELETE FROM MON$STATEMENTS WHERE MON$STATEMENT_ID = ?")
+}
+
+// killMonitored looks up the owner of a monitored object, checks that the connected user may
+// end it and deletes it.
+func (r *FirebirdRepository) killMonitored(params domain.ConnectionParams, kind string, id int64, ownerQuery, deleteStmt string) error {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var owner sql.NullString
+	if err := db.QueryRow(ownerQuery, id).Scan(&owner); err == sql.ErrNoRows {
+		return &domain.NotFoundError{ObjectType: kind, Name: strconv.FormatInt(id, 10)}
+	} else if err != nil {
+		return err
+	}
+
+	var user, role, dbOwner sql.NullString
+	err = db.QueryRow(`
+		SELECT CURRENT_USER, CURRENT_ROLE, (SELECT RDB$OWNER_NAME FROM RDB$RELATIONS WHERE RDB$RELATION_NAME = 'RDB$DATABASE')
+		FROM RDB$DATABASE
+	`).Scan(&user, &role, &dbOwner)
+	if err != nil {
+		return err
+	}
+	if err := checkKillAllowed(kind, strings.TrimSpace(user.String), strings.TrimSpace(role.String),
+		strings.TrimSpace(dbOwner.String), strings.TrimSpace(owner.String)); err != nil {
+		return err
+	}
+
+	log.Printf("Kill %s %d of %s", kind, id, strings.TrimSpace(owner.String))
+	if _, err := db.Exec(deleteStmt, id); err != nil {
+		log.Printf("Kill %s error: %v", kind, err)
+		return err
+	}
+	return nil
+}
+
+// checkKillAllowed lets SYSDBA, the database owner and RDB$ADMIN end any attachment or
+// statement and other users only their own, as Firebird itself does.
+func checkKillAllowed(kind, user, role, dbOwner, owner string) error {
+	if user == "SYSDBA" || user == dbOwner || role == "RDB$ADMIN" || user == owner {
+		return nil
+	}
+	return &domain.PermissionError{Message: fmt.Sprintf("%s belongs to %s; only its owner or an administrator can end it", kind, owner)}
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,29 @@
+package repository
+
+import "testing"
+
+func TestCheckKillAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    string
+		role    string
+		dbOwner string
+		owner   string
+		allowed bool
+	}{
+		{name: "SYSDBA", user: "SYSDBA", dbOwner: "ADMIN", owner: "ALICE", allowed: true},
+		{name: "Database owner", user: "ADMIN", dbOwner: "ADMIN", owner: "ALICE", allowed: true},
+		{name: "RDB$ADMIN role", user: "BOB", role: "RDB$ADMIN", dbOwner: "ADMIN", owner: "ALICE", allowed: true},
+		{name: "Own attachment", user: "ALICE", role: "NONE", dbOwner: "ADMIN", owner: "ALICE", allowed: true},
+		{name: "Other user", user: "BOB", role: "NONE", dbOwner: "ADMIN", owner: "ALICE", allowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkKillAllowed("attachment", tt.user, tt.role, tt.dbOwner, tt.owner)
+			if (err == nil) != tt.allowed {
+				t.Errorf("checkKillAllowed() error = %v, allowed %v", err, tt.allowed)
+			}
+		})
+	}
+}
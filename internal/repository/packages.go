@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"database/sql"
+	"firebird-web-admin/internal/domain"
+	"log"
+	"regexp"
+)
+
+// packageHeader and packageBody match the start of a statement that defines a package
+// header or body and capture the package name. packageHeader also matches a body, taking
+// BODY for the name, so anyBody is checked first.
+var (
+	packageHeader = regexp.MustCompile(`(?is)^\s*(?:CREATE\s+OR\s+ALTER|CREATE|ALTER|RECREATE)\s+PACKAGE\s+("(?:[^"]|"")+"|[A-Z][A-Z0-9_$]*)`)
+	packageBody   = regexp.MustCompile(`(?is)^\s*(?:CREATE|RECREATE)\s+PACKAGE\s+BODY\s+("(?:[^"]|"")+"|[A-Z][A-Z0-9_$]*)`)
+	anyBody       = regexp.MustCompile(`(?is)^\s*(?:CREATE\s+OR\s+ALTER|CREATE|ALTER|RECREATE)\s+PACKAGE\s+BODY(?:\s|$)`)
+)
+
+// ListPackages returns packages without their header and body source.
+func (r *FirebirdRepository) ListPackages(params domain.ConnectionParams) ([]domain.Package, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	packages, err := loadPackages(db, "")
+	if err != nil {
+		log.Printf("ListPackages error: %v", err)
+		return nil, err
+	}
+	for i := range packages {
+		packages[i].Header = ""
+		packages[i].Body = ""
+	}
+	return packages, nil
+}
+
+// GetPackage returns a package with its header and body source and its member procedures
+// and functions, including the private ones declared only in the body.
+func (r *FirebirdRepository) GetPackage(params domain.ConnectionParams, name string) (*domain.Package, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	packages, err := loadPackages(db, objectName(name))
+	if err != nil {
+		log.Printf("GetPackage error: %v", err)
+		return nil, err
+	}
+	if len(packages) == 0 {
+		return nil, &domain.NotFoundError{ObjectType: "package", Name: name}
+	}
+	pkg := &packages[0]
+
+	charset, err := loadDefaultCharset(db)
+	if err != nil {
+		return nil, err
+	}
+	if pkg.Procedures, err = loadProcedures(db, charset, pkg.Name, ""); err != nil {
+		log.Printf("GetPackage procedures error: %v", err)
+		return nil, err
+	}
+	if pkg.Functions, err = loadFunctions(db, charset, pkg.Name, ""); err != nil {
+		log.Printf("GetPackage functions error: %v", err)
+		return nil, err
+	}
+	return pkg, nil
+}
+
+// GetPackageSource returns the CREATE OR ALTER PACKAGE statement of the header and the
+// RECREATE PACKAGE BODY statement of the body, which is empty for a package without a body.
+func (r *FirebirdRepository) GetPackageSource(params domain.ConnectionParams, name string) (string, string, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return "", "", err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return "", "", err
+	}
+	defer db.Close()
+
+	packages, err := loadPackages(db, objectName(name))
+	if err != nil {
+		log.Printf("GetPackageSource error: %v", err)
+		return "", "", err
+	}
+	if len(packages) == 0 {
+		return "", "", &domain.NotFoundError{ObjectType: "package", Name: name}
+	}
+	body := ""
+	if packages[0].Body != "" {
+		body = packageBodySQL(packages[0])
+	}
+	return packageHeaderSQL(packages[0]), body, nil
+}
+
+// CompilePackageHeader executes a CREATE [OR ALTER] / ALTER / RECREATE PACKAGE statement.
+// Firebird keeps the body of an altered header but it must be recompiled before use.
+func (r *FirebirdRepository) CompilePackageHeader(params domain.ConnectionParams, name string, source string) error {
+	if anyBody.MatchString(source) {
+		return invalidf("the statement defines a package body, not a package header")
+	}
+	return r.compileObject(params, packageHeader, "package", name, source)
+}
+
+// CompilePackageBody executes a CREATE / RECREATE PACKAGE BODY statement.
+func (r *FirebirdRepository) CompilePackageBody(params domain.ConnectionParams, name string, source string) error {
+	return r.compileObject(params, packageBody, "package body", name, source)
+}
+
+// ExecutePackageProcedure executes PKG.PROC like ExecuteProcedure executes a standalone procedure.
+func (r *FirebirdRepository) ExecutePackageProcedure(params domain.ConnectionParams, pkg string, procName string, inputParams map[string]interface{}, selectable *bool) ([]map[string]interface{}, []domain.Column, error) {
+	return r.executeProcedure(params, pkg, procName, inputParams, selectable)
+}
+
+// ExecutePackageFunction evaluates PKG.FUNC like ExecuteFunction evaluates a standalone function.
+func (r *FirebirdRepository) ExecutePackageFunction(params domain.ConnectionParams, pkg string, name string, args map[string]interface{}) ([]map[string]interface{}, []domain.Column, error) {
+	return r.executeFunction(params, pkg, name, args)
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"errors"
+	"firebird-web-admin/internal/domain"
+	"testing"
+)
+
+func TestPackageStatementName(t *testing.T) {
+	tests := []struct {
+		name     string
+		stmt     string
+		body     bool
+		expected string
+		ok       bool
+	}{
+		{name: "Header", stmt: "CREATE OR ALTER PACKAGE app_utils\nAS BEGIN END", expected: "APP_UTILS", ok: true},
+		{name: "Recreate header", stmt: "RECREATE PACKAGE \"Utils\"\nAS BEGIN END", expected: "Utils", ok: true},
+		{name: "Body", stmt: "RECREATE PACKAGE BODY APP_UTILS\nAS BEGIN END", body: true, expected: "APP_UTILS", ok: true},
+		{name: "Create body", stmt: "\ncreate package body app_utils as begin end", body: true, expected: "APP_UTILS", ok: true},
+		{name: "Header is not a body", stmt: "CREATE OR ALTER PACKAGE APP_UTILS AS BEGIN END", body: true},
+		{name: "Alter body is not supported", stmt: "ALTER PACKAGE BODY APP_UTILS AS BEGIN END", body: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := packageHeader
+			if tt.body {
+				header = packageBody
+			}
+			got, ok := statementObjectName(header, tt.stmt)
+			if ok != tt.ok || got != tt.expected {
+				t.Errorf("statementObjectName() = %q, %v, want %q, %v", got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCompilePackageHeaderRejectsBody(t *testing.T) {
+	r := NewFirebirdRepository()
+	for _, source := range []string{"CREATE PACKAGE BODY APP_UTILS AS BEGIN END", "recreate package body\nAPP_UTILS as begin end"} {
+		err := r.CompilePackageHeader(domain.ConnectionParams{}, "APP_UTILS", source)
+		var invalid *domain.ValidationError
+		if !errors.As(err, &invalid) || err.Error() != "the statement defines a package body, not a package header" {
+			t.Errorf("CompilePackageHeader(%q) error = %v", source, err)
+		}
+	}
+}
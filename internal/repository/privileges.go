@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"database/sql"
+	"firebird-web-admin/internal/domain"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// privilegeObject describes an object type of the privilege matrix: the RDB$OBJECT_TYPE
+// values its grants have and the privileges it takes.
+type privilegeObject struct {
+	types      []int
+	privileges string
+}
+
+var privilegeObjects = map[string]privilegeObject{
+	"table":     {types: []int{objRelation}, privileges: "SIUDR"},
+	"view":      {types: []int{objRelation, objView}, privileges: "SIUDR"},
+	"procedure": {types: []int{objProcedure}, privileges: "X"},
+	"function":  {types: []int{objFunction}, privileges: "X"},
+	"package":   {types: []int{objPackage}, privileges: "X"},
+}
+
+// privilegeOrder is the column order of the matrix.
+const privilegeOrder = "SIUDRX"
+
+var granteeTypeNames = map[int]string{
+	objUser: "USER", objRole: "ROLE", objProcedure: "PROCEDURE", objTrigger: "TRIGGER",
+	objFunction: "FUNCTION", objPackage: "PACKAGE", objView: "VIEW",
+}
+
+// privilegeKey identifies one cell of the matrix.
+type privilegeKey struct {
+	granteeType int
+	grantee     string
+	privilege   string
+	column      string
+}
+
+// GetPrivilegeMatrix returns the explicit privileges on a table, view or routine per
+// grantee. Privileges the owner has implicitly are not listed.
+func (r *FirebirdRepository) GetPrivilegeMatrix(params domain.ConnectionParams, objectType string, name string) (*domain.PrivilegeMatrix, error) {
+	name = objectName(name)
+	cells, err := r.loadPrivilegeCells(params, objectType, name)
+	if err != nil {
+		return nil, err
+	}
+	return privilegeMatrix(strings.ToLower(objectType), name, cells), nil
+}
+
+// ApplyPrivilegeMatrix grants and revokes privileges until the object has exactly those of
+// desired. Grantees missing from desired lose all their privileges on the object. With
+// dryRun the statements are only returned.
+func (r *FirebirdRepository) ApplyPrivilegeMatrix(params domain.ConnectionParams, objectType string, name string, desired domain.PrivilegeMatrix, dryRun bool) (*domain.PrivilegeChanges, error) {
+	name = objectName(name)
+	cells, err := r.loadPrivilegeCells(params, objectType, name)
+	if err != nil {
+		return nil, err
+	}
+	stmts, err := privilegeDiff(name, privilegeObjects[strings.ToLower(objectType)], cells, desired)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.PrivilegeChanges{Statements: stmts}
+	if dryRun {
+		return result, nil
+	}
+	for i, stmt := range stmts {
+		if err := r.execDDL(params, stmt); err != nil {
+			if i > 0 {
+				log.Printf("ApplyPrivilegeMatrix: statement %d of %d failed, the previous ones were applied", i+1, len(stmts))
+			}
+			return nil, err
+		}
+	}
+	result.Executed = true
+	return result, nil
+}
+
+func (r *FirebirdRepository) loadPrivilegeCells(params domain.ConnectionParams, objectType string, name string) (map[privilegeKey]bool, error) {
+	o, ok := privilegeObjects[strings.ToLower(objectType)]
+	if !ok {
+		return nil, invalidf("unknown object type %q, use table, view, procedure, function or package", objectType)
+	}
+
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	grants, err := loadGrants(db)
+	if err != nil {
+		log.Printf("loadPrivilegeCells error: %v", err)
+		return nil, err
+	}
+	return privilegeCells(name, o, grants), nil
+}
+
+// privilegeCells maps the privileges on an object to their grant option. A privilege
+// granted by several users is held with the grant option when any of them gave it.
+func privilegeCells(name string, o privilegeObject, grants []domain.Grant) map[privilegeKey]bool {
+	cells := make(map[privilegeKey]bool)
+	for _, g := range grants {
+		if g.Object != name || !slices.Contains(o.types, g.ObjectType) || !strings.Contains(o.privileges, g.Privilege) {
+			continue
+		}
+		k := privilegeKey{granteeType: g.GranteeType, grantee: g.Grantee, privilege: g.Privilege}
+		if g.Privilege == "U" || g.Privilege == "R" {
+			k.column = g.Field
+		}
+		cells[k] = cells[k] || g.GrantOption > 0
+	}
+	return cells
+}
+
+// privilegeMatrix groups privilege cells by grantee.
+func privilegeMatrix(objectType, name string, cells map[privilegeKey]bool) *domain.PrivilegeMatrix {
+	m := &domain.PrivilegeMatrix{ObjectType: objectType, Object: name, Grantees: []domain.GranteePrivileges{}}
+	for _, k := range sortedPrivilegeKeys(cells) {
+		typeName := granteeTypeNames[k.granteeType]
+		if n := len(m.Grantees); n == 0 || m.Grantees[n-1].Grantee != k.grantee || m.Grantees[n-1].GranteeType != typeName {
+			m.Grantees = append(m.Grantees, domain.GranteePrivileges{Grantee: k.grantee, GranteeType: typeName})
+		}
+		row := &m.Grantees[len(m.Grantees)-1]
+		row.Privileges = append(row.Privileges, domain.ObjectPrivilege{Privilege: k.privilege, Column: k.column, GrantOption: cells[k]})
+	}
+	return m
+}
+
+// privilegeDiff returns the REVOKE statements for privileges to remove, then the GRANT
+// statements for privileges to add. A changed grant option is granted or revoked alone.
+func privilegeDiff(name string, o privilegeObject, have map[privilegeKey]bool, desired domain.PrivilegeMatrix) ([]string, error) {
+	want := make(map[privilegeKey]bool)
+	for _, row := range desired.Grantees {
+		if strings.TrimSpace(row.Grantee) == "" {
+			return nil, invalidf("grantee name is required")
+		}
+		granteeType := objUser
+		if row.GranteeType != "" {
+			var ok bool
+			if granteeType, ok = granteeTypeCode(row.GranteeType); !ok {
+				return nil, invalidf("unknown grantee type %q", row.GranteeType)
+			}
+		}
+		for _, p := range row.Privileges {
+			g := normalizeGrant(domain.Grant{Privilege: p.Privilege})
+			if len(g.Privilege) != 1 || !strings.Contains(o.privileges, g.Privilege) {
+				return nil, invalidf("privilege %q does not apply to %s", p.Privilege, name)
+			}
+			if p.Column != "" && g.Privilege != "U" && g.Privilege != "R" {
+				return nil, invalidf("only UPDATE and REFERENCES can be granted on columns")
+			}
+			k := privilegeKey{granteeType: granteeType, grantee: row.Grantee, privilege: g.Privilege, column: p.Column}
+			want[k] = want[k] || p.GrantOption
+		}
+	}
+
+	grant := func(k privilegeKey, option bool) domain.Grant {
+		g := domain.Grant{Grantee: k.grantee, GranteeType: k.granteeType, Privilege: k.privilege,
+			Object: name, ObjectType: o.types[0], Field: k.column}
+		if option {
+			g.GrantOption = 1
+		}
+		return g
+	}
+
+	stmts := []string{}
+	for _, k := range sortedPrivilegeKeys(have) {
+		option, keep := want[k]
+		switch {
+		case !keep:
+			stmts = append(stmts, revokeSQL(grant(k, false)))
+		case have[k] && !option:
+			clause, _ := privilegeClause(grant(k, false))
+			stmts = append(stmts, fmt.Sprintf("REVOKE GRANT OPTION FOR %s FROM %s", clause, granteeSQL(k.grantee, k.granteeType)))
+		}
+	}
+	for _, k := range sortedPrivilegeKeys(want) {
+		option, held := have[k]
+		if !held || (want[k] && !option) {
+			stmts = append(stmts, grantSQL(grant(k, want[k])))
+		}
+	}
+	return stmts, nil
+}
+
+func granteeTypeCode(name string) (int, bool) {
+	for code, n := range granteeTypeNames {
+		if strings.EqualFold(n, name) {
+			return code, true
+		}
+	}
+	return 0, false
+}
+
+// sortedPrivilegeKeys orders cells by grantee type and name, then in matrix column order.
+func sortedPrivilegeKeys(cells map[privilegeKey]bool) []privilegeKey {
+	keys := make([]privilegeKey, 0, len(cells))
+	for k := range cells {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if ta, tb := granteeTypeNames[a.granteeType], granteeTypeNames[b.granteeType]; ta != tb {
+			return ta < tb
+		}
+		if a.grantee != b.grantee {
+			return a.grantee < b.grantee
+		}
+		if a.privilege != b.privilege {
+			return strings.Index(privilegeOrder, a.privilege) < strings.Index(privilegeOrder, b.privilege)
+		}
+		return a.column < b.column
+	})
+	return keys
+}
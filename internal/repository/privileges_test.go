@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"errors"
+	"firebird-web-admin/internal/domain"
+	"reflect"
+	"testing"
+)
+
+func TestPrivilegeMatrix(t *testing.T) {
+	grants := []domain.Grant{
+		{Grantee: "CLERK", GranteeType: objRole, Grantor: "SYSDBA", Privilege: "U", Object: "EMPLOYEE", Field: "PHONE_EXT"},
+		{Grantee: "APP", GranteeType: objUser, Grantor: "SYSDBA", Privilege: "S", Object: "EMPLOYEE"},
+		{Grantee: "APP", GranteeType: objUser, Grantor: "ADMIN", Privilege: "S", Object: "EMPLOYEE", GrantOption: 1},
+		{Grantee: "APP", GranteeType: objUser, Grantor: "SYSDBA", Privilege: "I", Object: "EMPLOYEE"},
+		{Grantee: "APP", GranteeType: objUser, Grantor: "SYSDBA", Privilege: "S", Object: "DEPARTMENT"},
+		{Grantee: "CLERK", GranteeType: objRole, Grantor: "SYSDBA", Privilege: "M", Object: "EMPLOYEE"},
+		{Grantee: "SET_EMP_NO", GranteeType: objTrigger, Grantor: "SYSDBA", Privilege: "D", Object: "EMPLOYEE"},
+	}
+
+	got := privilegeMatrix("table", "EMPLOYEE", privilegeCells("EMPLOYEE", privilegeObjects["table"], grants))
+	want := &domain.PrivilegeMatrix{ObjectType: "table", Object: "EMPLOYEE", Grantees: []domain.GranteePrivileges{
+		{Grantee: "CLERK", GranteeType: "ROLE", Privileges: []domain.ObjectPrivilege{{Privilege: "U", Column: "PHONE_EXT"}}},
+		{Grantee: "SET_EMP_NO", GranteeType: "TRIGGER", Privileges: []domain.ObjectPrivilege{{Privilege: "D"}}},
+		{Grantee: "APP", GranteeType: "USER", Privileges: []domain.ObjectPrivilege{{Privilege: "S", GrantOption: true}, {Privilege: "I"}}},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("privilegeMatrix() = %+v, want %+v", got, want)
+	}
+}
+
+func TestPrivilegeDiff(t *testing.T) {
+	have := map[privilegeKey]bool{
+		{granteeType: objUser, grantee: "APP", privilege: "S"}:                        true,
+		{granteeType: objUser, grantee: "APP", privilege: "I"}:                        false,
+		{granteeType: objRole, grantee: "CLERK", privilege: "U", column: "PHONE_EXT"}: false,
+	}
+
+	tests := []struct {
+		name    string
+		object  string
+		desired []domain.GranteePrivileges
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "Unchanged",
+			object: "table",
+			desired: []domain.GranteePrivileges{
+				{Grantee: "APP", Privileges: []domain.ObjectPrivilege{{Privilege: "S", GrantOption: true}, {Privilege: "I"}}},
+				{Grantee: "CLERK", GranteeType: "ROLE", Privileges: []domain.ObjectPrivilege{{Privilege: "U", Column: "PHONE_EXT"}}},
+			},
+			want: []string{},
+		},
+		{
+			name:   "Revoke, drop grant option and grant",
+			object: "table",
+			desired: []domain.GranteePrivileges{
+				{Grantee: "APP", GranteeType: "USER", Privileges: []domain.ObjectPrivilege{{Privilege: "SELECT"}, {Privilege: "I", GrantOption: true}}},
+				{Grantee: "AUDITOR", GranteeType: "ROLE", Privileges: []domain.ObjectPrivilege{{Privilege: "S"}}},
+			},
+			want: []string{
+				`REVOKE UPDATE ("PHONE_EXT") ON "EMPLOYEE" FROM ROLE "CLERK"`,
+				`REVOKE GRANT OPTION FOR SELECT ON "EMPLOYEE" FROM USER "APP"`,
+				`GRANT SELECT ON "EMPLOYEE" TO ROLE "AUDITOR"`,
+				`GRANT INSERT ON "EMPLOYEE" TO USER "APP" WITH GRANT OPTION`,
+			},
+		},
+		{
+			name:    "Execute on a table",
+			object:  "table",
+			desired: []domain.GranteePrivileges{{Grantee: "APP", Privileges: []domain.ObjectPrivilege{{Privilege: "X"}}}},
+			wantErr: true,
+		},
+		{
+			name:    "Column-level select",
+			object:  "table",
+			desired: []domain.GranteePrivileges{{Grantee: "APP", Privileges: []domain.ObjectPrivilege{{Privilege: "S", Column: "SALARY"}}}},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown grantee type",
+			object:  "table",
+			desired: []domain.GranteePrivileges{{Grantee: "APP", GranteeType: "GROUP"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := privilegeDiff("EMPLOYEE", privilegeObjects[tt.object], have, domain.PrivilegeMatrix{Grantees: tt.desired})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("privilegeDiff() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var invalid *domain.ValidationError
+			if err != nil && !errors.As(err, &invalid) {
+				t.Errorf("privilegeDiff() error = %T, want *domain.ValidationError", err)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("privilegeDiff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
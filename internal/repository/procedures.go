@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"firebird-web-admin/internal/domain"
+	"fmt"
+	"log"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// procedureHeader matches the start of a statement that defines a procedure and captures its name.
+var procedureHeader = regexp.MustCompile(`(?is)^\s*(?:CREATE\s+OR\s+ALTER|CREATE|ALTER|RECREATE)\s+PROCEDURE\s+("(?:[^"]|"")+"|[A-Z][A-Z0-9_$]*)`)
+
+// statementObjectName returns the object name of a CREATE/ALTER statement matched by header,
+// unquoting delimited identifiers and upper-casing regular ones as Firebird does.
+func statementObjectName(header *regexp.Regexp, stmt string) (string, bool) {
+	m := header.FindStringSubmatch(stmt)
+	if m == nil {
+		return "", false
+	}
+	return objectName(m[1]), true
+}
+
+// trimStatement removes trailing blanks and a trailing SET TERM terminator copied from a script.
+// Leading lines are kept so error positions match the editor.
+func trimStatement(stmt string) string {
+	stmt = strings.TrimRight(stmt, " \t\r\n")
+	return strings.TrimRight(strings.TrimSuffix(stmt, "^"), " \t\r\n")
+}
+
+// CompileProcedure executes a CREATE [OR ALTER] / ALTER / RECREATE PROCEDURE statement for the named procedure.
+// Compile errors are returned as *domain.SQLError with the position inside the statement.
+func (r *FirebirdRepository) CompileProcedure(params domain.ConnectionParams, procName string, source string) error {
+	return r.compileObject(params, procedureHeader, "procedure", procName, source)
+}
+
+// compileObject executes a statement that defines the named object, after checking with
+// header that it defines that object and not another one.
+func (r *FirebirdRepository) compileObject(params domain.ConnectionParams, header *regexp.Regexp, kind string, objName string, source string) error {
+	stmt := trimStatement(source)
+	name, ok := statementObjectName(header, stmt)
+	if !ok {
+		return invalidf("the statement must be a %s definition", strings.ToUpper(kind))
+	}
+	if name != objectName(objName) {
+		return invalidf("the statement defines %s %s, not %s", kind, name, objName)
+	}
+
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	log.Printf("Compile %s: %s", kind, name)
+	if _, err := db.Exec(stmt); err != nil {
+		log.Printf("Compile %s error: %v", kind, err)
+		return sqlError(err, 0)
+	}
+	return nil
+}
+
+// loadProcedure loads a single standalone or packaged procedure with its typed parameters.
+// Names follow objectName.
+func loadProcedure(db *sql.DB, pkg string, procName string) (*domain.Routine, error) {
+	charset, err := loadDefaultCharset(db)
+	if err != nil {
+		return nil, err
+	}
+	procedures, err := loadProcedures(db, charset, objectName(pkg), objectName(procName))
+	if err != nil {
+		return nil, err
+	}
+	if len(procedures) == 0 {
+		return nil, &domain.NotFoundError{ObjectType: "procedure", Name: routineRef(domain.Routine{Package: pkg, Name: procName})}
+	}
+	return &procedures[0], nil
+}
+
+// sqlCommentOrString matches PSQL comments and string literals.
+var sqlCommentOrString = regexp.MustCompile(`(?s)/\*.*?\*/|--[^\n]*|'(?:[^']|'')*'`)
+
+// suspendStatement matches a SUSPEND statement.
+var suspendStatement = regexp.MustCompile(`(?i)\bSUSPEND\s*;`)
+
+// procedureSelectable reports whether a procedure is selectable. RDB$PROCEDURE_TYPE is NULL
+// for procedures whose metadata predates Firebird 2.1; they are selectable when their
+// source has a SUSPEND outside comments and strings.
+func procedureSelectable(procType sql.NullInt64, source string) bool {
+	if procType.Valid {
+		return procType.Int64 == 1
+	}
+	return suspendStatement.MatchString(sqlCommentOrString.ReplaceAllString(source, " "))
+}
+
+// procedureCallSQL returns the SELECT of a selectable procedure or the EXECUTE PROCEDURE
+// statement of an executable one, with the given argument placeholders.
+func procedureCallSQL(proc domain.Routine, selectable bool, placeholders []string) string {
+	call := routineRef(proc)
+	if len(placeholders) > 0 {
+		call += "(" + strings.Join(placeholders, ", ") + ")"
+	}
+	if selectable {
+		return "SELECT * FROM " + call
+	}
+	return "EXECUTE PROCEDURE " + call
+}
+
+// routineRef returns the quoted name used to call a routine, qualified by its package.
+func routineRef(r domain.Routine) string {
+	if r.Package != "" {
+		return quoteIdent(r.Package) + "." + quoteIdent(r.Name)
+	}
+	return quoteIdent(r.Name)
+}
+
+// parameterTimeLayouts lists the accepted textual forms of date, time and timestamp values.
+var parameterTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"02.01.2006 15:04:05.999999999",
+	"02.01.2006",
+}
+
+var parameterClockLayouts = []string{"15:04:05.999999999", "15:04"}
+
+// convertParameterValue converts a value decoded from JSON to the Go type the driver binds
+// for the given SQL type. Exact numerics are passed as strings so no precision is lost in
+// float64; empty strings are NULL for all non-text types.
+func convertParameterValue(v interface{}, dataType string) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if dataType == "" {
+		return v, nil
+	}
+	text, isText := v.(string)
+	if isText {
+		text = strings.TrimSpace(text)
+	}
+	typ := strings.ToUpper(dataType)
+	isType := func(prefixes ...string) bool {
+		for _, p := range prefixes {
+			if strings.HasPrefix(typ, p) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case isType("CHAR", "VARCHAR", "NCHAR", "BINARY", "VARBINARY", "BLOB") || strings.HasSuffix(typ, "WITH TIME ZONE"):
+		// Text, blobs and zoned values are sent as text and converted by the server.
+		if isText {
+			return v, nil
+		}
+		return numberText(v), nil
+	case isText && text == "":
+		return nil, nil
+	case isType("SMALLINT", "INTEGER", "BIGINT"):
+		switch val := v.(type) {
+		case json.Number:
+			return val.Int64()
+		case float64:
+			if val != math.Trunc(val) {
+				return nil, fmt.Errorf("%v is not an integer", val)
+			}
+			return int64(val), nil
+		case string:
+			return strconv.ParseInt(text, 10, 64)
+		}
+	case isType("INT128", "NUMERIC", "DECIMAL", "DECFLOAT"):
+		s := text
+		if !isText {
+			s = numberText(v)
+		}
+		if _, ok := new(big.Float).SetString(s); !ok {
+			return nil, fmt.Errorf("%q is not a number", s)
+		}
+		return s, nil
+	case isType("FLOAT", "DOUBLE", "REAL"):
+		switch val := v.(type) {
+		case json.Number:
+			return val.Float64()
+		case float64:
+			return val, nil
+		case string:
+			return strconv.ParseFloat(text, 64)
+		}
+	case isType("BOOLEAN"):
+		switch val := v.(type) {
+		case bool:
+			return val, nil
+		case json.Number, float64:
+			return numberText(val) != "0", nil
+		case string:
+			return strconv.ParseBool(text)
+		}
+	case isType("TIMESTAMP", "DATE"):
+		if isText {
+			for _, layout := range parameterTimeLayouts {
+				if t, err := time.Parse(layout, text); err == nil {
+					return t, nil
+				}
+			}
+		}
+	case isType("TIME"):
+		if isText {
+			// The driver binds a time.Time in year 0 as a TIME value.
+			for _, layout := range parameterClockLayouts {
+				if t, err := time.Parse(layout, text); err == nil {
+					return t, nil
+				}
+			}
+		}
+	default:
+		return v, nil
+	}
+	return nil, fmt.Errorf("cannot convert %v to %s", v, dataType)
+}
+
+// numberText formats a JSON value as text without losing precision for json.Number.
+func numberText(v interface{}) string {
+	switch val := v.(type) {
+	case json.Number:
+		return val.String()
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
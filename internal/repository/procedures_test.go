@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"firebird-web-admin/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestStatementObjectName(t *testing.T) {
+	tests := []struct {
+		name     string
+		stmt     string
+		expected string
+		ok       bool
+	}{
+		{name: "Create or alter", stmt: "CREATE OR ALTER PROCEDURE get_items (id INTEGER)\nAS BEGIN END", expected: "GET_ITEMS", ok: true},
+		{name: "Leading blank lines", stmt: "\n\n  create procedure P$1\nAS BEGIN END", expected: "P$1", ok: true},
+		{name: "Quoted name", stmt: "RECREATE PROCEDURE \"My \"\"Proc\"\"\"\nAS BEGIN END", expected: "My \"Proc\"", ok: true},
+		{name: "Alter", stmt: "ALTER PROCEDURE X AS BEGIN END", expected: "X", ok: true},
+		{name: "Not a procedure", stmt: "CREATE TABLE X (ID INTEGER)"},
+		{name: "Leading statement", stmt: "DROP TABLE T; CREATE PROCEDURE X AS BEGIN END"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := statementObjectName(procedureHeader, tt.stmt)
+			if ok != tt.ok || got != tt.expected {
+				t.Errorf("statementObjectName() = %q, %v, want %q, %v", got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+
+	if got := trimStatement("\nCREATE PROCEDURE X AS BEGIN END^\n"); got != "\nCREATE PROCEDURE X AS BEGIN END" {
+		t.Errorf("trimStatement() = %q", got)
+	}
+}
+
+func TestConvertParameterValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		dataType string
+		expected interface{}
+		wantErr  bool
+	}{
+		{name: "Integer from number", value: json.Number("42"), dataType: "INTEGER", expected: int64(42)},
+		{name: "Bigint keeps precision", value: json.Number("9007199254740993"), dataType: "BIGINT", expected: int64(9007199254740993)},
+		{name: "Integer from string", value: " 7 ", dataType: "SMALLINT", expected: int64(7)},
+		{name: "Integer from fraction", value: 1.5, dataType: "INTEGER", wantErr: true},
+		{name: "Empty string is NULL", value: "", dataType: "INTEGER", expected: nil},
+		{name: "Numeric as exact text", value: json.Number("12345678901234.56"), dataType: "NUMERIC(18, 2)", expected: "12345678901234.56"},
+		{name: "Numeric from string", value: "0.10", dataType: "DECIMAL(9, 2)", expected: "0.10"},
+		{name: "Numeric invalid", value: "abc", dataType: "NUMERIC(9, 2)", wantErr: true},
+		{name: "Double", value: json.Number("2.5"), dataType: "DOUBLE PRECISION", expected: 2.5},
+		{name: "Boolean from string", value: "true", dataType: "BOOLEAN", expected: true},
+		{name: "Boolean from number", value: json.Number("0"), dataType: "BOOLEAN", expected: false},
+		{name: "Date", value: "2024-02-29", dataType: "DATE", expected: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{name: "Timestamp", value: "2024-02-29 13:45:10", dataType: "TIMESTAMP", expected: time.Date(2024, 2, 29, 13, 45, 10, 0, time.UTC)},
+		{name: "Time", value: "13:45", dataType: "TIME", expected: time.Date(0, 1, 1, 13, 45, 0, 0, time.UTC)},
+		{name: "Invalid date", value: "yesterday", dataType: "DATE", wantErr: true},
+		{name: "Zoned timestamp stays text", value: "2024-02-29 13:45 Europe/Berlin", dataType: "TIMESTAMP WITH TIME ZONE", expected: "2024-02-29 13:45 Europe/Berlin"},
+		{name: "Varchar from number", value: json.Number("10"), dataType: "VARCHAR(10)", expected: "10"},
+		{name: "Varchar keeps blanks", value: " a ", dataType: "VARCHAR(10)", expected: " a "},
+		{name: "Nil", value: nil, dataType: "DATE", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convertParameterValue(tt.value, tt.dataType)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("convertParameterValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if gt, ok := got.(time.Time); ok {
+				if !gt.Equal(tt.expected.(time.Time)) {
+					t.Errorf("convertParameterValue() = %v, want %v", got, tt.expected)
+				}
+				return
+			}
+			if got != tt.expected {
+				t.Errorf("convertParameterValue() = %#v, want %#v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestProcedureSelectable(t *testing.T) {
+	tests := []struct {
+		name     string
+		procType sql.NullInt64
+		source   string
+		expected bool
+	}{
+		{name: "Selectable type", procType: sql.NullInt64{Int64: 1, Valid: true}, source: "BEGIN END", expected: true},
+		{name: "Executable type", procType: sql.NullInt64{Int64: 2, Valid: true}, source: "BEGIN SUSPEND; END", expected: false},
+		{name: "Legacy with SUSPEND", source: "BEGIN\n  FOR SELECT ID FROM T INTO :ID DO\n    suspend;\nEND", expected: true},
+		{name: "Legacy without SUSPEND", source: "BEGIN\n  UPDATE T SET X = 1;\nEND", expected: false},
+		{name: "Legacy with SUSPEND in a comment", source: "BEGIN\n  -- SUSPEND;\n  /* SUSPEND; */\n  X = 1;\nEND"},
+		{name: "Legacy with SUSPEND in a string", source: "BEGIN\n  MSG = 'SUSPEND;';\nEND"},
+		{name: "Legacy with a SUSPENDED variable", source: "BEGIN\n  SUSPENDED = 1;\nEND"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := procedureSelectable(tt.procType, tt.source); got != tt.expected {
+				t.Errorf("procedureSelectable() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestProcedureCallSQL(t *testing.T) {
+	tests := []struct {
+		name         string
+		proc         domain.Routine
+		selectable   bool
+		placeholders []string
+		expected     string
+	}{
+		{name: "Selectable", proc: domain.Routine{Name: "GET_ITEMS"}, selectable: true, placeholders: []string{"?", "?"}, expected: `SELECT * FROM "GET_ITEMS"(?, ?)`},
+		{name: "Executable", proc: domain.Routine{Name: "ADD_ITEM"}, placeholders: []string{"?"}, expected: `EXECUTE PROCEDURE "ADD_ITEM"(?)`},
+		{name: "Executable without arguments", proc: domain.Routine{Name: "RESET"}, expected: `EXECUTE PROCEDURE "RESET"`},
+		{name: "Packaged selectable", proc: domain.Routine{Package: "PKG", Name: "ITEMS"}, selectable: true, expected: `SELECT * FROM "PKG"."ITEMS"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := procedureCallSQL(tt.proc, tt.selectable, tt.placeholders); got != tt.expected {
+				t.Errorf("procedureCallSQL() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
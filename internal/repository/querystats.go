@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"firebird-web-admin/internal/domain"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// executionSnapshot holds the monitoring counters of the current attachment.
+type executionSnapshot struct {
+	reads, writes, fetches, marks int64
+	tables                        map[string]domain.TableStats
+}
+
+// readExecutionSnapshot takes a snapshot of the counters of the attachment of conn in a
+// transaction of its own. Firebird fills the MON$ tables once per transaction, on its first
+// read of them, and keeps them until the transaction ends. The driver's autocommit
+// transaction only ends with COMMIT RETAINING, which keeps the snapshot too, so reading
+// both snapshots in it would return the same counters twice and zero deltas.
+func readExecutionSnapshot(ctx context.Context, conn *sql.Conn) (*executionSnapshot, error) {
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	s, err := loadExecutionSnapshot(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	return s, tx.Commit()
+}
+
+// loadExecutionSnapshot reads the I/O counters of the attachment of tx and, on Firebird 3
+// and later, its per-table record counters. Monitoring tables are left out because the
+// snapshot queries themselves read them.
+func loadExecutionSnapshot(ctx context.Context, tx *sql.Tx) (*executionSnapshot, error) {
+	s := &executionSnapshot{tables: make(map[string]domain.TableStats)}
+	err := tx.QueryRowContext(ctx, `
+		SELECT io.MON$PAGE_READS, io.MON$PAGE_WRITES, io.MON$PAGE_FETCHES, io.MON$PAGE_MARKS
+		FROM MON$ATTACHMENTS a
+		JOIN MON$IO_STATS io ON io.MON$STAT_ID = a.MON$STAT_ID
+		WHERE a.MON$ATTACHMENT_ID = CURRENT_CONNECTION
+	`).Scan(&s.reads, &s.writes, &s.fetches, &s.marks)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT t.MON$TABLE_NAME, r.MON$RECORD_SEQ_READS, r.MON$RECORD_IDX_READS, r.MON$RECORD_INSERTS,
+			r.MON$RECORD_UPDATES, r.MON$RECORD_DELETES, r.MON$RECORD_BACKOUTS, r.MON$RECORD_PURGES, r.MON$RECORD_EXPUNGES
+		FROM MON$ATTACHMENTS a
+		JOIN MON$TABLE_STATS t ON t.MON$STAT_ID = a.MON$STAT_ID
+		JOIN MON$RECORD_STATS r ON r.MON$STAT_ID = t.MON$RECORD_STAT_ID
+		WHERE a.MON$ATTACHMENT_ID = CURRENT_CONNECTION
+	`)
+	if err != nil {
+		// MON$TABLE_STATS only exists since Firebird 3.
+		log.Printf("loadExecutionSnapshot table stats: %v", err)
+		return s, nil
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var t domain.TableStats
+		if err := rows.Scan(&t.Table, &t.SeqReads, &t.IdxReads, &t.Inserts, &t.Updates, &t.Deletes, &t.Backouts, &t.Purges, &t.Expunges); err != nil {
+			return nil, err
+		}
+		t.Table = strings.TrimSpace(t.Table)
+		if !strings.HasPrefix(t.Table, "MON$") {
+			s.tables[t.Table] = t
+		}
+	}
+	return s, rows.Err()
+}
+
+// executionStats returns the counters accumulated between two snapshots. Tables that were
+// not touched are omitted.
+func executionStats(before, after *executionSnapshot) *domain.QueryStats {
+	stats := &domain.QueryStats{
+		Reads:   after.reads - before.reads,
+		Writes:  after.writes - before.writes,
+		Fetches: after.fetches - before.fetches,
+		Marks:   after.marks - before.marks,
+		Tables:  []domain.TableStats{},
+	}
+	for name, a := range after.tables {
+		b := before.tables[name]
+		d := domain.TableStats{
+			Table:    name,
+			SeqReads: a.SeqReads - b.SeqReads,
+			IdxReads: a.IdxReads - b.IdxReads,
+			Inserts:  a.Inserts - b.Inserts,
+			Updates:  a.Updates - b.Updates,
+			Deletes:  a.Deletes - b.Deletes,
+			Backouts: a.Backouts - b.Backouts,
+			Purges:   a.Purges - b.Purges,
+			Expunges: a.Expunges - b.Expunges,
+		}
+		if d != (domain.TableStats{Table: name}) {
+			stats.Tables = append(stats.Tables, d)
+		}
+	}
+	sort.Slice(stats.Tables, func(i, j int) bool { return stats.Tables[i].Table < stats.Tables[j].Table })
+	return stats
+}
+
+// milliseconds returns the time elapsed since start in milliseconds.
+func milliseconds(start time.Time) float64 {
+	return float64(time.Since(start).Microseconds()) / 1000
+}
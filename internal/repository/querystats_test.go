@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"firebird-web-admin/internal/domain"
+	"reflect"
+	"testing"
+)
+
+func TestExecutionStats(t *testing.T) {
+	before := &executionSnapshot{
+		reads: 10, writes: 1, fetches: 100, marks: 2,
+		tables: map[string]domain.TableStats{
+			"CUSTOMER": {Table: "CUSTOMER", SeqReads: 5},
+			"COUNTRY":  {Table: "COUNTRY", IdxReads: 3},
+		},
+	}
+	after := &executionSnapshot{
+		reads: 15, writes: 1, fetches: 180, marks: 4,
+		tables: map[string]domain.TableStats{
+			"CUSTOMER": {Table: "CUSTOMER", SeqReads: 25, Updates: 2},
+			"COUNTRY":  {Table: "COUNTRY", IdxReads: 3},
+			"ORDERS":   {Table: "ORDERS", IdxReads: 7, Inserts: 1},
+		},
+	}
+
+	got := executionStats(before, after)
+	expected := &domain.QueryStats{
+		Reads: 5, Writes: 0, Fetches: 80, Marks: 2,
+		Tables: []domain.TableStats{
+			{Table: "CUSTOMER", SeqReads: 20, Updates: 2},
+			{Table: "ORDERS", IdxReads: 7, Inserts: 1},
+		},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("executionStats() = %+v, want %+v", got, expected)
+	}
+}
@@ -0,0 +1,721 @@
+package repository
+
+import (
+	"firebird-web-admin/internal/domain"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Migration phases, in the order their statements appear in the script.
+// Dependents are dropped first, then objects are created or altered bottom-up,
+// and finally removed objects are dropped once nothing references them.
+const (
+	phaseDropTriggers = iota
+	phaseDropViews
+	phaseDropForeignKeys
+	phaseDropConstraints
+	phaseDropIndexes
+	phaseDomains
+	phaseSequences
+	phaseExceptions
+	phaseRoutineStubs
+	phasePackageHeaders
+	phaseTables
+	phaseConstraints
+	phaseForeignKeys
+	phaseIndexes
+	phaseViews
+	phaseRoutineBodies
+	phasePackageBodies
+	phaseTriggers
+	phaseDropRoutines
+	phaseDropTables
+	phaseDropObjects
+	phaseCount
+)
+
+type migrationStatement struct {
+	sql  string
+	psql bool // Needs the SET TERM ^ terminator
+}
+
+// migration collects the changes and statements produced by DiffSchemas.
+type migration struct {
+	phases   [phaseCount][]migrationStatement
+	changes  []domain.SchemaChange
+	warnings []string
+	// Columns that are dropped or retyped, by table. Views that select them are recreated.
+	changedColumns map[string]map[string]bool
+}
+
+func (m *migration) add(phase int, stmt string) {
+	m.phases[phase] = append(m.phases[phase], migrationStatement{sql: stmt})
+}
+
+func (m *migration) addPSQL(phase int, stmt string) {
+	m.phases[phase] = append(m.phases[phase], migrationStatement{sql: stmt, psql: true})
+}
+
+func (m *migration) change(objectType, parent, name, action string, details ...string) {
+	m.changes = append(m.changes, domain.SchemaChange{
+		ObjectType: objectType,
+		Name:       name,
+		Parent:     parent,
+		Action:     action,
+		Details:    details,
+	})
+}
+
+func (m *migration) columnChanged(table, column string) {
+	if m.changedColumns == nil {
+		m.changedColumns = make(map[string]map[string]bool)
+	}
+	if m.changedColumns[table] == nil {
+		m.changedColumns[table] = make(map[string]bool)
+	}
+	m.changedColumns[table][column] = true
+}
+
+func (m *migration) warn(format string, args ...interface{}) {
+	m.warnings = append(m.warnings, fmt.Sprintf(format, args...))
+}
+
+func (m *migration) script() string {
+	var sb strings.Builder
+	for _, stmts := range m.phases {
+		for i := 0; i < len(stmts); {
+			if !stmts[i].psql {
+				sb.WriteString(stmts[i].sql + ";\n")
+				i++
+				continue
+			}
+			sb.WriteString("SET TERM ^ ;\n")
+			for ; i < len(stmts) && stmts[i].psql; i++ {
+				sb.WriteString(stmts[i].sql + "^\n")
+			}
+			sb.WriteString("SET TERM ; ^\n")
+		}
+	}
+	return sb.String()
+}
+
+// normalizeSQL collapses whitespace so that formatting-only differences in sources are ignored.
+func normalizeSQL(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// changed returns a "label: old -> new" detail when the values differ.
+func changed(label, old, new string) []string {
+	if old == new {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s: %s -> %s", label, displayValue(old), displayValue(new))}
+}
+
+func displayValue(v string) string {
+	if v == "" {
+		return "(none)"
+	}
+	return v
+}
+
+func boolText(v bool) string {
+	if v {
+		return "yes"
+	}
+	return "no"
+}
+
+// DiffSchemas compares target against source and returns the differences together with
+// an ordered script that migrates target into source.
+func DiffSchemas(source, target *domain.Schema) *domain.SchemaDiff {
+	m := &migration{}
+
+	diffDomains(m, source.Domains, target.Domains)
+	diffSequences(m, source.Sequences, target.Sequences)
+	diffExceptions(m, source.Exceptions, target.Exceptions)
+	droppedTables := diffTables(m, source.Tables, target.Tables)
+	diffIndexes(m, source.Indexes, target.Indexes, droppedTables)
+	recreatedViews := diffViews(m, source.Views, target.Views)
+	diffRoutines(m, "PROCEDURE", source.Procedures, target.Procedures)
+	diffRoutines(m, "FUNCTION", source.Functions, target.Functions)
+	diffPackages(m, source.Packages, target.Packages)
+	diffTriggers(m, source.Triggers, target.Triggers, droppedTables, recreatedViews)
+
+	return &domain.SchemaDiff{
+		Changes:  m.changes,
+		Warnings: m.warnings,
+		Script:   m.script(),
+	}
+}
+
+func diffDomains(m *migration, source, target []domain.Domain) {
+	old := make(map[string]domain.Domain)
+	for _, d := range target {
+		old[d.Name] = d
+	}
+	for _, d := range source {
+		o, ok := old[d.Name]
+		delete(old, d.Name)
+		if !ok {
+			m.change("DOMAIN", "", d.Name, "CREATE")
+			m.add(phaseDomains, domainSQL(d))
+			continue
+		}
+		name := quoteIdent(d.Name)
+		var details []string
+		if d.Type != o.Type {
+			details = append(details, changed("type", o.Type, d.Type)...)
+			m.add(phaseDomains, fmt.Sprintf("ALTER DOMAIN %s TYPE %s", name, d.Type))
+		}
+		if d.Default != o.Default {
+			details = append(details, changed("default", o.Default, d.Default)...)
+			if d.Default == "" {
+				m.add(phaseDomains, fmt.Sprintf("ALTER DOMAIN %s DROP DEFAULT", name))
+			} else {
+				m.add(phaseDomains, fmt.Sprintf("ALTER DOMAIN %s SET %s", name, d.Default))
+			}
+		}
+		if d.NotNull != o.NotNull {
+			details = append(details, changed("not null", boolText(o.NotNull), boolText(d.NotNull))...)
+			if d.NotNull {
+				m.add(phaseDomains, fmt.Sprintf("ALTER DOMAIN %s SET NOT NULL", name))
+			} else {
+				m.add(phaseDomains, fmt.Sprintf("ALTER DOMAIN %s DROP NOT NULL", name))
+			}
+		}
+		if normalizeSQL(d.Check) != normalizeSQL(o.Check) {
+			details = append(details, changed("check", o.Check, d.Check)...)
+			if o.Check != "" {
+				m.add(phaseDomains, fmt.Sprintf("ALTER DOMAIN %s DROP CONSTRAINT", name))
+			}
+			if d.Check != "" {
+				m.add(phaseDomains, fmt.Sprintf("ALTER DOMAIN %s ADD CONSTRAINT %s", name, d.Check))
+			}
+		}
+		if d.Collation != o.Collation {
+			details = append(details, changed("collation", o.Collation, d.Collation)...)
+			m.warn("Domain %s: collation cannot be altered, recreate the domain manually", d.Name)
+		}
+		if len(details) > 0 {
+			m.change("DOMAIN", "", d.Name, "ALTER", details...)
+		}
+	}
+	for _, d := range sortedKeys(old) {
+		m.change("DOMAIN", "", d, "DROP")
+		m.add(phaseDropObjects, "DROP DOMAIN "+quoteIdent(d))
+	}
+}
+
+func diffSequences(m *migration, source, target []domain.Sequence) {
+	old := make(map[string]domain.Sequence)
+	for _, s := range target {
+		old[s.Name] = s
+	}
+	for _, s := range source {
+		o, ok := old[s.Name]
+		delete(old, s.Name)
+		if !ok {
+			m.change("SEQUENCE", "", s.Name, "CREATE")
+			m.add(phaseSequences, sequenceSQL(s))
+			continue
+		}
+		if s.Increment != o.Increment {
+			m.change("SEQUENCE", "", s.Name, "ALTER", changed("increment", fmt.Sprint(o.Increment), fmt.Sprint(s.Increment))...)
+			m.add(phaseSequences, fmt.Sprintf("ALTER SEQUENCE %s INCREMENT BY %d", quoteIdent(s.Name), s.Increment))
+		}
+	}
+	for _, s := range sortedKeys(old) {
+		m.change("SEQUENCE", "", s, "DROP")
+		m.add(phaseDropObjects, "DROP SEQUENCE "+quoteIdent(s))
+	}
+}
+
+func diffExceptions(m *migration, source, target []domain.DatabaseException) {
+	old := make(map[string]domain.DatabaseException)
+	for _, e := range target {
+		old[e.Name] = e
+	}
+	for _, e := range source {
+		o, ok := old[e.Name]
+		delete(old, e.Name)
+		switch {
+		case !ok:
+			m.change("EXCEPTION", "", e.Name, "CREATE")
+		case o.Message != e.Message:
+			m.change("EXCEPTION", "", e.Name, "ALTER", changed("message", o.Message, e.Message)...)
+		default:
+			continue
+		}
+		m.add(phaseExceptions, "CREATE OR ALTER"+strings.TrimPrefix(exceptionSQL(e), "CREATE"))
+	}
+	for _, e := range sortedKeys(old) {
+		m.change("EXCEPTION", "", e, "DROP")
+		m.add(phaseDropObjects, "DROP EXCEPTION "+quoteIdent(e))
+	}
+}
+
+// constraintKey identifies a constraint by what it enforces rather than by name,
+// because system-generated INTEG_ names differ between databases.
+func constraintKey(c domain.Constraint) string {
+	switch c.Type {
+	case "PRIMARY KEY":
+		return "PRIMARY KEY"
+	case "CHECK":
+		return "CHECK " + normalizeSQL(c.Check)
+	}
+	key := c.Type + " (" + strings.Join(c.Columns, ",") + ")"
+	if c.Type == "FOREIGN KEY" {
+		key += " " + c.RefTable + " (" + strings.Join(c.RefColumns, ",") + ")"
+	}
+	return key
+}
+
+func constraintEqual(a, b domain.Constraint) bool {
+	return constraintKey(a) == constraintKey(b) &&
+		strings.Join(a.Columns, ",") == strings.Join(b.Columns, ",") &&
+		a.OnUpdate == b.OnUpdate && a.OnDelete == b.OnDelete && a.Descending == b.Descending
+}
+
+// diffTables compares tables, columns and constraints. It returns the set of dropped tables.
+func diffTables(m *migration, source, target []domain.TableDefinition) map[string]bool {
+	old := make(map[string]domain.TableDefinition)
+	for _, t := range target {
+		old[t.Name] = t
+	}
+	for _, t := range source {
+		o, ok := old[t.Name]
+		delete(old, t.Name)
+		if !ok {
+			m.change("TABLE", "", t.Name, "CREATE")
+			m.add(phaseTables, tableSQL(t))
+			for _, c := range t.Constraints {
+				if c.Type == "FOREIGN KEY" || c.Type == "CHECK" {
+					m.add(phaseForeignKeys, fmt.Sprintf("ALTER TABLE %s ADD %s", quoteIdent(t.Name), constraintSQL(c)))
+				}
+			}
+			continue
+		}
+		if t.Kind != o.Kind {
+			m.warn("Table %s: table type changed from %s to %s, recreate the table manually", t.Name, o.Kind, t.Kind)
+		}
+		diffColumns(m, t.Name, t.Columns, o.Columns)
+		diffConstraints(m, t.Name, t.Constraints, o.Constraints)
+	}
+
+	dropped := make(map[string]bool)
+	for _, name := range sortedKeys(old) {
+		dropped[name] = true
+		m.change("TABLE", "", name, "DROP")
+		m.add(phaseDropTables, "DROP TABLE "+quoteIdent(name))
+		// Foreign keys of dropped tables go first so referenced keys can be changed or dropped.
+		for _, c := range old[name].Constraints {
+			if c.Type == "FOREIGN KEY" {
+				m.add(phaseDropForeignKeys, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", quoteIdent(name), quoteIdent(c.Name)))
+			}
+		}
+	}
+	return dropped
+}
+
+func diffColumns(m *migration, table string, source, target []domain.TableColumn) {
+	tableName := quoteIdent(table)
+	old := make(map[string]domain.TableColumn)
+	for _, c := range target {
+		old[c.Name] = c
+	}
+	for _, c := range source {
+		o, ok := old[c.Name]
+		delete(old, c.Name)
+		if !ok {
+			m.change("COLUMN", table, c.Name, "CREATE")
+			m.add(phaseTables, fmt.Sprintf("ALTER TABLE %s ADD %s", tableName, columnSQL(c)))
+			continue
+		}
+
+		col := quoteIdent(c.Name)
+		if (c.Computed == "") != (o.Computed == "") {
+			m.change("COLUMN", table, c.Name, "ALTER", changed("computed", o.Computed, c.Computed)...)
+			m.warn("Column %s.%s switches between computed and stored, it is dropped and re-added (data is lost)", table, c.Name)
+			m.columnChanged(table, c.Name)
+			m.add(phaseTables, fmt.Sprintf("ALTER TABLE %s DROP %s", tableName, col))
+			m.add(phaseTables, fmt.Sprintf("ALTER TABLE %s ADD %s", tableName, columnSQL(c)))
+			continue
+		}
+
+		var details []string
+		if c.Computed != "" {
+			if normalizeSQL(c.Computed) != normalizeSQL(o.Computed) {
+				details = append(details, changed("computed", o.Computed, c.Computed)...)
+				m.add(phaseTables, fmt.Sprintf("ALTER TABLE %s ALTER %s COMPUTED BY %s", tableName, col, parenthesize(c.Computed)))
+			}
+		} else {
+			newType, oldType := columnTypeSQL(c), columnTypeSQL(o)
+			if newType != oldType {
+				details = append(details, changed("type", oldType, newType)...)
+				m.add(phaseTables, fmt.Sprintf("ALTER TABLE %s ALTER %s TYPE %s", tableName, col, newType))
+				m.columnChanged(table, c.Name)
+			}
+			if c.Default != o.Default {
+				details = append(details, changed("default", o.Default, c.Default)...)
+				if c.Default == "" {
+					m.add(phaseTables, fmt.Sprintf("ALTER TABLE %s ALTER %s DROP DEFAULT", tableName, col))
+				} else {
+					m.add(phaseTables, fmt.Sprintf("ALTER TABLE %s ALTER %s SET %s", tableName, col, c.Default))
+				}
+			}
+			if c.NotNull != o.NotNull && c.Identity == "" {
+				details = append(details, changed("not null", boolText(o.NotNull), boolText(c.NotNull))...)
+				if c.NotNull {
+					m.add(phaseTables, fmt.Sprintf("ALTER TABLE %s ALTER %s SET NOT NULL", tableName, col))
+				} else {
+					m.add(phaseTables, fmt.Sprintf("ALTER TABLE %s ALTER %s DROP NOT NULL", tableName, col))
+				}
+			}
+			if c.Identity != o.Identity {
+				details = append(details, changed("identity", o.Identity, c.Identity)...)
+				switch {
+				case c.Identity == "":
+					m.add(phaseTables, fmt.Sprintf("ALTER TABLE %s ALTER %s DROP IDENTITY", tableName, col))
+				case o.Identity != "":
+					m.add(phaseTables, fmt.Sprintf("ALTER TABLE %s ALTER %s SET GENERATED %s", tableName, col, c.Identity))
+				default:
+					m.warn("Column %s.%s: an existing column cannot become an identity column, recreate it manually", table, c.Name)
+				}
+			}
+			if c.Collation != o.Collation {
+				details = append(details, changed("collation", o.Collation, c.Collation)...)
+				m.warn("Column %s.%s: collation cannot be altered in place, recreate the column manually", table, c.Name)
+			}
+		}
+		if len(details) > 0 {
+			m.change("COLUMN", table, c.Name, "ALTER", details...)
+		}
+	}
+	for _, name := range sortedKeys(old) {
+		m.change("COLUMN", table, name, "DROP")
+		m.add(phaseTables, fmt.Sprintf("ALTER TABLE %s DROP %s", tableName, quoteIdent(name)))
+		m.columnChanged(table, name)
+	}
+}
+
+// columnTypeSQL returns the type part of a column definition: its domain or its data type.
+func columnTypeSQL(c domain.TableColumn) string {
+	if c.Domain != "" {
+		return quoteIdent(c.Domain)
+	}
+	return c.Type
+}
+
+func diffConstraints(m *migration, table string, source, target []domain.Constraint) {
+	tableName := quoteIdent(table)
+	old := make(map[string]domain.Constraint)
+	for _, c := range target {
+		old[constraintKey(c)] = c
+	}
+	for _, c := range source {
+		key := constraintKey(c)
+		o, ok := old[key]
+		delete(old, key)
+		if ok && constraintEqual(c, o) {
+			continue
+		}
+		if ok {
+			m.change("CONSTRAINT", table, c.Name, "ALTER", changed("definition", constraintSQL(o), constraintSQL(c))...)
+			dropConstraint(m, table, o)
+		} else {
+			m.change("CONSTRAINT", table, c.Name, "CREATE")
+		}
+		phase := phaseConstraints
+		if c.Type == "FOREIGN KEY" || c.Type == "CHECK" {
+			phase = phaseForeignKeys
+		}
+		m.add(phase, fmt.Sprintf("ALTER TABLE %s ADD %s", tableName, constraintSQL(c)))
+	}
+
+	for _, k := range sortedKeys(old) {
+		m.change("CONSTRAINT", table, old[k].Name, "DROP")
+		dropConstraint(m, table, old[k])
+	}
+}
+
+func dropConstraint(m *migration, table string, c domain.Constraint) {
+	phase := phaseDropConstraints
+	if c.Type == "FOREIGN KEY" {
+		phase = phaseDropForeignKeys
+	}
+	m.add(phase, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", quoteIdent(table), quoteIdent(c.Name)))
+}
+
+func diffIndexes(m *migration, source, target []domain.Index, droppedTables map[string]bool) {
+	old := make(map[string]domain.Index)
+	for _, i := range target {
+		if i.Constraint == "" {
+			old[i.Name] = i
+		}
+	}
+	for _, i := range source {
+		if i.Constraint != "" {
+			continue
+		}
+		o, ok := old[i.Name]
+		delete(old, i.Name)
+		if !ok {
+			m.change("INDEX", i.Relation, i.Name, "CREATE")
+			m.add(phaseIndexes, indexSQL(i))
+			if !i.Active {
+				m.add(phaseIndexes, fmt.Sprintf("ALTER INDEX %s INACTIVE", quoteIdent(i.Name)))
+			}
+			continue
+		}
+		if indexSQL(i) != indexSQL(o) {
+			m.change("INDEX", i.Relation, i.Name, "ALTER", changed("definition", indexSQL(o), indexSQL(i))...)
+			m.add(phaseDropIndexes, "DROP INDEX "+quoteIdent(o.Name))
+			m.add(phaseIndexes, indexSQL(i))
+			if !i.Active {
+				m.add(phaseIndexes, fmt.Sprintf("ALTER INDEX %s INACTIVE", quoteIdent(i.Name)))
+			}
+			continue
+		}
+		if i.Active != o.Active {
+			m.change("INDEX", i.Relation, i.Name, "ALTER", changed("active", boolText(o.Active), boolText(i.Active))...)
+			state := "ACTIVE"
+			if !i.Active {
+				state = "INACTIVE"
+			}
+			m.add(phaseIndexes, fmt.Sprintf("ALTER INDEX %s %s", quoteIdent(i.Name), state))
+		}
+	}
+	for _, name := range sortedKeys(old) {
+		if droppedTables[old[name].Relation] {
+			continue
+		}
+		m.change("INDEX", old[name].Relation, name, "DROP")
+		m.add(phaseDropIndexes, "DROP INDEX "+quoteIdent(name))
+	}
+}
+
+func viewEqual(a, b domain.ViewDefinition) bool {
+	if normalizeSQL(a.Source) != normalizeSQL(b.Source) || len(a.Columns) != len(b.Columns) {
+		return false
+	}
+	for i := range a.Columns {
+		if a.Columns[i].Name != b.Columns[i].Name {
+			return false
+		}
+	}
+	return true
+}
+
+// diffViews compares views and returns the existing views that are dropped and recreated
+// because they select a dropped or retyped column, or such a view.
+func diffViews(m *migration, source, target []domain.ViewDefinition) map[string]bool {
+	old := make(map[string]domain.ViewDefinition)
+	for _, v := range target {
+		old[v.Name] = v
+	}
+	recreated := make(map[string]bool)
+	for _, v := range sortViews(target) {
+		recreate := false
+		for _, dep := range v.DependsOn {
+			recreate = recreate || recreated[dep]
+		}
+		for table, columns := range v.UsesColumns {
+			for _, c := range columns {
+				recreate = recreate || m.changedColumns[table][c]
+			}
+		}
+		if recreate {
+			recreated[v.Name] = true
+		}
+	}
+
+	for _, v := range sortViews(source) {
+		o, ok := old[v.Name]
+		delete(old, v.Name)
+		switch {
+		case !ok:
+			m.change("VIEW", "", v.Name, "CREATE")
+		case !viewEqual(v, o) || recreated[v.Name]:
+			details := changed("source", normalizeSQL(o.Source), normalizeSQL(v.Source))
+			if recreated[v.Name] {
+				details = append(details, "recreated for changed columns it depends on")
+			}
+			m.change("VIEW", "", v.Name, "ALTER", details...)
+		default:
+			continue
+		}
+		m.add(phaseViews, "CREATE OR ALTER"+strings.TrimPrefix(viewSQL(v), "CREATE"))
+	}
+
+	// Drop removed and recreated views in reverse dependency order.
+	var dropped []domain.ViewDefinition
+	for _, v := range sortViews(target) {
+		if _, ok := old[v.Name]; ok || recreated[v.Name] {
+			dropped = append(dropped, v)
+		}
+	}
+	for i := len(dropped) - 1; i >= 0; i-- {
+		if _, ok := old[dropped[i].Name]; ok {
+			m.change("VIEW", "", dropped[i].Name, "DROP")
+		}
+		m.add(phaseDropViews, "DROP VIEW "+quoteIdent(dropped[i].Name))
+	}
+	return recreated
+}
+
+func parametersEqual(a, b []domain.ProcedureParameter) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if parameterSQL(a[i]) != parameterSQL(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func routineEqual(a, b domain.Routine) bool {
+	return parametersEqual(a.Inputs, b.Inputs) && parametersEqual(a.Outputs, b.Outputs) &&
+		a.Returns == b.Returns && a.Deterministic == b.Deterministic && a.Legacy == b.Legacy &&
+		a.Engine == b.Engine && a.EntryPoint == b.EntryPoint && a.ModuleName == b.ModuleName &&
+		a.SQLSecurity == b.SQLSecurity && normalizeSQL(a.Source) == normalizeSQL(b.Source)
+}
+
+// diffRoutines compares procedures or functions (kind is "PROCEDURE" or "FUNCTION").
+// New PSQL routines are created as stubs first so tables and views may reference them.
+func diffRoutines(m *migration, kind string, source, target []domain.Routine) {
+	render := procedureSQL
+	if kind == "FUNCTION" {
+		render = functionSQL
+	}
+	old := make(map[string]domain.Routine)
+	for _, r := range target {
+		old[r.Name] = r
+	}
+	for _, r := range source {
+		o, ok := old[r.Name]
+		delete(old, r.Name)
+		if ok && routineEqual(r, o) {
+			continue
+		}
+		if ok {
+			m.change(kind, "", r.Name, "ALTER")
+		} else {
+			m.change(kind, "", r.Name, "CREATE")
+		}
+
+		if r.Legacy {
+			if ok {
+				m.add(phaseRoutineStubs, "DROP EXTERNAL FUNCTION "+quoteIdent(r.Name))
+			}
+			m.add(phaseRoutineStubs, legacyFunctionSQL(r))
+			continue
+		}
+		if ok && o.Legacy {
+			m.add(phaseRoutineStubs, "DROP EXTERNAL FUNCTION "+quoteIdent(o.Name))
+		}
+		if r.Engine != "" {
+			m.addPSQL(phaseRoutineStubs, render(r, false))
+			continue
+		}
+		if !ok {
+			m.addPSQL(phaseRoutineStubs, render(r, true))
+		}
+		m.addPSQL(phaseRoutineBodies, render(r, false))
+	}
+	for _, name := range sortedKeys(old) {
+		m.change(kind, "", name, "DROP")
+		stmt := "DROP " + kind + " " + quoteIdent(name)
+		if old[name].Legacy {
+			stmt = "DROP EXTERNAL FUNCTION " + quoteIdent(name)
+		}
+		m.add(phaseDropRoutines, stmt)
+	}
+}
+
+func diffPackages(m *migration, source, target []domain.Package) {
+	old := make(map[string]domain.Package)
+	for _, p := range target {
+		old[p.Name] = p
+	}
+	for _, p := range source {
+		o, ok := old[p.Name]
+		delete(old, p.Name)
+		headerChanged := !ok || normalizeSQL(p.Header) != normalizeSQL(o.Header) || p.SQLSecurity != o.SQLSecurity
+		bodyChanged := !ok || normalizeSQL(p.Body) != normalizeSQL(o.Body)
+		if !headerChanged && !bodyChanged {
+			continue
+		}
+		if ok {
+			m.change("PACKAGE", "", p.Name, "ALTER")
+		} else {
+			m.change("PACKAGE", "", p.Name, "CREATE")
+		}
+		if headerChanged {
+			m.addPSQL(phasePackageHeaders, packageHeaderSQL(p))
+		}
+		// Altering the header invalidates the body, so it is recreated as well.
+		if p.Body != "" {
+			m.addPSQL(phasePackageBodies, packageBodySQL(p))
+		}
+	}
+	for _, name := range sortedKeys(old) {
+		m.change("PACKAGE", "", name, "DROP")
+		m.add(phaseDropRoutines, "DROP PACKAGE "+quoteIdent(name))
+	}
+}
+
+func triggerEqual(a, b domain.Trigger) bool {
+	return a.Relation == b.Relation && a.Event == b.Event && a.Position == b.Position && a.Active == b.Active &&
+		a.Engine == b.Engine && a.EntryPoint == b.EntryPoint && a.SQLSecurity == b.SQLSecurity &&
+		normalizeSQL(a.Source) == normalizeSQL(b.Source)
+}
+
+func diffTriggers(m *migration, source, target []domain.Trigger, droppedTables, recreatedViews map[string]bool) {
+	old := make(map[string]domain.Trigger)
+	for _, t := range target {
+		old[t.Name] = t
+	}
+	for _, t := range source {
+		o, ok := old[t.Name]
+		delete(old, t.Name)
+		switch {
+		case !ok:
+			m.change("TRIGGER", t.Relation, t.Name, "CREATE")
+		case !triggerEqual(t, o):
+			var details []string
+			details = append(details, changed("event", o.Event, t.Event)...)
+			details = append(details, changed("active", boolText(o.Active), boolText(t.Active))...)
+			details = append(details, changed("position", fmt.Sprint(o.Position), fmt.Sprint(t.Position))...)
+			m.change("TRIGGER", t.Relation, t.Name, "ALTER", details...)
+			if t.Relation != o.Relation {
+				m.add(phaseDropTriggers, "DROP TRIGGER "+quoteIdent(o.Name))
+			}
+		case recreatedViews[t.Relation]:
+			// Dropping the view dropped its triggers.
+			m.change("TRIGGER", t.Relation, t.Name, "ALTER", "recreated with its view")
+		default:
+			continue
+		}
+		m.addPSQL(phaseTriggers, triggerSQL(t))
+	}
+	for _, name := range sortedKeys(old) {
+		if droppedTables[old[name].Relation] {
+			continue
+		}
+		m.change("TRIGGER", old[name].Relation, name, "DROP")
+		m.add(phaseDropTriggers, "DROP TRIGGER "+quoteIdent(name))
+	}
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
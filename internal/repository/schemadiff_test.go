@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"firebird-web-admin/internal/domain"
+	"strings"
+	"testing"
+)
+
+func TestDiffSchemas(t *testing.T) {
+	target := &domain.Schema{
+		Tables: []domain.TableDefinition{
+			{
+				Name: "CUSTOMER",
+				Kind: "TABLE",
+				Columns: []domain.TableColumn{
+					{Name: "ID", Type: "INTEGER", NotNull: true},
+					{Name: "NAME", Type: "VARCHAR(50)"},
+					{Name: "OBSOLETE", Type: "INTEGER"},
+				},
+				Constraints: []domain.Constraint{{Name: "INTEG_1", Type: "PRIMARY KEY", Columns: []string{"ID"}, Index: "RDB$PRIMARY1"}},
+			},
+			{Name: "OLD_TABLE", Kind: "TABLE", Columns: []domain.TableColumn{{Name: "ID", Type: "INTEGER"}}},
+		},
+		Views: []domain.ViewDefinition{
+			{Name: "V_OLD", Columns: []domain.TableColumn{{Name: "ID"}}, Source: "SELECT ID FROM CUSTOMER"},
+		},
+		Triggers: []domain.Trigger{
+			{Name: "OLD_TABLE_BI", Relation: "OLD_TABLE", Kind: "TABLE", Event: "BEFORE INSERT", Active: true, Source: "AS BEGIN END"},
+		},
+	}
+	source := &domain.Schema{
+		Sequences: []domain.Sequence{{Name: "GEN_CUSTOMER", Increment: 1}},
+		Tables: []domain.TableDefinition{
+			{
+				Name: "CUSTOMER",
+				Kind: "TABLE",
+				Columns: []domain.TableColumn{
+					{Name: "ID", Type: "INTEGER", NotNull: true},
+					{Name: "NAME", Type: "VARCHAR(100)", NotNull: true},
+					{Name: "EMAIL", Type: "VARCHAR(255)"},
+				},
+				// Same primary key under a different system name must not be reported.
+				Constraints: []domain.Constraint{
+					{Name: "INTEG_7", Type: "PRIMARY KEY", Columns: []string{"ID"}, Index: "RDB$PRIMARY7"},
+					{Name: "UQ_CUSTOMER_EMAIL", Type: "UNIQUE", Columns: []string{"EMAIL"}, Index: "UQ_CUSTOMER_EMAIL"},
+				},
+			},
+		},
+		Procedures: []domain.Routine{
+			{Name: "NEW_PROC", Inputs: []domain.ProcedureParameter{{Name: "A", Type: "INTEGER"}}, Source: "BEGIN\n  EXIT;\nEND"},
+		},
+	}
+
+	diff := DiffSchemas(source, target)
+
+	expected := []string{
+		`DROP VIEW "V_OLD";`,
+		`CREATE SEQUENCE "GEN_CUSTOMER";`,
+		`CREATE OR ALTER PROCEDURE "NEW_PROC"`,
+		`ALTER TABLE "CUSTOMER" ALTER "NAME" TYPE VARCHAR(100);`,
+		`ALTER TABLE "CUSTOMER" ALTER "NAME" SET NOT NULL;`,
+		`ALTER TABLE "CUSTOMER" ADD "EMAIL" VARCHAR(255);`,
+		`ALTER TABLE "CUSTOMER" DROP "OBSOLETE";`,
+		`ALTER TABLE "CUSTOMER" ADD CONSTRAINT "UQ_CUSTOMER_EMAIL" UNIQUE ("EMAIL");`,
+		`DROP TABLE "OLD_TABLE";`,
+	}
+	last := -1
+	for _, e := range expected {
+		idx := strings.Index(diff.Script, e)
+		if idx == -1 {
+			t.Fatalf("script does not contain %q\n%s", e, diff.Script)
+		}
+		if idx < last {
+			t.Errorf("statement %q is out of order\n%s", e, diff.Script)
+		}
+		last = idx
+	}
+
+	if strings.Contains(diff.Script, "PRIMARY KEY") {
+		t.Errorf("unchanged primary key should not be migrated\n%s", diff.Script)
+	}
+	// Triggers of dropped tables are removed with the table.
+	if strings.Contains(diff.Script, "DROP TRIGGER") {
+		t.Errorf("trigger of dropped table should not be dropped separately\n%s", diff.Script)
+	}
+
+	found := false
+	for _, c := range diff.Changes {
+		if c.ObjectType == "COLUMN" && c.Parent == "CUSTOMER" && c.Name == "NAME" && c.Action == "ALTER" {
+			found = true
+			if len(c.Details) != 2 {
+				t.Errorf("column change details = %v, want type and not null", c.Details)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("changes do not report CUSTOMER.NAME: %+v", diff.Changes)
+	}
+}
+
+func TestDiffSchemasIdentical(t *testing.T) {
+	schema := &domain.Schema{
+		Tables: []domain.TableDefinition{{Name: "T", Kind: "TABLE", Columns: []domain.TableColumn{{Name: "ID", Type: "INTEGER"}}}},
+		Views:  []domain.ViewDefinition{{Name: "V", Columns: []domain.TableColumn{{Name: "ID"}}, Source: "SELECT ID\nFROM T"}},
+	}
+	reformatted := &domain.Schema{
+		Tables: schema.Tables,
+		Views:  []domain.ViewDefinition{{Name: "V", Columns: []domain.TableColumn{{Name: "ID"}}, Source: "SELECT ID  FROM T"}},
+	}
+
+	diff := DiffSchemas(schema, reformatted)
+	if len(diff.Changes) != 0 || diff.Script != "" {
+		t.Errorf("DiffSchemas() = %+v, want no changes", diff)
+	}
+}
+
+func TestDiffSchemasRecreatesDependentViews(t *testing.T) {
+	table := func(nameType string) []domain.TableDefinition {
+		return []domain.TableDefinition{{Name: "T", Kind: "TABLE", Columns: []domain.TableColumn{
+			{Name: "ID", Type: "INTEGER"},
+			{Name: "NAME", Type: nameType},
+		}}}
+	}
+	views := []domain.ViewDefinition{
+		{Name: "V_IDS", Columns: []domain.TableColumn{{Name: "ID"}}, Source: "SELECT ID FROM T", UsesColumns: map[string][]string{"T": {"ID"}}},
+		{Name: "V_NAMES", Columns: []domain.TableColumn{{Name: "NAME"}}, Source: "SELECT NAME FROM T", UsesColumns: map[string][]string{"T": {"NAME"}}},
+		{Name: "V_TOP", Columns: []domain.TableColumn{{Name: "NAME"}}, Source: "SELECT FIRST 1 NAME FROM V_NAMES", DependsOn: []string{"V_NAMES"}},
+	}
+	triggers := []domain.Trigger{
+		{Name: "V_NAMES_BI", Relation: "V_NAMES", Kind: "TABLE", Event: "BEFORE INSERT", Active: true, Source: "AS BEGIN END"},
+	}
+	target := &domain.Schema{Tables: table("VARCHAR(50)"), Views: views, Triggers: triggers}
+	source := &domain.Schema{Tables: table("VARCHAR(100)"), Views: views, Triggers: triggers}
+
+	diff := DiffSchemas(source, target)
+
+	expected := []string{
+		`DROP VIEW "V_TOP";`,
+		`DROP VIEW "V_NAMES";`,
+		`ALTER TABLE "T" ALTER "NAME" TYPE VARCHAR(100);`,
+		`CREATE OR ALTER VIEW "V_NAMES"`,
+		`CREATE OR ALTER VIEW "V_TOP"`,
+		`TRIGGER "V_NAMES_BI"`,
+	}
+	last := -1
+	for _, e := range expected {
+		idx := strings.Index(diff.Script, e)
+		if idx == -1 {
+			t.Fatalf("script does not contain %q\n%s", e, diff.Script)
+		}
+		if idx < last {
+			t.Errorf("statement %q is out of order\n%s", e, diff.Script)
+		}
+		last = idx
+	}
+	if strings.Contains(diff.Script, "V_IDS") {
+		t.Errorf("view without changed columns should be kept\n%s", diff.Script)
+	}
+}
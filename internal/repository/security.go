@@ -0,0 +1,362 @@
+package repository
+
+import (
+	"database/sql"
+	"firebird-web-admin/internal/domain"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// symbolName matches names that USING PLUGIN and TAGS take unquoted.
+var symbolName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_$]*$`)
+
+// passwordClause matches the password of a CREATE or ALTER USER statement for the log.
+var passwordClause = regexp.MustCompile(`PASSWORD '(?:[^']|'')*'`)
+
+// ListUsers returns the users of the security database with their tags. Users without
+// administrator rights only see themselves.
+func (r *FirebirdRepository) ListUsers(params domain.ConnectionParams) ([]domain.User, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`
+		SELECT SEC$USER_NAME, SEC$FIRST_NAME, SEC$MIDDLE_NAME, SEC$LAST_NAME, SEC$PLUGIN,
+			SEC$ACTIVE, SEC$ADMIN, SEC$DESCRIPTION
+		FROM SEC$USERS
+		ORDER BY SEC$USER_NAME, SEC$PLUGIN
+	`)
+	if err != nil {
+		log.Printf("ListUsers error: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []domain.User{}
+	index := make(map[string]int)
+	for rows.Next() {
+		var u domain.User
+		var first, middle, last, plugin, description sql.NullString
+		var active, admin sql.NullBool
+		if err := rows.Scan(&u.Name, &first, &middle, &last, &plugin, &active, &admin, &description); err != nil {
+			return nil, err
+		}
+		u.Name = strings.TrimSpace(u.Name)
+		u.FirstName = strings.TrimSpace(first.String)
+		u.MiddleName = strings.TrimSpace(middle.String)
+		u.LastName = strings.TrimSpace(last.String)
+		u.Plugin = strings.TrimSpace(plugin.String)
+		u.Active = active.Bool
+		u.Admin = admin.Bool
+		u.Description = description.String
+		u.Tags = make(map[string]string)
+		index[u.Name+"\x00"+u.Plugin] = len(users)
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tags, err := db.Query(`SELECT SEC$USER_NAME, SEC$PLUGIN, SEC$KEY, SEC$VALUE FROM SEC$USER_ATTRIBUTES`)
+	if err != nil {
+		log.Printf("ListUsers tags error: %v", err)
+		return nil, err
+	}
+	defer tags.Close()
+	for tags.Next() {
+		var user, plugin, key, value sql.NullString
+		if err := tags.Scan(&user, &plugin, &key, &value); err != nil {
+			return nil, err
+		}
+		if i, ok := index[strings.TrimSpace(user.String)+"\x00"+strings.TrimSpace(plugin.String)]; ok {
+			users[i].Tags[strings.TrimSpace(key.String)] = value.String
+		}
+	}
+	return users, tags.Err()
+}
+
+func (r *FirebirdRepository) CreateUser(params domain.ConnectionParams, req domain.UserRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Password == "" {
+		return invalidf("password is required")
+	}
+	stmt, err := userSQL("CREATE", req)
+	if err != nil {
+		return err
+	}
+	return r.execUserDDL(params, stmt, "", "")
+}
+
+// AlterUser changes the fields of the request that are set.
+func (r *FirebirdRepository) AlterUser(params domain.ConnectionParams, name string, req domain.UserRequest) error {
+	req.Name = objectName(name)
+	stmt, err := userSQL("ALTER", req)
+	if err != nil {
+		return err
+	}
+	return r.execUserDDL(params, stmt, req.Name, req.Plugin)
+}
+
+// DropUser drops a user, from the given user manager plugin or the default one.
+func (r *FirebirdRepository) DropUser(params domain.ConnectionParams, name string, plugin string) error {
+	name = objectName(name)
+	stmt := "DROP USER " + quoteIdent(name)
+	if plugin != "" {
+		if !symbolName.MatchString(plugin) {
+			return invalidf("invalid plugin name %q", plugin)
+		}
+		stmt += " USING PLUGIN " + plugin
+	}
+	return r.execUserDDL(params, stmt, name, plugin)
+}
+
+// userSQL renders CREATE USER or ALTER USER. Tags are sorted by key.
+func userSQL(verb string, req domain.UserRequest) (string, error) {
+	if req.Name == "" {
+		return "", invalidf("user name is required")
+	}
+	var opts []string
+	if req.Password != "" {
+		opts = append(opts, "PASSWORD "+quoteString(req.Password))
+	}
+	for _, f := range []struct {
+		keyword string
+		value   *string
+	}{{"FIRSTNAME", req.FirstName}, {"MIDDLENAME", req.MiddleName}, {"LASTNAME", req.LastName}} {
+		if f.value != nil {
+			opts = append(opts, f.keyword+" "+quoteString(*f.value))
+		}
+	}
+	if req.Active != nil {
+		if *req.Active {
+			opts = append(opts, "ACTIVE")
+		} else {
+			opts = append(opts, "INACTIVE")
+		}
+	}
+	if req.Plugin != "" {
+		if !symbolName.MatchString(req.Plugin) {
+			return "", invalidf("invalid plugin name %q", req.Plugin)
+		}
+		opts = append(opts, "USING PLUGIN "+req.Plugin)
+	}
+	if req.Admin != nil {
+		switch {
+		case *req.Admin:
+			opts = append(opts, "GRANT ADMIN ROLE")
+		case verb == "ALTER":
+			opts = append(opts, "REVOKE ADMIN ROLE")
+		}
+	}
+
+	keys := make([]string, 0, len(req.Tags))
+	for k := range req.Tags {
+		if !symbolName.MatchString(k) {
+			return "", invalidf("invalid tag name %q", k)
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var tags []string
+	for _, k := range keys {
+		switch {
+		case req.Tags[k] != "":
+			tags = append(tags, k+" = "+quoteString(req.Tags[k]))
+		case verb == "ALTER":
+			tags = append(tags, "DROP "+k)
+		}
+	}
+	if len(tags) > 0 {
+		opts = append(opts, "TAGS ("+strings.Join(tags, ", ")+")")
+	}
+
+	if len(opts) == 0 {
+		return "", invalidf("nothing to change")
+	}
+	keyword := ""
+	if verb == "ALTER" {
+		keyword = " SET"
+	}
+	return fmt.Sprintf("%s USER %s%s %s", verb, quoteIdent(req.Name), keyword, strings.Join(opts, " ")), nil
+}
+
+// execUserDDL is execDDL for user statements: the password is not written to the log.
+// When user is set, the statement changes that user, of the given plugin if any, and
+// fails with NotFoundError when SEC$USERS does not list it.
+func (r *FirebirdRepository) execUserDDL(params domain.ConnectionParams, stmt string, user string, plugin string) error {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if user != "" {
+		found, err := rowExists(db, `
+			SELECT 1 FROM SEC$USERS
+			WHERE SEC$USER_NAME = ? AND (CAST(? AS VARCHAR(63)) = '' OR SEC$PLUGIN = ?)
+		`, user, plugin, plugin)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return &domain.NotFoundError{ObjectType: "user", Name: user}
+		}
+	}
+
+	log.Printf("DDL: %s", passwordClause.ReplaceAllString(stmt, "PASSWORD '***'"))
+	if _, err := db.Exec(stmt); err != nil {
+		log.Printf("DDL error: %v", err)
+		return sqlError(err, 0)
+	}
+	return nil
+}
+
+func (r *FirebirdRepository) ListRoles(params domain.ConnectionParams) ([]domain.Role, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	roles, err := loadRoles(db)
+	if err != nil {
+		log.Printf("ListRoles error: %v", err)
+		return nil, err
+	}
+	if roles == nil {
+		roles = []domain.Role{}
+	}
+	return roles, nil
+}
+
+func (r *FirebirdRepository) CreateRole(params domain.ConnectionParams, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return invalidf("role name is required")
+	}
+	return r.execDDL(params, "CREATE ROLE "+quoteIdent(name))
+}
+
+func (r *FirebirdRepository) DropRole(params domain.ConnectionParams, name string) error {
+	name = objectName(name)
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return err
+	}
+	found, err := rowExists(db, "SELECT 1 FROM RDB$ROLES WHERE RDB$ROLE_NAME = ?", name)
+	db.Close()
+	if err != nil {
+		return err
+	}
+	if !found {
+		return &domain.NotFoundError{ObjectType: "role", Name: name}
+	}
+	return r.execDDL(params, "DROP ROLE "+quoteIdent(name))
+}
+
+// rowExists reports whether query returns a row.
+func rowExists(db *sql.DB, query string, args ...interface{}) (bool, error) {
+	var one int
+	err := db.QueryRow(query, args...).Scan(&one)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ListGrants returns the explicit grants on an object, to a grantee, or all of them when
+// both are empty. Role memberships have the role as object.
+func (r *FirebirdRepository) ListGrants(params domain.ConnectionParams, object string, grantee string) ([]domain.Grant, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	all, err := loadGrants(db)
+	if err != nil {
+		log.Printf("ListGrants error: %v", err)
+		return nil, err
+	}
+	grants := []domain.Grant{}
+	for _, g := range all {
+		if (object == "" || g.Object == object) && (grantee == "" || g.Grantee == grantee) {
+			grants = append(grants, g)
+		}
+	}
+	return grants, nil
+}
+
+// GrantPrivileges grants roles and privileges one statement at a time and stops at the
+// first error.
+func (r *FirebirdRepository) GrantPrivileges(params domain.ConnectionParams, grants []domain.Grant) error {
+	return r.execGrants(params, grants, grantSQL)
+}
+
+// RevokePrivileges revokes roles and privileges one statement at a time and stops at the
+// first error.
+func (r *FirebirdRepository) RevokePrivileges(params domain.ConnectionParams, grants []domain.Grant) error {
+	return r.execGrants(params, grants, revokeSQL)
+}
+
+func (r *FirebirdRepository) execGrants(params domain.ConnectionParams, grants []domain.Grant, render func(domain.Grant) string) error {
+	if len(grants) == 0 {
+		return invalidf("no privileges given")
+	}
+	stmts := make([]string, len(grants))
+	for i, g := range grants {
+		g = normalizeGrant(g)
+		if stmts[i] = render(g); stmts[i] == "" {
+			return invalidf("unsupported privilege %q on %s", g.Privilege, g.Object)
+		}
+	}
+	for _, stmt := range stmts {
+		if err := r.execDDL(params, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeGrant accepts privilege names (SELECT, ..., ROLE) besides the RDB$PRIVILEGE
+// codes and makes users the default grantee type.
+func normalizeGrant(g domain.Grant) domain.Grant {
+	g.Privilege = strings.ToUpper(strings.TrimSpace(g.Privilege))
+	if g.Privilege == "ROLE" {
+		g.Privilege = "M"
+	}
+	for code, name := range privilegeNames {
+		if g.Privilege == name {
+			g.Privilege = code
+		}
+	}
+	if g.GranteeType == objRelation {
+		g.GranteeType = objUser
+	}
+	return g
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"errors"
+	"firebird-web-admin/internal/domain"
+	"testing"
+)
+
+func TestUserSQL(t *testing.T) {
+	str := func(s string) *string { return &s }
+	yes, no := true, false
+
+	tests := []struct {
+		name    string
+		verb    string
+		req     domain.UserRequest
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Create with all options",
+			verb: "CREATE",
+			req: domain.UserRequest{Name: "APP", Password: "it's", FirstName: str("App"), LastName: str("Login"),
+				Active: &yes, Admin: &yes, Plugin: "Srp", Tags: map[string]string{"TEAM": "billing", "ENV": "prod"}},
+			want: `CREATE USER "APP" PASSWORD 'it''s' FIRSTNAME 'App' LASTNAME 'Login' ACTIVE USING PLUGIN Srp GRANT ADMIN ROLE TAGS (ENV = 'prod', TEAM = 'billing')`,
+		},
+		{
+			name: "Alter deactivates and drops a tag",
+			verb: "ALTER",
+			req:  domain.UserRequest{Name: "APP", Active: &no, Admin: &no, Tags: map[string]string{"TEAM": ""}},
+			want: `ALTER USER "APP" SET INACTIVE REVOKE ADMIN ROLE TAGS (DROP TEAM)`,
+		},
+		{
+			name: "Alter password only",
+			verb: "ALTER",
+			req:  domain.UserRequest{Name: "APP", Password: "secret"},
+			want: `ALTER USER "APP" SET PASSWORD 'secret'`,
+		},
+		{name: "Nothing to change", verb: "ALTER", req: domain.UserRequest{Name: "APP"}, wantErr: true},
+		{name: "Missing name", verb: "CREATE", req: domain.UserRequest{Password: "x"}, wantErr: true},
+		{name: "Invalid tag", verb: "CREATE", req: domain.UserRequest{Name: "APP", Password: "x", Tags: map[string]string{"a b": "c"}}, wantErr: true},
+		{name: "Invalid plugin", verb: "CREATE", req: domain.UserRequest{Name: "APP", Password: "x", Plugin: "Srp; DROP"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := userSQL(tt.verb, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("userSQL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var invalid *domain.ValidationError
+			if err != nil && !errors.As(err, &invalid) {
+				t.Errorf("userSQL() error = %T, want *domain.ValidationError", err)
+			}
+			if got != tt.want {
+				t.Errorf("userSQL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrantAndRevokeSQL(t *testing.T) {
+	tests := []struct {
+		name   string
+		grant  domain.Grant
+		grantS string
+		revoke string
+	}{
+		{
+			name:   "Select by privilege name",
+			grant:  domain.Grant{Grantee: "APP", Privilege: "select", Object: "EMPLOYEE"},
+			grantS: `GRANT SELECT ON "EMPLOYEE" TO USER "APP"`,
+			revoke: `REVOKE SELECT ON "EMPLOYEE" FROM USER "APP"`,
+		},
+		{
+			name:   "Column update to a role with grant option",
+			grant:  domain.Grant{Grantee: "CLERK", GranteeType: objRole, Privilege: "U", Object: "EMPLOYEE", Field: "SALARY", GrantOption: 1},
+			grantS: `GRANT UPDATE ("SALARY") ON "EMPLOYEE" TO ROLE "CLERK" WITH GRANT OPTION`,
+			revoke: `REVOKE UPDATE ("SALARY") ON "EMPLOYEE" FROM ROLE "CLERK"`,
+		},
+		{
+			name:   "Role membership",
+			grant:  domain.Grant{Grantee: "APP", Privilege: "ROLE", Object: "CLERK", GrantOption: 1},
+			grantS: `GRANT "CLERK" TO USER "APP" WITH ADMIN OPTION`,
+			revoke: `REVOKE "CLERK" FROM USER "APP"`,
+		},
+		{
+			name:   "Execute to a procedure",
+			grant:  domain.Grant{Grantee: "ADD_EMP", GranteeType: objProcedure, Privilege: "X", Object: "GET_ID", ObjectType: objFunction},
+			grantS: `GRANT EXECUTE ON FUNCTION "GET_ID" TO PROCEDURE "ADD_EMP"`,
+			revoke: `REVOKE EXECUTE ON FUNCTION "GET_ID" FROM PROCEDURE "ADD_EMP"`,
+		},
+		{
+			name:   "Metadata privilege",
+			grant:  domain.Grant{Grantee: "DEV", Privilege: "C", ObjectType: 22},
+			grantS: `GRANT CREATE TABLE TO USER "DEV"`,
+			revoke: `REVOKE CREATE TABLE FROM USER "DEV"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := normalizeGrant(tt.grant)
+			if got := grantSQL(g); got != tt.grantS {
+				t.Errorf("grantSQL() = %q, want %q", got, tt.grantS)
+			}
+			if got := revokeSQL(g); got != tt.revoke {
+				t.Errorf("revokeSQL() = %q, want %q", got, tt.revoke)
+			}
+		})
+	}
+}
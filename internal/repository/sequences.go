@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"database/sql"
+	"firebird-web-admin/internal/domain"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// ListSequences returns user sequences and identity column generators with their
+// current values and the objects that use them.
+func (r *FirebirdRepository) ListSequences(params domain.ConnectionParams) ([]domain.Sequence, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	sequences, err := loadSequenceDetails(db, "")
+	if err != nil {
+		log.Printf("ListSequences error: %v", err)
+		return nil, err
+	}
+	return sequences, nil
+}
+
+func (r *FirebirdRepository) GetSequence(params domain.ConnectionParams, name string) (*domain.Sequence, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	name = objectName(name)
+	sequences, err := loadSequenceDetails(db, name)
+	if err != nil {
+		log.Printf("GetSequence error: %v", err)
+		return nil, err
+	}
+	if len(sequences) == 0 {
+		return nil, &domain.NotFoundError{ObjectType: "sequence", Name: name}
+	}
+	return &sequences[0], nil
+}
+
+func (r *FirebirdRepository) CreateSequence(params domain.ConnectionParams, s domain.Sequence) error {
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Name == "" {
+		return invalidf("sequence name is required")
+	}
+	if s.Increment == 0 {
+		s.Increment = 1
+	}
+	return r.execDDL(params, sequenceSQL(s))
+}
+
+// RestartSequence runs ALTER SEQUENCE ... RESTART, using the initial value when value is nil.
+// Note that since Firebird 4 the next generated value is the restart value itself,
+// while Firebird 3 adds the increment first.
+func (r *FirebirdRepository) RestartSequence(params domain.ConnectionParams, name string, value *int64) error {
+	return r.execDDL(params, restartSequenceSQL(objectName(name), value))
+}
+
+// SetSequenceValue sets the current value, so the next generated value is value plus the increment.
+func (r *FirebirdRepository) SetSequenceValue(params domain.ConnectionParams, name string, value int64) error {
+	return r.execDDL(params, setSequenceValueSQL(objectName(name), value))
+}
+
+func (r *FirebirdRepository) DropSequence(params domain.ConnectionParams, name string) error {
+	return r.execDDL(params, "DROP SEQUENCE "+quoteIdent(objectName(name)))
+}
+
+// loadSequenceDetails loads the sequences, or only the named one, with their current values
+// and the objects that use them.
+func loadSequenceDetails(db *sql.DB, name string) ([]domain.Sequence, error) {
+	sequences, err := loadSequences(db, true, name)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := loadSequenceUsage(db)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sequences {
+		s := &sequences[i]
+		s.UsedBy = usage[s.Name]
+		// GEN_ID needs the USAGE privilege; a sequence we may not read is still listed.
+		var value int64
+		if err := db.QueryRow(fmt.Sprintf("SELECT GEN_ID(%s, 0) FROM RDB$DATABASE", quoteIdent(s.Name))).Scan(&value); err == nil {
+			s.CurrentValue = &value
+		} else {
+			log.Printf("Current value of sequence %s: %v", s.Name, err)
+		}
+	}
+	return sequences, nil
+}
+
+// loadSequenceUsage returns, for each sequence, the objects whose source references it
+// and the identity columns it generates values for.
+func loadSequenceUsage(db *sql.DB) (map[string][]domain.ObjectUsage, error) {
+	usage, err := loadDependentObjects(db, objGenerator)
+	if err != nil {
+		return nil, err
+	}
+
+	identityRows, err := db.Query(`
+		SELECT RDB$GENERATOR_NAME, RDB$RELATION_NAME, RDB$FIELD_NAME
+		FROM RDB$RELATION_FIELDS
+		WHERE RDB$GENERATOR_NAME IS NOT NULL
+		ORDER BY RDB$RELATION_NAME, RDB$FIELD_POSITION
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer identityRows.Close()
+
+	for identityRows.Next() {
+		var sequence, relation, field string
+		if err := identityRows.Scan(&sequence, &relation, &field); err != nil {
+			return nil, err
+		}
+		sequence = strings.TrimSpace(sequence)
+		usage[sequence] = append(usage[sequence], domain.ObjectUsage{
+			ObjectType: "IDENTITY COLUMN",
+			Name:       strings.TrimSpace(relation),
+			Column:     strings.TrimSpace(field),
+		})
+	}
+	return usage, identityRows.Err()
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"firebird-web-admin/internal/domain"
+	"testing"
+)
+
+func TestSequenceSQL(t *testing.T) {
+	start := int64(100)
+	negative := int64(-5)
+
+	tests := []struct {
+		name     string
+		got      string
+		expected string
+	}{
+		{name: "Defaults", got: sequenceSQL(domain.Sequence{Name: "GEN_CUSTOMER", Increment: 1}), expected: `CREATE SEQUENCE "GEN_CUSTOMER"`},
+		{name: "Start with", got: sequenceSQL(domain.Sequence{Name: "GEN_CUSTOMER", InitialValue: 1000, Increment: 1}), expected: `CREATE SEQUENCE "GEN_CUSTOMER" START WITH 1000`},
+		{name: "Increment by", got: sequenceSQL(domain.Sequence{Name: "GEN_CUSTOMER", Increment: 10}), expected: `CREATE SEQUENCE "GEN_CUSTOMER" INCREMENT BY 10`},
+		{name: "Start with and negative increment", got: sequenceSQL(domain.Sequence{Name: "GEN_DOWN", InitialValue: -1, Increment: -2}), expected: `CREATE SEQUENCE "GEN_DOWN" START WITH -1 INCREMENT BY -2`},
+		{name: "Quoted name", got: sequenceSQL(domain.Sequence{Name: "gen \"x\"", Increment: 1}), expected: `CREATE SEQUENCE "gen ""x"""`},
+		{name: "Restart at initial value", got: restartSequenceSQL("GEN_CUSTOMER", nil), expected: `ALTER SEQUENCE "GEN_CUSTOMER" RESTART`},
+		{name: "Restart with value", got: restartSequenceSQL("GEN_CUSTOMER", &start), expected: `ALTER SEQUENCE "GEN_CUSTOMER" RESTART WITH 100`},
+		{name: "Restart quoted with negative value", got: restartSequenceSQL("Gen", &negative), expected: `ALTER SEQUENCE "Gen" RESTART WITH -5`},
+		{name: "Set value", got: setSequenceValueSQL("GEN_CUSTOMER", 42), expected: `SET GENERATOR "GEN_CUSTOMER" TO 42`},
+		{name: "Set value quoted", got: setSequenceValueSQL("gen", 0), expected: `SET GENERATOR "gen" TO 0`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.expected {
+				t.Errorf("got %q, want %q", tt.got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"firebird-web-admin/internal/domain"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/nakagami/firebirdsql"
+)
+
+// validPageSizes are the page sizes a database can be restored with.
+var validPageSizes = map[int32]bool{4096: true, 8192: true, 16384: true, 32768: true}
+
+// serviceTarget splits the database of params into the Services Manager address and the
+// database path or alias on that server. A database without a host is on localhost.
+func serviceTarget(params domain.ConnectionParams) (addr string, database string) {
+	loc, err := parseDatabase(params.Database)
+	if err != nil {
+		return "localhost", params.Database
+	}
+	if addr, err = loc.address(); err != nil {
+		return "localhost", params.Database
+	}
+	return addr, loc.database()
+}
+
+// Backup backs up the connected database to a file on the server and writes the verbose
+// gbak log to w line by line.
+func (r *FirebirdRepository) Backup(params domain.ConnectionParams, req domain.BackupRequest, w io.Writer) error {
+	if strings.TrimSpace(req.BackupFile) == "" {
+		return invalidf("backup file is required")
+	}
+	addr, database := serviceTarget(params)
+
+	opts := firebirdsql.NewBackupOptions()
+	opts.MetadataOnly = req.MetadataOnly
+	opts.GarbageCollect = !req.NoGarbageCollect
+	opts.Transportable = !req.NonTransportable
+	opts.IgnoreChecksums = req.IgnoreChecksums
+	opts.IgnoreLimboTransactions = req.IgnoreLimbo
+
+	return runService(params, addr, w, func(bm *firebirdsql.BackupManager, verbose chan string) error {
+		return bm.Backup(database, req.BackupFile, opts, verbose)
+	})
+}
+
+// Restore restores a backup file on the server to the database of the request, or over
+// the connected database, and writes the verbose gbak log to w line by line.
+func (r *FirebirdRepository) Restore(params domain.ConnectionParams, req domain.RestoreRequest, w io.Writer) error {
+	if strings.TrimSpace(req.BackupFile) == "" {
+		return invalidf("backup file is required")
+	}
+	if req.PageSize != 0 && !validPageSizes[req.PageSize] {
+		return invalidf("invalid page size %d, use 4096, 8192, 16384 or 32768", req.PageSize)
+	}
+	addr, database := serviceTarget(params)
+	if req.Database != "" {
+		database = req.Database
+	}
+
+	opts := firebirdsql.NewRestoreOptions(firebirdsql.WithPageSize(req.PageSize))
+	opts.Replace = req.Replace
+	opts.DeactivateIndexes = req.DeactivateIndexes
+	opts.EnforceConstraints = !req.NoValidity
+	opts.CommitAfterEachTable = req.OneAtATime
+
+	return runService(params, addr, w, func(bm *firebirdsql.BackupManager, verbose chan string) error {
+		return bm.Restore(req.BackupFile, database, opts, verbose)
+	})
+}
+
+// runService runs a backup or restore and copies its verbose output to w, flushing after
+// every line when w can flush. The output is drained even after a write error so that the
+// service call can finish.
+func runService(params domain.ConnectionParams, addr string, w io.Writer, run func(*firebirdsql.BackupManager, chan string) error) error {
+	bm, err := firebirdsql.NewBackupManager(addr, params.User, params.Password, serviceManagerOptions(params))
+	if err != nil {
+		return err
+	}
+
+	verbose := make(chan string)
+	done := make(chan error, 1)
+	go func() {
+		done <- run(bm, verbose)
+		close(verbose)
+	}()
+
+	var writeErr error
+	for line := range verbose {
+		if writeErr != nil {
+			continue
+		}
+		if _, writeErr = fmt.Fprintln(w, line); writeErr == nil {
+			if f, ok := w.(interface{ Flush() }); ok {
+				f.Flush()
+			}
+		}
+	}
+	if err := <-done; err != nil {
+		log.Printf("Service error: %v", err)
+		return err
+	}
+	return writeErr
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"firebird-web-admin/internal/domain"
+	"testing"
+)
+
+func TestServiceTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		database string
+		addr     string
+		path     string
+	}{
+		{name: "Host and path", database: "localhost:/var/lib/firebird/data/employee.fdb", addr: "localhost", path: "/var/lib/firebird/data/employee.fdb"},
+		{name: "Host, port and path", database: "db.example.com/3051:/data/app.fdb", addr: "db.example.com:3051", path: "/data/app.fdb"},
+		{name: "Host and alias", database: "10.0.0.5:employee", addr: "10.0.0.5", path: "employee"},
+		{name: "Alias only", database: "employee", addr: "localhost", path: "employee"},
+		{name: "IPv6 host", database: "[::1]:employee", addr: "[::1]:3050", path: "employee"},
+		{name: "inet URL", database: "inet://db.example.com:3051//data/app.fdb", addr: "db.example.com:3051", path: "/data/app.fdb"},
+		{name: "Local Windows path", database: `C:\data\app.fdb`, addr: "localhost", path: `C:\data\app.fdb`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, path := serviceTarget(domain.ConnectionParams{Database: tt.database})
+			if addr != tt.addr || path != tt.path {
+				t.Errorf("serviceTarget() = %q, %q, want %q, %q", addr, path, tt.addr, tt.path)
+			}
+		})
+	}
+}
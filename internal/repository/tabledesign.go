@@ -0,0 +1,340 @@
+package repository
+
+import (
+	"database/sql"
+	"firebird-web-admin/internal/domain"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// GetTableDesign returns a table with its columns, constraints and indexes in the form
+// the table designer edits and sends back.
+func (r *FirebirdRepository) GetTableDesign(params domain.ConnectionParams, name string) (*domain.TableDesign, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	name = objectName(name)
+	design, err := loadTableDesign(db, name)
+	if err != nil {
+		log.Printf("GetTableDesign error: %v", err)
+		return nil, err
+	}
+	if design == nil {
+		return nil, &domain.NotFoundError{ObjectType: "table", Name: name}
+	}
+	return design, nil
+}
+
+// CreateTable creates a table from a design. With dryRun the statements are only returned.
+func (r *FirebirdRepository) CreateTable(params domain.ConnectionParams, design domain.TableDesign, dryRun bool) (*domain.TableDesignResult, error) {
+	return r.applyTableDesign(params, "", design, dryRun)
+}
+
+// AlterTable alters the named table to match a design with the minimal ALTER TABLE and
+// index statements. With dryRun the statements are only returned.
+func (r *FirebirdRepository) AlterTable(params domain.ConnectionParams, name string, design domain.TableDesign, dryRun bool) (*domain.TableDesignResult, error) {
+	if name == "" {
+		return nil, invalidf("table name is required")
+	}
+	return r.applyTableDesign(params, objectName(name), design, dryRun)
+}
+
+// applyTableDesign creates the table when name is empty and alters the named table otherwise.
+func (r *FirebirdRepository) applyTableDesign(params domain.ConnectionParams, name string, design domain.TableDesign, dryRun bool) (*domain.TableDesignResult, error) {
+	if err := normalizeTableDesign(&design); err != nil {
+		return nil, err
+	}
+	if name != "" && design.Name != name {
+		return nil, invalidf("tables cannot be renamed, the design is for %s", design.Name)
+	}
+
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	current, err := loadTableDesign(db, design.Name)
+	db.Close()
+	if err != nil {
+		log.Printf("applyTableDesign error: %v", err)
+		return nil, err
+	}
+	switch {
+	case name == "" && current != nil:
+		return nil, &domain.ConflictError{Message: fmt.Sprintf("table %s already exists", design.Name)}
+	case name != "" && current == nil:
+		return nil, &domain.NotFoundError{ObjectType: "table", Name: name}
+	}
+
+	m := tableDesignMigration(current, design)
+	result := &domain.TableDesignResult{Statements: []string{}, Changes: m.changes, Warnings: m.warnings}
+	for _, stmts := range m.phases {
+		for _, stmt := range stmts {
+			result.Statements = append(result.Statements, stmt.sql)
+		}
+	}
+	if result.Changes == nil {
+		result.Changes = []domain.SchemaChange{}
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	// DDL is committed statement by statement, so a failure leaves the earlier ones applied.
+	for i, stmt := range result.Statements {
+		if err := r.execDDL(params, stmt); err != nil {
+			if i > 0 {
+				log.Printf("applyTableDesign: statement %d of %d failed, the previous ones were applied", i+1, len(result.Statements))
+			}
+			return nil, err
+		}
+	}
+	result.Executed = true
+	return result, nil
+}
+
+// tableDesignMigration diffs a design against the current table, which is nil for a new one,
+// with the rules of the schema compare, and adds column moves and data loss warnings.
+func tableDesignMigration(current *domain.TableDesign, design domain.TableDesign) *migration {
+	m := &migration{}
+	var tables []domain.TableDefinition
+	var indexes []domain.Index
+	if current != nil {
+		tables = []domain.TableDefinition{current.TableDefinition}
+		indexes = current.Indexes
+	}
+	diffTables(m, []domain.TableDefinition{design.TableDefinition}, tables)
+	if current != nil {
+		diffColumnPositions(m, design.Name, design.Columns, current.Columns)
+	}
+	diffIndexes(m, design.Indexes, indexes, nil)
+
+	for _, c := range m.changes {
+		if c.ObjectType == "COLUMN" && c.Action == "DROP" {
+			m.warn("Column %s.%s is dropped, its data is lost", c.Parent, c.Name)
+		}
+	}
+	return m
+}
+
+// diffColumnPositions moves columns with ALTER ... POSITION when the design orders them
+// differently from the table after columns are added at its end.
+func diffColumnPositions(m *migration, table string, source, target []domain.TableColumn) {
+	inSource := make(map[string]bool)
+	for _, c := range source {
+		inSource[c.Name] = true
+	}
+	var order []string
+	inTarget := make(map[string]bool)
+	for _, c := range target {
+		inTarget[c.Name] = true
+		if inSource[c.Name] {
+			order = append(order, c.Name)
+		}
+	}
+	for _, c := range source {
+		if !inTarget[c.Name] {
+			order = append(order, c.Name)
+		}
+	}
+
+	first := len(source)
+	for i, c := range source {
+		if order[i] != c.Name {
+			first = i
+			break
+		}
+	}
+	for i := first; i < len(source); i++ {
+		m.change("COLUMN", table, source[i].Name, "ALTER", fmt.Sprintf("position: %d", i+1))
+		m.add(phaseTables, fmt.Sprintf("ALTER TABLE %s ALTER %s POSITION %d", quoteIdent(table), quoteIdent(source[i].Name), i+1))
+	}
+}
+
+// normalizeTableDesign validates a design received from the designer and brings defaults,
+// checks and referential actions into the form metadata is loaded in.
+func normalizeTableDesign(d *domain.TableDesign) error {
+	d.Name = strings.TrimSpace(d.Name)
+	if d.Name == "" {
+		return invalidf("table name is required")
+	}
+	if d.Kind == "" {
+		d.Kind = "TABLE"
+	}
+	if len(d.Columns) == 0 {
+		return invalidf("table %s needs at least one column", d.Name)
+	}
+
+	columns := make(map[string]bool)
+	for i := range d.Columns {
+		c := &d.Columns[i]
+		c.Name = strings.TrimSpace(c.Name)
+		c.Type = strings.Join(strings.Fields(c.Type), " ")
+		c.Identity = strings.ToUpper(strings.TrimSpace(c.Identity))
+		switch {
+		case c.Name == "":
+			return invalidf("column %d: name is required", i+1)
+		case columns[c.Name]:
+			return invalidf("column %s is defined twice", c.Name)
+		case c.Type == "" && c.Domain == "" && c.Computed == "":
+			return invalidf("column %s: type, domain or computed expression is required", c.Name)
+		case c.Identity != "" && c.Identity != "ALWAYS" && c.Identity != "BY DEFAULT":
+			return invalidf("column %s: identity must be ALWAYS or BY DEFAULT", c.Name)
+		}
+		columns[c.Name] = true
+		if c.Default != "" && !strings.HasPrefix(strings.ToUpper(strings.TrimSpace(c.Default)), "DEFAULT") {
+			c.Default = "DEFAULT " + strings.TrimSpace(c.Default)
+		}
+	}
+
+	hasColumns := func(names []string) error {
+		if len(names) == 0 {
+			return invalidf("columns are required")
+		}
+		for _, n := range names {
+			if !columns[n] {
+				return invalidf("unknown column %s", n)
+			}
+		}
+		return nil
+	}
+	primaryKeys := 0
+	for i := range d.Constraints {
+		c := &d.Constraints[i]
+		c.Name = strings.TrimSpace(c.Name)
+		c.Type = strings.ToUpper(strings.Join(strings.Fields(c.Type), " "))
+		label := c.Name
+		if label == "" {
+			label = fmt.Sprintf("%d", i+1)
+		}
+		var err error
+		switch c.Type {
+		case "PRIMARY KEY":
+			primaryKeys++
+			err = hasColumns(c.Columns)
+		case "UNIQUE":
+			err = hasColumns(c.Columns)
+		case "FOREIGN KEY":
+			err = hasColumns(c.Columns)
+			switch {
+			case err != nil:
+			case c.RefTable == "":
+				err = invalidf("referenced table is required")
+			case len(c.RefColumns) != len(c.Columns):
+				err = invalidf("%d columns reference %d columns", len(c.Columns), len(c.RefColumns))
+			}
+			c.OnUpdate = referentialAction(c.OnUpdate)
+			c.OnDelete = referentialAction(c.OnDelete)
+		case "CHECK":
+			if strings.TrimSpace(c.Check) == "" {
+				err = invalidf("check condition is required")
+			} else if !strings.HasPrefix(strings.ToUpper(strings.TrimSpace(c.Check)), "CHECK") {
+				c.Check = "CHECK " + parenthesize(c.Check)
+			}
+		default:
+			err = invalidf("unknown constraint type %q", c.Type)
+		}
+		if err != nil {
+			return invalidf("constraint %s: %v", label, err)
+		}
+	}
+	if primaryKeys > 1 {
+		return invalidf("table %s can have only one primary key", d.Name)
+	}
+
+	for i := range d.Indexes {
+		idx := &d.Indexes[i]
+		idx.Name = strings.TrimSpace(idx.Name)
+		idx.Relation = d.Name
+		if idx.Name == "" {
+			return invalidf("index %d: name is required", i+1)
+		}
+		if idx.Constraint != "" {
+			// Indexes backing constraints follow the constraints.
+			continue
+		}
+		if idx.Expression == "" {
+			if err := hasColumns(idx.Segments); err != nil {
+				return invalidf("index %s: %v", idx.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// invalidf reports input the client should correct before submitting it again.
+func invalidf(format string, a ...any) error {
+	return &domain.ValidationError{Message: fmt.Sprintf(format, a...)}
+}
+
+// referentialAction returns the rule as RDB$REF_CONSTRAINTS stores it; no rule means RESTRICT.
+func referentialAction(rule string) string {
+	rule = strings.ToUpper(strings.Join(strings.Fields(rule), " "))
+	if rule == "" || rule == "NO ACTION" {
+		return "RESTRICT"
+	}
+	return rule
+}
+
+// loadTableDesign loads a table with its constraints and indexes, or nil when it does not exist.
+func loadTableDesign(db *sql.DB, name string) (*domain.TableDesign, error) {
+	var relType sql.NullInt64
+	var isView int64
+	var externalFile, description sql.NullString
+	err := db.QueryRow(`
+		SELECT RDB$RELATION_TYPE, RDB$EXTERNAL_FILE, CASE WHEN RDB$VIEW_BLR IS NULL THEN 0 ELSE 1 END, RDB$DESCRIPTION
+		FROM RDB$RELATIONS
+		WHERE RDB$RELATION_NAME = ?
+	`, name).Scan(&relType, &externalFile, &isView, &description)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if isView == 1 {
+		return nil, invalidf("%s is a view", name)
+	}
+
+	charset, err := loadDefaultCharset(db)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := loadRelationColumns(db, charset, name)
+	if err != nil {
+		return nil, err
+	}
+	constraints, err := loadConstraints(db, name)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := loadIndexes(db, name)
+	if err != nil {
+		return nil, err
+	}
+	if indexes == nil {
+		indexes = []domain.Index{}
+	}
+	return &domain.TableDesign{
+		TableDefinition: domain.TableDefinition{
+			Name:         name,
+			Kind:         tableKind(relType.Int64),
+			ExternalFile: strings.TrimSpace(externalFile.String),
+			Columns:      columns[name],
+			Constraints:  constraints[name],
+			Description:  description.String,
+		},
+		Indexes: indexes,
+	}, nil
+}
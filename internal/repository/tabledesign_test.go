@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"errors"
+	"firebird-web-admin/internal/domain"
+	"reflect"
+	"testing"
+)
+
+func TestTableDesignMigration(t *testing.T) {
+	current := &domain.TableDesign{
+		TableDefinition: domain.TableDefinition{
+			Name: "CUSTOMER",
+			Kind: "TABLE",
+			Columns: []domain.TableColumn{
+				{Name: "ID", Type: "INTEGER", NotNull: true},
+				{Name: "NAME", Type: "VARCHAR(50)"},
+				{Name: "FAX", Type: "VARCHAR(20)"},
+			},
+			Constraints: []domain.Constraint{
+				{Name: "INTEG_1", Type: "PRIMARY KEY", Columns: []string{"ID"}, Index: "RDB$PRIMARY1"},
+			},
+		},
+		Indexes: []domain.Index{
+			{Name: "RDB$PRIMARY1", Relation: "CUSTOMER", Unique: true, Active: true, Segments: []string{"ID"}, Constraint: "PRIMARY KEY"},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		current    *domain.TableDesign
+		design     domain.TableDesign
+		statements []string
+		warnings   int
+	}{
+		{
+			name:    "Create",
+			current: nil,
+			design: domain.TableDesign{
+				TableDefinition: domain.TableDefinition{
+					Name:    "ORDERS",
+					Kind:    "TABLE",
+					Columns: []domain.TableColumn{{Name: "ID", Type: "INTEGER", NotNull: true}, {Name: "CUSTOMER_ID", Type: "INTEGER"}},
+					Constraints: []domain.Constraint{
+						{Type: "PRIMARY KEY", Columns: []string{"ID"}},
+						{Name: "FK_ORDERS_CUSTOMER", Type: "FOREIGN KEY", Columns: []string{"CUSTOMER_ID"}, RefTable: "CUSTOMER", RefColumns: []string{"ID"}},
+					},
+				},
+				Indexes: []domain.Index{{Name: "IX_ORDERS_CUSTOMER", Relation: "ORDERS", Active: true, Segments: []string{"CUSTOMER_ID"}}},
+			},
+			statements: []string{
+				"CREATE TABLE \"ORDERS\" (\n    \"ID\" INTEGER NOT NULL,\n    \"CUSTOMER_ID\" INTEGER,\n    PRIMARY KEY (\"ID\")\n)",
+				`ALTER TABLE "ORDERS" ADD CONSTRAINT "FK_ORDERS_CUSTOMER" FOREIGN KEY ("CUSTOMER_ID") REFERENCES "CUSTOMER" ("ID")`,
+				`CREATE INDEX "IX_ORDERS_CUSTOMER" ON "ORDERS" ("CUSTOMER_ID")`,
+			},
+		},
+		{
+			name:    "Unchanged",
+			current: current,
+			design:  *current,
+		},
+		{
+			name:    "Add, drop and alter columns",
+			current: current,
+			design: domain.TableDesign{
+				TableDefinition: domain.TableDefinition{
+					Name: "CUSTOMER",
+					Kind: "TABLE",
+					Columns: []domain.TableColumn{
+						{Name: "ID", Type: "INTEGER", NotNull: true},
+						{Name: "NAME", Type: "VARCHAR(80)", NotNull: true},
+						{Name: "EMAIL", Type: "VARCHAR(100)"},
+					},
+					Constraints: []domain.Constraint{{Type: "PRIMARY KEY", Columns: []string{"ID"}}},
+				},
+			},
+			statements: []string{
+				`ALTER TABLE "CUSTOMER" ALTER "NAME" TYPE VARCHAR(80)`,
+				`ALTER TABLE "CUSTOMER" ALTER "NAME" SET NOT NULL`,
+				`ALTER TABLE "CUSTOMER" ADD "EMAIL" VARCHAR(100)`,
+				`ALTER TABLE "CUSTOMER" DROP "FAX"`,
+			},
+			warnings: 1,
+		},
+		{
+			name:    "Insert column in the middle",
+			current: current,
+			design: domain.TableDesign{
+				TableDefinition: domain.TableDefinition{
+					Name: "CUSTOMER",
+					Kind: "TABLE",
+					Columns: []domain.TableColumn{
+						{Name: "ID", Type: "INTEGER", NotNull: true},
+						{Name: "CODE", Type: "CHAR(3)"},
+						{Name: "NAME", Type: "VARCHAR(50)"},
+						{Name: "FAX", Type: "VARCHAR(20)"},
+					},
+					Constraints: current.Constraints,
+				},
+			},
+			statements: []string{
+				`ALTER TABLE "CUSTOMER" ADD "CODE" CHAR(3)`,
+				`ALTER TABLE "CUSTOMER" ALTER "CODE" POSITION 2`,
+				`ALTER TABLE "CUSTOMER" ALTER "NAME" POSITION 3`,
+				`ALTER TABLE "CUSTOMER" ALTER "FAX" POSITION 4`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := tableDesignMigration(tt.current, tt.design)
+			var statements []string
+			for _, stmts := range m.phases {
+				for _, s := range stmts {
+					statements = append(statements, s.sql)
+				}
+			}
+			if !reflect.DeepEqual(statements, tt.statements) {
+				t.Errorf("statements = %q, want %q", statements, tt.statements)
+			}
+			if len(m.warnings) != tt.warnings {
+				t.Errorf("warnings = %v, want %d", m.warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestNormalizeTableDesign(t *testing.T) {
+	tests := []struct {
+		name     string
+		design   domain.TableDesign
+		hasError bool
+	}{
+		{name: "Valid", design: domain.TableDesign{TableDefinition: domain.TableDefinition{Name: "T", Columns: []domain.TableColumn{{Name: "ID", Type: "INTEGER"}}}}},
+		{name: "No name", design: domain.TableDesign{TableDefinition: domain.TableDefinition{Columns: []domain.TableColumn{{Name: "ID", Type: "INTEGER"}}}}, hasError: true},
+		{name: "No columns", design: domain.TableDesign{TableDefinition: domain.TableDefinition{Name: "T"}}, hasError: true},
+		{name: "Duplicate column", design: domain.TableDesign{TableDefinition: domain.TableDefinition{Name: "T", Columns: []domain.TableColumn{{Name: "ID", Type: "INTEGER"}, {Name: "ID", Type: "BIGINT"}}}}, hasError: true},
+		{name: "Column without type", design: domain.TableDesign{TableDefinition: domain.TableDefinition{Name: "T", Columns: []domain.TableColumn{{Name: "ID"}}}}, hasError: true},
+		{
+			name: "Key on unknown column",
+			design: domain.TableDesign{TableDefinition: domain.TableDefinition{Name: "T", Columns: []domain.TableColumn{{Name: "ID", Type: "INTEGER"}},
+				Constraints: []domain.Constraint{{Type: "primary key", Columns: []string{"CODE"}}}}},
+			hasError: true,
+		},
+		{
+			name: "Two primary keys",
+			design: domain.TableDesign{TableDefinition: domain.TableDefinition{Name: "T", Columns: []domain.TableColumn{{Name: "ID", Type: "INTEGER"}},
+				Constraints: []domain.Constraint{{Type: "PRIMARY KEY", Columns: []string{"ID"}}, {Type: "PRIMARY KEY", Columns: []string{"ID"}}}}},
+			hasError: true,
+		},
+		{
+			name: "Foreign key column count",
+			design: domain.TableDesign{TableDefinition: domain.TableDefinition{Name: "T", Columns: []domain.TableColumn{{Name: "ID", Type: "INTEGER"}},
+				Constraints: []domain.Constraint{{Type: "FOREIGN KEY", Columns: []string{"ID"}, RefTable: "P", RefColumns: []string{"A", "B"}}}}},
+			hasError: true,
+		},
+		{
+			name: "Index on unknown column",
+			design: domain.TableDesign{TableDefinition: domain.TableDefinition{Name: "T", Columns: []domain.TableColumn{{Name: "ID", Type: "INTEGER"}}},
+				Indexes: []domain.Index{{Name: "IX", Segments: []string{"CODE"}}}},
+			hasError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := normalizeTableDesign(&tt.design)
+			if (err != nil) != tt.hasError {
+				t.Errorf("normalizeTableDesign() error = %v, hasError %v", err, tt.hasError)
+			}
+			var invalid *domain.ValidationError
+			if err != nil && !errors.As(err, &invalid) {
+				t.Errorf("normalizeTableDesign() error = %T, want *domain.ValidationError", err)
+			}
+		})
+	}
+
+	d := domain.TableDesign{TableDefinition: domain.TableDefinition{
+		Name:        "T",
+		Columns:     []domain.TableColumn{{Name: "QTY", Type: "integer", Default: "0"}},
+		Constraints: []domain.Constraint{{Type: "check", Check: "QTY >= 0"}},
+	}}
+	if err := normalizeTableDesign(&d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Kind != "TABLE" || d.Columns[0].Default != "DEFAULT 0" || d.Constraints[0].Check != "CHECK (QTY >= 0)" {
+		t.Errorf("normalizeTableDesign() = %+v", d)
+	}
+}
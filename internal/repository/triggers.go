@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"database/sql"
+	"firebird-web-admin/internal/domain"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// ListTriggers returns table, database and DDL triggers without their source.
+func (r *FirebirdRepository) ListTriggers(params domain.ConnectionParams) ([]domain.Trigger, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	triggers, err := loadTriggers(db, "", "")
+	if err != nil {
+		log.Printf("ListTriggers error: %v", err)
+		return nil, err
+	}
+	for i := range triggers {
+		triggers[i].Source = ""
+	}
+	return triggers, nil
+}
+
+func (r *FirebirdRepository) GetTrigger(params domain.ConnectionParams, name string) (*domain.Trigger, error) {
+	name = objectName(name)
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	triggers, err := loadTriggers(db, "", name)
+	if err != nil {
+		log.Printf("GetTrigger error: %v", err)
+		return nil, err
+	}
+	if len(triggers) == 0 {
+		return nil, &domain.NotFoundError{ObjectType: "trigger", Name: name}
+	}
+	return &triggers[0], nil
+}
+
+// GetTriggerSource returns the complete CREATE OR ALTER TRIGGER statement of a trigger.
+func (r *FirebirdRepository) GetTriggerSource(params domain.ConnectionParams, name string) (string, error) {
+	t, err := r.GetTrigger(params, name)
+	if err != nil {
+		return "", err
+	}
+	return triggerSQL(*t), nil
+}
+
+// CreateTrigger creates a new trigger; it fails if a trigger with the same name exists.
+func (r *FirebirdRepository) CreateTrigger(params domain.ConnectionParams, t domain.Trigger) error {
+	source := t.Source
+	if err := normalizeTrigger(&t); err != nil {
+		return err
+	}
+	return r.execTriggerDDL(params, "CREATE TRIGGER"+strings.TrimPrefix(triggerSQL(t), "CREATE OR ALTER TRIGGER"), source)
+}
+
+// AlterTrigger replaces the header and source of an existing trigger.
+func (r *FirebirdRepository) AlterTrigger(params domain.ConnectionParams, name string, t domain.Trigger) error {
+	name = objectName(name)
+	if t.Name == "" {
+		t.Name = name
+	}
+	if t.Name != name {
+		return invalidf("triggers cannot be renamed")
+	}
+	source := t.Source
+	if err := normalizeTrigger(&t); err != nil {
+		return err
+	}
+	return r.execTriggerDDL(params, triggerSQL(t), source)
+}
+
+func (r *FirebirdRepository) SetTriggerActive(params domain.ConnectionParams, name string, active bool) error {
+	state := "INACTIVE"
+	if active {
+		state = "ACTIVE"
+	}
+	return r.execDDL(params, fmt.Sprintf("ALTER TRIGGER %s %s", quoteIdent(objectName(name)), state))
+}
+
+func (r *FirebirdRepository) DropTrigger(params domain.ConnectionParams, name string) error {
+	return r.execDDL(params, "DROP TRIGGER "+quoteIdent(objectName(name)))
+}
+
+// execTriggerDDL executes a generated trigger statement. Error positions are reported
+// relative to the source as written by the user, which follows the generated header lines.
+func (r *FirebirdRepository) execTriggerDDL(params domain.ConnectionParams, stmt string, source string) error {
+	header := 0
+	if idx := strings.LastIndex(stmt, source); source != "" && idx != -1 {
+		header = strings.Count(stmt[:idx], "\n")
+	}
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	log.Printf("Trigger DDL: %s", stmt)
+	if _, err := db.Exec(stmt); err != nil {
+		log.Printf("Trigger DDL error: %v", err)
+		return sqlError(err, header)
+	}
+	return nil
+}
+
+// execDDL executes a single DDL statement in its own connection.
+func (r *FirebirdRepository) execDDL(params domain.ConnectionParams, stmt string) error {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	log.Printf("DDL: %s", stmt)
+	if _, err := db.Exec(stmt); err != nil {
+		log.Printf("DDL error: %v", err)
+		return sqlError(err, 0)
+	}
+	return nil
+}
+
+// normalizeTrigger validates a trigger definition received from the editor and fills in
+// the kind and the leading AS of the source when they are omitted.
+func normalizeTrigger(t *domain.Trigger) error {
+	t.Name = strings.TrimSpace(t.Name)
+	t.Event = strings.ToUpper(strings.Join(strings.Fields(t.Event), " "))
+	switch {
+	case t.Name == "":
+		return invalidf("trigger name is required")
+	case t.Event == "":
+		return invalidf("trigger event is required")
+	case strings.TrimSpace(t.Source) == "" && t.Engine == "":
+		return invalidf("trigger source is required")
+	}
+
+	if t.Kind == "" {
+		switch {
+		case t.Relation != "":
+			t.Kind = "TABLE"
+		case strings.HasPrefix(t.Event, "ON "):
+			t.Kind = "DATABASE"
+		default:
+			t.Kind = "DDL"
+		}
+	}
+	if t.Kind == "TABLE" && t.Relation == "" {
+		return invalidf("table triggers require a relation")
+	}
+
+	if t.Engine == "" {
+		if fields := strings.Fields(t.Source); len(fields) == 0 || !strings.EqualFold(fields[0], "AS") {
+			t.Source = "AS\n" + t.Source
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"errors"
+	"firebird-web-admin/internal/domain"
+	"testing"
+)
+
+func TestSQLError(t *testing.T) {
+	tests := []struct {
+		name       string
+		message    string
+		lineOffset int
+		line       int
+		column     int
+		positioned bool
+	}{
+		{name: "Token unknown", message: "Dynamic SQL Error\nSQL error code = -104\nToken unknown - line 5, column 3\nEND", line: 5, column: 3, positioned: true},
+		{name: "Column unknown", message: "Dynamic SQL Error\nSQL error code = -206\nColumn unknown\nFOO\nAt line 7, column 12", lineOffset: 2, line: 5, column: 12, positioned: true},
+		{name: "Error in header", message: "Token unknown - line 1, column 9", lineOffset: 2, line: 1, column: 9, positioned: true},
+		{name: "No position", message: "unsuccessful metadata update\nTRIGGER T_BI already exists"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := sqlError(errors.New(tt.message), tt.lineOffset)
+			var sqlErr *domain.SQLError
+			if errors.As(err, &sqlErr) != tt.positioned {
+				t.Fatalf("sqlError() = %#v, positioned want %v", err, tt.positioned)
+			}
+			if tt.positioned && (sqlErr.Line != tt.line || sqlErr.Column != tt.column) {
+				t.Errorf("sqlError() position = %d:%d, want %d:%d", sqlErr.Line, sqlErr.Column, tt.line, tt.column)
+			}
+		})
+	}
+}
+
+func TestNormalizeTrigger(t *testing.T) {
+	tests := []struct {
+		name    string
+		trigger domain.Trigger
+		kind    string
+		source  string
+		wantErr bool
+	}{
+		{name: "Table trigger", trigger: domain.Trigger{Name: "T_BI", Relation: "T", Event: "before  insert", Source: "AS\nBEGIN END"}, kind: "TABLE", source: "AS\nBEGIN END"},
+		{name: "Missing AS", trigger: domain.Trigger{Name: "T_BI", Relation: "T", Event: "BEFORE INSERT", Source: "BEGIN END"}, kind: "TABLE", source: "AS\nBEGIN END"},
+		{name: "Database trigger", trigger: domain.Trigger{Name: "ON_CONN", Event: "ON CONNECT", Source: "AS BEGIN END"}, kind: "DATABASE", source: "AS BEGIN END"},
+		{name: "DDL trigger", trigger: domain.Trigger{Name: "AUDIT", Event: "AFTER ANY DDL STATEMENT", Source: "AS BEGIN END"}, kind: "DDL", source: "AS BEGIN END"},
+		{name: "Table kind without relation", trigger: domain.Trigger{Name: "X", Kind: "TABLE", Event: "BEFORE INSERT", Source: "AS BEGIN END"}, wantErr: true},
+		{name: "Missing source", trigger: domain.Trigger{Name: "X", Relation: "T", Event: "BEFORE INSERT"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trigger := tt.trigger
+			err := normalizeTrigger(&trigger)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeTrigger() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var invalid *domain.ValidationError
+			if err != nil && !errors.As(err, &invalid) {
+				t.Errorf("normalizeTrigger() error = %T, want *domain.ValidationError", err)
+			}
+			if err == nil && (trigger.Kind != tt.kind || trigger.Source != tt.source) {
+				t.Errorf("normalizeTrigger() = %q, %q, want %q, %q", trigger.Kind, trigger.Source, tt.kind, tt.source)
+			}
+		})
+	}
+}
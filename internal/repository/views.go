@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"firebird-web-admin/internal/domain"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+)
+
+// viewHeader matches the start of a statement that defines a view and captures its name.
+var viewHeader = regexp.MustCompile(`(?is)^\s*(?:CREATE\s+OR\s+ALTER|CREATE|ALTER|RECREATE)\s+VIEW\s+("(?:[^"]|"")+"|[A-Z][A-Z0-9_$]*)`)
+
+// viewNotUpdatable matches select clauses that make a view read-only unless it has triggers.
+var viewNotUpdatable = regexp.MustCompile(`(?i)\b(?:DISTINCT|GROUP\s+BY|HAVING|UNION|FIRST|SKIP|ROWS|FETCH|OFFSET)\b|\b(?:COUNT|SUM|AVG|MIN|MAX|LIST)\s*\(`)
+
+// GetView returns a view with its columns and whether rows can be edited through it.
+func (r *FirebirdRepository) GetView(params domain.ConnectionParams, name string) (*domain.ViewDefinition, error) {
+	connStr, err := r.getConnectionString(params)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("firebirdsql", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	v, err := loadView(db, objectName(name))
+	if err != nil {
+		log.Printf("GetView error: %v", err)
+		return nil, err
+	}
+	return v, nil
+}
+
+// GetViewSource rebuilds the CREATE OR ALTER VIEW statement from RDB$VIEW_SOURCE and the column list.
+func (r *FirebirdRepository) GetViewSource(params domain.ConnectionParams, name string) (string, error) {
+	v, err := r.GetView(params, name)
+	if err != nil {
+		return "", err
+	}
+	return viewSourceSQL(*v), nil
+}
+
+// AlterView executes a CREATE [OR ALTER] / ALTER / RECREATE VIEW statement for the named view.
+func (r *FirebirdRepository) AlterView(params domain.ConnectionParams, name string, source string) error {
+	return r.compileObject(params, viewHeader, "view", name, source)
+}
+
+func loadView(db *sql.DB, name string) (*domain.ViewDefinition, error) {
+	var source, description sql.NullString
+	err := db.QueryRow(`
+		SELECT RDB$VIEW_SOURCE, RDB$DESCRIPTION
+		FROM RDB$RELATIONS
+		WHERE RDB$RELATION_NAME = ? AND RDB$VIEW_BLR IS NOT NULL
+	`, name).Scan(&source, &description)
+	if err == sql.ErrNoRows {
+		return nil, &domain.NotFoundError{ObjectType: "view", Name: name}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	charset, err := loadDefaultCharset(db)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := loadRelationColumns(db, charset, name)
+	if err != nil {
+		return nil, err
+	}
+	v := &domain.ViewDefinition{
+		Name:        name,
+		Columns:     columns[name],
+		Source:      strings.TrimSpace(source.String),
+		Description: description.String,
+	}
+	if v.Updatable, err = viewUpdatable(db, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// viewUpdatable reports whether rows can be changed through a view: either it has active
+// triggers that perform the changes, or it selects from a single relation without
+// aggregation, DISTINCT, UNION or row limits.
+func viewUpdatable(db *sql.DB, v *domain.ViewDefinition) (bool, error) {
+	var triggers, contexts int
+	err := db.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM RDB$TRIGGERS
+			 WHERE RDB$RELATION_NAME = r.RDB$RELATION_NAME AND COALESCE(RDB$TRIGGER_INACTIVE, 0) = 0),
+			(SELECT COUNT(*) FROM RDB$VIEW_RELATIONS WHERE RDB$VIEW_NAME = r.RDB$RELATION_NAME)
+		FROM RDB$RELATIONS r
+		WHERE r.RDB$RELATION_NAME = ?
+	`, v.Name).Scan(&triggers, &contexts)
+	if err != nil {
+		return false, err
+	}
+	if triggers > 0 {
+		return true, nil
+	}
+	return contexts == 1 && !viewNotUpdatable.MatchString(v.Source), nil
+}
+
+// parseDBKey decodes a DB_KEY sent back by the data grid. A table DB_KEY has 8 bytes;
+// the DB_KEY of a view concatenates the keys of the relations it selects from.
+func parseDBKey(dbKey string) ([]byte, error) {
+	key, err := hex.DecodeString(dbKey)
+	if err != nil || len(key) == 0 || len(key)%8 != 0 {
+		return nil, fmt.Errorf("invalid db_key format")
+	}
+	return key, nil
+}
+
+// relationIsView reports whether a relation is a view.
+func relationIsView(db *sql.DB, name string) (bool, error) {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM RDB$RELATIONS WHERE RDB$RELATION_NAME = ? AND RDB$VIEW_BLR IS NOT NULL", name).Scan(&count)
+	return count > 0, err
+}
+
+func viewSourceSQL(v domain.ViewDefinition) string {
+	return "CREATE OR ALTER VIEW" + strings.TrimPrefix(viewSQL(v), "CREATE VIEW")
+}
@@ -0,0 +1,52 @@
+package repository
+
+import "testing"
+
+func TestViewNotUpdatable(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected bool
+	}{
+		{name: "Plain select", source: "SELECT ID, FIRST_NAME, ROWS_COUNT FROM CUSTOMER WHERE ACTIVE = 1", expected: false},
+		{name: "Distinct", source: "SELECT DISTINCT CITY FROM CUSTOMER", expected: true},
+		{name: "Group by", source: "SELECT CITY, COUNT(*) FROM CUSTOMER\nGROUP  BY CITY", expected: true},
+		{name: "Aggregate", source: "SELECT max (ID) FROM CUSTOMER", expected: true},
+		{name: "Union", source: "SELECT ID FROM A UNION ALL SELECT ID FROM B", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := viewNotUpdatable.MatchString(tt.source); got != tt.expected {
+				t.Errorf("viewNotUpdatable.MatchString() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseDBKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		dbKey    string
+		length   int
+		hasError bool
+	}{
+		{name: "Table", dbKey: "8100000001000000", length: 8},
+		{name: "View over two tables", dbKey: "81000000010000008200000002000000", length: 16},
+		{name: "Not hex", dbKey: "zz00000001000000", hasError: true},
+		{name: "Truncated", dbKey: "81000000", hasError: true},
+		{name: "Empty", dbKey: "", hasError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDBKey(tt.dbKey)
+			if (err != nil) != tt.hasError {
+				t.Fatalf("parseDBKey() error = %v, hasError %v", err, tt.hasError)
+			}
+			if len(got) != tt.length {
+				t.Errorf("parseDBKey() length = %d, want %d", len(got), tt.length)
+			}
+		})
+	}
+}
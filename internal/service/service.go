@@ -1,16 +1,27 @@
 package service
 
 import (
+	"errors"
 	"firebird-web-admin/internal/domain"
 	"firebird-web-admin/internal/repository"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
 )
 
 type Service struct {
 	repo repository.Repository
+
+	mu sync.Mutex
+	// previousProcedures keeps the source each procedure had before its last
+	// AlterProcedure call, keyed by database and procedure name. It lives in this
+	// process only, so a restart forgets it.
+	previousProcedures map[string]string
 }
 
 func NewService(repo repository.Repository) *Service {
-	return &Service{repo: repo}
+	return &Service{repo: repo, previousProcedures: make(map[string]string)}
 }
 
 func (s *Service) Connect(params domain.ConnectionParams) error {
@@ -49,6 +60,450 @@ func (s *Service) GetTableDDL(params domain.ConnectionParams, tableName string)
 	return s.repo.GetTableDDL(params, tableName)
 }
 
+// WriteDatabaseDDL loads the whole schema first, so connection and metadata errors are
+// returned before anything is written to w.
+func (s *Service) WriteDatabaseDDL(params domain.ConnectionParams, w io.Writer) error {
+	schema, err := s.repo.GetSchema(params)
+	if err != nil {
+		return err
+	}
+	return repository.WriteSchemaScript(w, schema)
+}
+
+func (s *Service) GetSchema(params domain.ConnectionParams) (*domain.Schema, error) {
+	return s.repo.GetSchema(params)
+}
+
+// CompareSchemas diffs two schemas. The returned script migrates target into source.
+func (s *Service) CompareSchemas(source, target domain.SchemaSource) (*domain.SchemaDiff, error) {
+	sourceSchema, err := s.loadSchema(source)
+	if err != nil {
+		return nil, fmt.Errorf("source: %w", err)
+	}
+	targetSchema, err := s.loadSchema(target)
+	if err != nil {
+		return nil, fmt.Errorf("target: %w", err)
+	}
+	return repository.DiffSchemas(sourceSchema, targetSchema), nil
+}
+
+func (s *Service) loadSchema(src domain.SchemaSource) (*domain.Schema, error) {
+	if src.Snapshot != nil {
+		return src.Snapshot, nil
+	}
+	if src.Connection == nil {
+		return nil, fmt.Errorf("a connection or a snapshot is required")
+	}
+	return s.repo.GetSchema(*src.Connection)
+}
+
+const defaultDataDiffRows = 1000
+
+// CompareData compares the rows of two tables. At most opts.MaxRows differences are returned,
+// the counters always cover the whole table.
+func (s *Service) CompareData(source, target domain.ConnectionParams, opts domain.DataCompareOptions) (*domain.DataDiff, error) {
+	maxRows := opts.MaxRows
+	if maxRows <= 0 {
+		maxRows = defaultDataDiffRows
+	}
+	rows := []domain.RowDifference{}
+	diff, err := s.repo.CompareTableData(source, target, opts, func(d *domain.DataDiff, row domain.RowDifference) error {
+		if len(rows) < maxRows {
+			rows = append(rows, row)
+		} else {
+			d.Truncated = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	diff.Rows = rows
+	return diff, nil
+}
+
+// WriteDataSyncScript streams the INSERT/UPDATE/DELETE statements that make the target table
+// match the source table. Nothing is written before the first difference is found.
+func (s *Service) WriteDataSyncScript(source, target domain.ConnectionParams, opts domain.DataCompareOptions, w io.Writer) error {
+	started := false
+	header := func(d *domain.DataDiff) error {
+		started = true
+		_, err := fmt.Fprintf(w, "-- Data sync of %s from %s, key (%s)\n", d.TargetTable, d.Table, strings.Join(d.KeyColumns, ", "))
+		return err
+	}
+	diff, err := s.repo.CompareTableData(source, target, opts, func(d *domain.DataDiff, row domain.RowDifference) error {
+		if !started {
+			if err := header(d); err != nil {
+				return err
+			}
+		}
+		return repository.WriteRowSync(w, d, row)
+	})
+	if err != nil {
+		return err
+	}
+	if !started {
+		if err := header(diff); err != nil {
+			return err
+		}
+	}
+	for _, warning := range diff.Warnings {
+		if _, err := fmt.Fprintf(w, "-- Warning: %s\n", warning); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "COMMIT;\n-- %d inserted, %d updated, %d deleted, %d unchanged\n", diff.Inserted, diff.Updated, diff.Deleted, diff.Unchanged)
+	return err
+}
+
+func (s *Service) ListTriggers(params domain.ConnectionParams) ([]domain.Trigger, error) {
+	return s.repo.ListTriggers(params)
+}
+
+func (s *Service) GetTrigger(params domain.ConnectionParams, name string) (*domain.Trigger, error) {
+	return s.repo.GetTrigger(params, name)
+}
+
+func (s *Service) GetTriggerSource(params domain.ConnectionParams, name string) (string, error) {
+	return s.repo.GetTriggerSource(params, name)
+}
+
+func (s *Service) CreateTrigger(params domain.ConnectionParams, t domain.Trigger) error {
+	return s.repo.CreateTrigger(params, t)
+}
+
+func (s *Service) AlterTrigger(params domain.ConnectionParams, name string, t domain.Trigger) error {
+	return s.repo.AlterTrigger(params, name, t)
+}
+
+func (s *Service) SetTriggerActive(params domain.ConnectionParams, name string, active bool) error {
+	return s.repo.SetTriggerActive(params, name, active)
+}
+
+func (s *Service) DropTrigger(params domain.ConnectionParams, name string) error {
+	return s.repo.DropTrigger(params, name)
+}
+
+func (s *Service) ListSequences(params domain.ConnectionParams) ([]domain.Sequence, error) {
+	return s.repo.ListSequences(params)
+}
+
+func (s *Service) GetSequence(params domain.ConnectionParams, name string) (*domain.Sequence, error) {
+	return s.repo.GetSequence(params, name)
+}
+
+func (s *Service) CreateSequence(params domain.ConnectionParams, seq domain.Sequence) error {
+	return s.repo.CreateSequence(params, seq)
+}
+
+func (s *Service) RestartSequence(params domain.ConnectionParams, name string, value *int64) error {
+	return s.repo.RestartSequence(params, name, value)
+}
+
+func (s *Service) SetSequenceValue(params domain.ConnectionParams, name string, value int64) error {
+	return s.repo.SetSequenceValue(params, name, value)
+}
+
+func (s *Service) DropSequence(params domain.ConnectionParams, name string) error {
+	return s.repo.DropSequence(params, name)
+}
+
+func (s *Service) ListFunctions(params domain.ConnectionParams) ([]domain.Routine, error) {
+	return s.repo.ListFunctions(params)
+}
+
+func (s *Service) GetFunction(params domain.ConnectionParams, name string) (*domain.Routine, error) {
+	return s.repo.GetFunction(params, name)
+}
+
+func (s *Service) GetFunctionSource(params domain.ConnectionParams, name string) (string, error) {
+	return s.repo.GetFunctionSource(params, name)
+}
+
+func (s *Service) ExecuteFunction(params domain.ConnectionParams, name string, args map[string]interface{}) ([]map[string]interface{}, []domain.Column, error) {
+	return s.repo.ExecuteFunction(params, name, args)
+}
+
+func (s *Service) ListPackages(params domain.ConnectionParams) ([]domain.Package, error) {
+	return s.repo.ListPackages(params)
+}
+
+func (s *Service) GetPackage(params domain.ConnectionParams, name string) (*domain.Package, error) {
+	return s.repo.GetPackage(params, name)
+}
+
+func (s *Service) GetPackageSource(params domain.ConnectionParams, name string) (string, string, error) {
+	return s.repo.GetPackageSource(params, name)
+}
+
+func (s *Service) CompilePackageHeader(params domain.ConnectionParams, name string, source string) error {
+	return s.repo.CompilePackageHeader(params, name, source)
+}
+
+func (s *Service) CompilePackageBody(params domain.ConnectionParams, name string, source string) error {
+	return s.repo.CompilePackageBody(params, name, source)
+}
+
+func (s *Service) ExecutePackageProcedure(params domain.ConnectionParams, pkg string, procName string, inputParams map[string]interface{}, selectable *bool) ([]map[string]interface{}, []domain.Column, error) {
+	return s.repo.ExecutePackageProcedure(params, pkg, procName, inputParams, selectable)
+}
+
+func (s *Service) ExecutePackageFunction(params domain.ConnectionParams, pkg string, name string, args map[string]interface{}) ([]map[string]interface{}, []domain.Column, error) {
+	return s.repo.ExecutePackageFunction(params, pkg, name, args)
+}
+
+func (s *Service) ListExceptions(params domain.ConnectionParams) ([]domain.DatabaseException, error) {
+	return s.repo.ListExceptions(params)
+}
+
+func (s *Service) GetException(params domain.ConnectionParams, name string) (*domain.DatabaseException, error) {
+	return s.repo.GetException(params, name)
+}
+
+func (s *Service) GetExceptionDDL(params domain.ConnectionParams, name string) (string, error) {
+	return s.repo.GetExceptionDDL(params, name)
+}
+
+func (s *Service) CreateException(params domain.ConnectionParams, e domain.DatabaseException) error {
+	return s.repo.CreateException(params, e)
+}
+
+func (s *Service) AlterException(params domain.ConnectionParams, name string, message string) error {
+	return s.repo.AlterException(params, name, message)
+}
+
+func (s *Service) DropException(params domain.ConnectionParams, name string) error {
+	return s.repo.DropException(params, name)
+}
+
+func (s *Service) ListDomains(params domain.ConnectionParams) ([]domain.Domain, error) {
+	return s.repo.ListDomains(params)
+}
+
+func (s *Service) GetDomain(params domain.ConnectionParams, name string) (*domain.Domain, error) {
+	return s.repo.GetDomain(params, name)
+}
+
+func (s *Service) GetDomainDDL(params domain.ConnectionParams, name string) (string, error) {
+	return s.repo.GetDomainDDL(params, name)
+}
+
+func (s *Service) CreateDomain(params domain.ConnectionParams, d domain.Domain) error {
+	return s.repo.CreateDomain(params, d)
+}
+
+func (s *Service) AlterDomain(params domain.ConnectionParams, name string, req domain.DomainRequest) error {
+	return s.repo.AlterDomain(params, name, req)
+}
+
+func (s *Service) DropDomain(params domain.ConnectionParams, name string) error {
+	return s.repo.DropDomain(params, name)
+}
+
+func (s *Service) ListCharacterSets(params domain.ConnectionParams) ([]domain.CharacterSet, error) {
+	return s.repo.ListCharacterSets(params)
+}
+
+func (s *Service) GetCharacterSet(params domain.ConnectionParams, name string) (*domain.CharacterSet, error) {
+	return s.repo.GetCharacterSet(params, name)
+}
+
+func (s *Service) GetCharacterSetDDL(params domain.ConnectionParams, name string) (string, error) {
+	return s.repo.GetCharacterSetDDL(params, name)
+}
+
+func (s *Service) SetDefaultCollation(params domain.ConnectionParams, charset string, collation string) error {
+	return s.repo.SetDefaultCollation(params, charset, collation)
+}
+
+func (s *Service) ListCollations(params domain.ConnectionParams) ([]domain.Collation, error) {
+	return s.repo.ListCollations(params)
+}
+
+func (s *Service) GetCollation(params domain.ConnectionParams, name string) (*domain.Collation, error) {
+	return s.repo.GetCollation(params, name)
+}
+
+func (s *Service) GetCollationDDL(params domain.ConnectionParams, name string) (string, error) {
+	return s.repo.GetCollationDDL(params, name)
+}
+
+func (s *Service) CreateCollation(params domain.ConnectionParams, c domain.Collation) error {
+	return s.repo.CreateCollation(params, c)
+}
+
+func (s *Service) DropCollation(params domain.ConnectionParams, name string) error {
+	return s.repo.DropCollation(params, name)
+}
+
+func (s *Service) GetView(params domain.ConnectionParams, name string) (*domain.ViewDefinition, error) {
+	return s.repo.GetView(params, name)
+}
+
+func (s *Service) GetViewSource(params domain.ConnectionParams, name string) (string, error) {
+	return s.repo.GetViewSource(params, name)
+}
+
+func (s *Service) AlterView(params domain.ConnectionParams, name string, source string) error {
+	return s.repo.AlterView(params, name, source)
+}
+
+func (s *Service) GetDependencies(params domain.ConnectionParams, objectType, name, column, direction string, transitive bool) (*domain.DependencyGraph, error) {
+	return s.repo.GetDependencies(params, objectType, name, column, direction, transitive)
+}
+
+func (s *Service) CheckDDLDependencies(params domain.ConnectionParams, stmt string) ([]domain.DependencyWarning, error) {
+	return s.repo.CheckDDLDependencies(params, stmt)
+}
+
+func (s *Service) GetTableDesign(params domain.ConnectionParams, name string) (*domain.TableDesign, error) {
+	return s.repo.GetTableDesign(params, name)
+}
+
+func (s *Service) CreateTable(params domain.ConnectionParams, design domain.TableDesign, dryRun bool) (*domain.TableDesignResult, error) {
+	return s.repo.CreateTable(params, design, dryRun)
+}
+
+func (s *Service) AlterTable(params domain.ConnectionParams, name string, design domain.TableDesign, dryRun bool) (*domain.TableDesignResult, error) {
+	return s.repo.AlterTable(params, name, design, dryRun)
+}
+
+func (s *Service) ListIndexes(params domain.ConnectionParams, table string) ([]domain.Index, error) {
+	return s.repo.ListIndexes(params, table)
+}
+
+func (s *Service) GetIndex(params domain.ConnectionParams, name string) (*domain.Index, error) {
+	return s.repo.GetIndex(params, name)
+}
+
+func (s *Service) CreateIndex(params domain.ConnectionParams, i domain.Index) error {
+	return s.repo.CreateIndex(params, i)
+}
+
+func (s *Service) DropIndex(params domain.ConnectionParams, name string) error {
+	return s.repo.DropIndex(params, name)
+}
+
+func (s *Service) SetIndexActive(params domain.ConnectionParams, name string, active bool) error {
+	return s.repo.SetIndexActive(params, name, active)
+}
+
+func (s *Service) SetIndexStatistics(params domain.ConnectionParams, name string) (*domain.Index, error) {
+	return s.repo.SetIndexStatistics(params, name)
+}
+
+func (s *Service) GetIndexReport(params domain.ConnectionParams, maxSelectivity float64) ([]domain.IndexIssue, error) {
+	return s.repo.GetIndexReport(params, maxSelectivity)
+}
+
+func (s *Service) ExplainQuery(params domain.ConnectionParams, query string) (*domain.QueryPlan, error) {
+	return s.repo.ExplainQuery(params, query)
+}
+
+func (s *Service) GetMonitorOverview(params domain.ConnectionParams) (*domain.MonitorOverview, error) {
+	return s.repo.GetMonitorOverview(params)
+}
+
+func (s *Service) ListAttachments(params domain.ConnectionParams) ([]domain.Attachment, error) {
+	return s.repo.ListAttachments(params)
+}
+
+func (s *Service) ListMonitoredTransactions(params domain.ConnectionParams) ([]domain.MonitoredTransaction, error) {
+	return s.repo.ListMonitoredTransactions(params)
+}
+
+func (s *Service) ListMonitoredStatements(params domain.ConnectionParams, activeOnly bool) ([]domain.MonitoredStatement, error) {
+	return s.repo.ListMonitoredStatements(params, activeOnly)
+}
+
+func (s *Service) KillAttachment(params domain.ConnectionParams, id int64) error {
+	return s.repo.KillAttachment(params, id)
+}
+
+func (s *Service) CancelStatement(params domain.ConnectionParams, id int64) error {
+	return s.repo.CancelStatement(params, id)
+}
+
+func (s *Service) GetDatabaseHealth(params domain.ConnectionParams, thresholds domain.HealthThresholds) (*domain.DatabaseHealth, error) {
+	return s.repo.GetDatabaseHealth(params, thresholds)
+}
+
+func (s *Service) Backup(params domain.ConnectionParams, req domain.BackupRequest, w io.Writer) error {
+	return s.repo.Backup(params, req, w)
+}
+
+func (s *Service) Restore(params domain.ConnectionParams, req domain.RestoreRequest, w io.Writer) error {
+	return s.repo.Restore(params, req, w)
+}
+
+func (s *Service) ValidateDatabase(params domain.ConnectionParams, req domain.ValidationRequest) (*domain.ValidationReport, error) {
+	return s.repo.ValidateDatabase(params, req)
+}
+
+func (s *Service) SweepDatabase(params domain.ConnectionParams) (*domain.ServiceResult, error) {
+	return s.repo.SweepDatabase(params)
+}
+
+func (s *Service) ShutdownDatabase(params domain.ConnectionParams, req domain.ShutdownRequest) (*domain.ServiceResult, error) {
+	return s.repo.ShutdownDatabase(params, req)
+}
+
+func (s *Service) BringOnline(params domain.ConnectionParams, state string) (*domain.ServiceResult, error) {
+	return s.repo.BringOnline(params, state)
+}
+
+func (s *Service) SetDatabaseProperties(params domain.ConnectionParams, props domain.DatabaseProperties) (*domain.ServiceResult, error) {
+	return s.repo.SetDatabaseProperties(params, props)
+}
+
+func (s *Service) ListUsers(params domain.ConnectionParams) ([]domain.User, error) {
+	return s.repo.ListUsers(params)
+}
+
+func (s *Service) CreateUser(params domain.ConnectionParams, req domain.UserRequest) error {
+	return s.repo.CreateUser(params, req)
+}
+
+func (s *Service) AlterUser(params domain.ConnectionParams, name string, req domain.UserRequest) error {
+	return s.repo.AlterUser(params, name, req)
+}
+
+func (s *Service) DropUser(params domain.ConnectionParams, name string, plugin string) error {
+	return s.repo.DropUser(params, name, plugin)
+}
+
+func (s *Service) ListRoles(params domain.ConnectionParams) ([]domain.Role, error) {
+	return s.repo.ListRoles(params)
+}
+
+func (s *Service) CreateRole(params domain.ConnectionParams, name string) error {
+	return s.repo.CreateRole(params, name)
+}
+
+func (s *Service) DropRole(params domain.ConnectionParams, name string) error {
+	return s.repo.DropRole(params, name)
+}
+
+func (s *Service) ListGrants(params domain.ConnectionParams, object string, grantee string) ([]domain.Grant, error) {
+	return s.repo.ListGrants(params, object, grantee)
+}
+
+func (s *Service) GrantPrivileges(params domain.ConnectionParams, grants []domain.Grant) error {
+	return s.repo.GrantPrivileges(params, grants)
+}
+
+func (s *Service) RevokePrivileges(params domain.ConnectionParams, grants []domain.Grant) error {
+	return s.repo.RevokePrivileges(params, grants)
+}
+
+func (s *Service) GetPrivilegeMatrix(params domain.ConnectionParams, objectType string, name string) (*domain.PrivilegeMatrix, error) {
+	return s.repo.GetPrivilegeMatrix(params, objectType, name)
+}
+
+func (s *Service) ApplyPrivilegeMatrix(params domain.ConnectionParams, objectType string, name string, desired domain.PrivilegeMatrix, dryRun bool) (*domain.PrivilegeChanges, error) {
+	return s.repo.ApplyPrivilegeMatrix(params, objectType, name, desired, dryRun)
+}
+
 func (s *Service) ListViews(params domain.ConnectionParams) ([]domain.Table, error) {
 	return s.repo.ListViews(params)
 }
@@ -61,16 +516,60 @@ func (s *Service) GetProcedureSource(params domain.ConnectionParams, procName st
 	return s.repo.GetProcedureSource(params, procName)
 }
 
+func procedureVersionKey(params domain.ConnectionParams, procName string) string {
+	return params.Database + "\x00" + strings.ToUpper(procName)
+}
+
+// GetPreviousProcedureSource returns the source replaced by the last AlterProcedure call, if any.
+func (s *Service) GetPreviousProcedureSource(params domain.ConnectionParams, procName string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous, ok := s.previousProcedures[procedureVersionKey(params, procName)]
+	return previous, ok
+}
+
+// AlterProcedure compiles a new version of a procedure and remembers the replaced one for RevertProcedure.
+// The replaced source is returned; it is empty when the procedure is new. Nothing is
+// compiled when the current source cannot be read, so no version is lost.
+func (s *Service) AlterProcedure(params domain.ConnectionParams, procName string, source string) (string, error) {
+	// A missing procedure is created, so there is simply no previous version.
+	previous, err := s.repo.GetProcedureSource(params, procName)
+	var notFound *domain.NotFoundError
+	if err != nil && !errors.As(err, &notFound) {
+		return "", fmt.Errorf("cannot save the current version of procedure %s: %w", procName, err)
+	}
+	if err := s.repo.CompileProcedure(params, procName, source); err != nil {
+		return "", err
+	}
+	if previous != "" {
+		s.mu.Lock()
+		s.previousProcedures[procedureVersionKey(params, procName)] = previous
+		s.mu.Unlock()
+	}
+	return previous, nil
+}
+
+// RevertProcedure compiles the version replaced by the last AlterProcedure call.
+// Reverting twice restores the newer version again.
+func (s *Service) RevertProcedure(params domain.ConnectionParams, procName string) error {
+	previous, ok := s.GetPreviousProcedureSource(params, procName)
+	if !ok {
+		return fmt.Errorf("no previous version of procedure %s", procName)
+	}
+	_, err := s.AlterProcedure(params, procName, previous)
+	return err
+}
+
 func (s *Service) GetProcedureParameters(params domain.ConnectionParams, procName string) ([]domain.ProcedureParameter, error) {
 	return s.repo.GetProcedureParameters(params, procName)
 }
 
-func (s *Service) ExecuteProcedure(params domain.ConnectionParams, procName string, inputParams map[string]interface{}) ([]map[string]interface{}, []domain.Column, error) {
-	return s.repo.ExecuteProcedure(params, procName, inputParams)
+func (s *Service) ExecuteProcedure(params domain.ConnectionParams, procName string, inputParams map[string]interface{}, selectable *bool) ([]map[string]interface{}, []domain.Column, error) {
+	return s.repo.ExecuteProcedure(params, procName, inputParams, selectable)
 }
 
-func (s *Service) ExecuteQuery(params domain.ConnectionParams, query string) ([]map[string]interface{}, []domain.Column, error) {
-	return s.repo.ExecuteQuery(params, query)
+func (s *Service) ExecuteQuery(params domain.ConnectionParams, query string, withStats bool) ([]map[string]interface{}, []domain.Column, *domain.QueryStats, error) {
+	return s.repo.ExecuteQuery(params, query, withStats)
 }
 
 func (s *Service) GetAllMetadata(params domain.ConnectionParams) ([]domain.TableMetadata, error) {
@@ -0,0 +1,101 @@
+package http
+
+import (
+	"firebird-web-admin/internal/domain"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DefaultCollationRequest carries the new default collation of a character set.
+type DefaultCollationRequest struct {
+	Collation string `json:"default_collation"`
+}
+
+func (h *Handler) listCharacterSets(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	charsets, err := h.svc.ListCharacterSets(params)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, charsets)
+}
+
+func (h *Handler) getCharacterSet(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	charset, err := h.svc.GetCharacterSet(params, c.Param("name"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, charset)
+}
+
+func (h *Handler) getCharacterSetDDL(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	ddl, err := h.svc.GetCharacterSetDDL(params, c.Param("name"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"ddl": ddl})
+}
+
+func (h *Handler) setDefaultCollation(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var req DefaultCollationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	if err := h.svc.SetDefaultCollation(params, c.Param("name"), req.Collation); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+func (h *Handler) listCollations(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	collations, err := h.svc.ListCollations(params)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, collations)
+}
+
+func (h *Handler) getCollation(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	collation, err := h.svc.GetCollation(params, c.Param("name"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, collation)
+}
+
+func (h *Handler) getCollationDDL(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	ddl, err := h.svc.GetCollationDDL(params, c.Param("name"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"ddl": ddl})
+}
+
+func (h *Handler) createCollation(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var collation domain.Collation
+	if err := c.Bind(&collation); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	if err := h.svc.CreateCollation(params, collation); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+func (h *Handler) dropCollation(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	if err := h.svc.DropCollation(params, c.Param("name")); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
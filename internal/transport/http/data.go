@@ -0,0 +1,82 @@
+package http
+
+import (
+	"firebird-web-admin/internal/domain"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CompareDataRequest selects the tables of a data comparison.
+// A missing source or target uses the current connection.
+type CompareDataRequest struct {
+	Source *domain.ConnectionParams `json:"source,omitempty"`
+	Target *domain.ConnectionParams `json:"target,omitempty"`
+	domain.DataCompareOptions
+}
+
+// bindCompareData reads the request and resolves both connections.
+// On failure the error response has already been written.
+func (h *Handler) bindCompareData(c echo.Context) (*CompareDataRequest, error) {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var req CompareDataRequest
+	if err := c.Bind(&req); err != nil {
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	if req.Table == "" {
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{"error": "table is required"})
+	}
+	for _, side := range []**domain.ConnectionParams{&req.Source, &req.Target} {
+		if *side == nil {
+			*side = &params
+			continue
+		}
+		if !demoAllows(**side) {
+			return nil, c.JSON(http.StatusForbidden, map[string]string{"error": "Demo mode: only firebird5:employee allowed"})
+		}
+		// A connection given in the request is checked like one made through /connect.
+		if err := h.svc.Connect(**side); err != nil {
+			return nil, c.JSON(http.StatusBadRequest, map[string]string{"error": "Connection failed: " + err.Error()})
+		}
+	}
+	return &req, nil
+}
+
+func (h *Handler) compareData(c echo.Context) error {
+	req, err := h.bindCompareData(c)
+	if req == nil {
+		return err
+	}
+
+	diff, err := h.svc.CompareData(*req.Source, *req.Target, req.DataCompareOptions)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, diff)
+}
+
+// getDataSyncScript streams the script that makes the target table match the source table.
+// With ?download=1 the script is sent as an attachment.
+func (h *Handler) getDataSyncScript(c echo.Context) error {
+	req, err := h.bindCompareData(c)
+	if req == nil {
+		return err
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, echo.MIMETextPlainCharsetUTF8)
+	if c.QueryParam("download") != "" {
+		res.Header().Set(echo.HeaderContentDisposition, "attachment; filename=\"sync.sql\"")
+	}
+
+	// Headers are committed on the first write, so errors before that can still be reported as JSON.
+	if err := h.svc.WriteDataSyncScript(*req.Source, *req.Target, req.DataCompareOptions, res); err != nil {
+		if !res.Committed {
+			res.Header().Del(echo.HeaderContentDisposition)
+			return errorResponse(c, err)
+		}
+		c.Logger().Errorf("getDataSyncScript: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"firebird-web-admin/internal/domain"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CheckDependenciesRequest carries the DDL statement to check before executing it.
+type CheckDependenciesRequest struct {
+	SQL string `json:"sql"`
+}
+
+func (h *Handler) getDependencyUses(c echo.Context) error {
+	return h.dependencies(c, "uses")
+}
+
+func (h *Handler) getDependencyUsedBy(c echo.Context) error {
+	return h.dependencies(c, "used_by")
+}
+
+// getDependencyGraph returns both directions, transitively unless ?transitive=0 is given.
+func (h *Handler) getDependencyGraph(c echo.Context) error {
+	return h.dependencies(c, "both")
+}
+
+// dependencies serves the dependency endpoints. ?transitive=1 follows dependencies beyond the
+// direct ones and ?column= restricts them to a single column of a table or view.
+func (h *Handler) dependencies(c echo.Context, direction string) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	transitive := c.QueryParam("transitive") == "1" || c.QueryParam("transitive") == "true"
+	if direction == "both" && c.QueryParam("transitive") == "" {
+		transitive = true
+	}
+	graph, err := h.svc.GetDependencies(params, c.Param("type"), c.Param("name"), c.QueryParam("column"), direction, transitive)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, graph)
+}
+
+func (h *Handler) checkDependencies(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var req CheckDependenciesRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	warnings, err := h.svc.CheckDDLDependencies(params, req.SQL)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if warnings == nil {
+		warnings = []domain.DependencyWarning{}
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"warnings": warnings})
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"firebird-web-admin/internal/domain"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) listDomains(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	domains, err := h.svc.ListDomains(params)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, domains)
+}
+
+func (h *Handler) getDomain(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	d, err := h.svc.GetDomain(params, c.Param("name"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, d)
+}
+
+func (h *Handler) getDomainDDL(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	ddl, err := h.svc.GetDomainDDL(params, c.Param("name"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"ddl": ddl})
+}
+
+func (h *Handler) createDomain(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var d domain.Domain
+	if err := c.Bind(&d); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	if err := h.svc.CreateDomain(params, d); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+// alterDomain changes the attributes given in the body and keeps the omitted ones; a
+// different name renames the domain.
+func (h *Handler) alterDomain(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var req domain.DomainRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	if err := h.svc.AlterDomain(params, c.Param("name"), req); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+func (h *Handler) dropDomain(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	if err := h.svc.DropDomain(params, c.Param("name")); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
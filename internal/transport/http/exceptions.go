@@ -0,0 +1,74 @@
+package http
+
+import (
+	"firebird-web-admin/internal/domain"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AlterExceptionRequest carries the new message text of an exception.
+type AlterExceptionRequest struct {
+	Message string `json:"message"`
+}
+
+func (h *Handler) listExceptions(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	exceptions, err := h.svc.ListExceptions(params)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, exceptions)
+}
+
+func (h *Handler) getException(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	exception, err := h.svc.GetException(params, c.Param("name"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, exception)
+}
+
+func (h *Handler) getExceptionDDL(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	ddl, err := h.svc.GetExceptionDDL(params, c.Param("name"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"ddl": ddl})
+}
+
+func (h *Handler) createException(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var exception domain.DatabaseException
+	if err := c.Bind(&exception); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	if err := h.svc.CreateException(params, exception); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+func (h *Handler) alterException(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var req AlterExceptionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	if err := h.svc.AlterException(params, c.Param("name"), req.Message); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+func (h *Handler) dropException(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	if err := h.svc.DropException(params, c.Param("name")); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
@@ -0,0 +1,55 @@
+package http
+
+import (
+	"firebird-web-admin/internal/domain"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) listFunctions(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	functions, err := h.svc.ListFunctions(params)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, functions)
+}
+
+func (h *Handler) getFunction(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	function, err := h.svc.GetFunction(params, c.Param("name"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, function)
+}
+
+func (h *Handler) getFunctionSource(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	source, err := h.svc.GetFunctionSource(params, c.Param("name"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"source": source})
+}
+
+// executeFunction evaluates a function with the arguments in the body, keyed by argument
+// name, or by 1-based position for legacy UDFs whose arguments have no names.
+func (h *Handler) executeFunction(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	args, err := decodeRoutineArgs(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	data, cols, err := h.svc.ExecuteFunction(params, c.Param("name"), args)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":    data,
+		"columns": cols,
+		"total":   len(data),
+	})
+}
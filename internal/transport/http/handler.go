@@ -6,6 +6,7 @@ import (
 	"firebird-web-admin/internal/domain"
 	"firebird-web-admin/internal/service"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"os"
@@ -42,16 +43,114 @@ func (h *Handler) RegisterRoutes(e *echo.Echo) {
 	api.GET("/views", h.listViews)
 	api.GET("/procedures", h.listProcedures)
 	api.GET("/procedure/:name/source", h.getProcedureSource)
+	api.PUT("/procedure/:name", h.alterProcedure)
+	api.POST("/procedure/:name/revert", h.revertProcedure)
 	api.GET("/procedure/:name/parameters", h.getProcedureParameters)
 	api.POST("/procedure/:name/execute", h.executeProcedure)
+	api.GET("/triggers", h.listTriggers)
+	api.POST("/triggers", h.createTrigger)
+	api.GET("/trigger/:name", h.getTrigger)
+	api.PUT("/trigger/:name", h.alterTrigger)
+	api.DELETE("/trigger/:name", h.dropTrigger)
+	api.GET("/trigger/:name/source", h.getTriggerSource)
+	api.POST("/trigger/:name/activate", h.activateTrigger)
+	api.POST("/trigger/:name/deactivate", h.deactivateTrigger)
+	api.GET("/sequences", h.listSequences)
+	api.POST("/sequences", h.createSequence)
+	api.GET("/sequence/:name", h.getSequence)
+	api.DELETE("/sequence/:name", h.dropSequence)
+	api.POST("/sequence/:name/restart", h.restartSequence)
+	api.PUT("/sequence/:name/value", h.setSequenceValue)
+	api.GET("/functions", h.listFunctions)
+	api.GET("/function/:name", h.getFunction)
+	api.GET("/function/:name/source", h.getFunctionSource)
+	api.POST("/function/:name/execute", h.executeFunction)
+	api.GET("/packages", h.listPackages)
+	api.GET("/package/:name", h.getPackage)
+	api.GET("/package/:name/source", h.getPackageSource)
+	api.PUT("/package/:name/header", h.compilePackageHeader)
+	api.PUT("/package/:name/body", h.compilePackageBody)
+	api.POST("/package/:name/procedure/:routine/execute", h.executePackageProcedure)
+	api.POST("/package/:name/function/:routine/execute", h.executePackageFunction)
+	api.GET("/view/:name", h.getView)
+	api.GET("/view/:name/source", h.getViewSource)
+	api.PUT("/view/:name", h.alterView)
+	api.GET("/exceptions", h.listExceptions)
+	api.POST("/exceptions", h.createException)
+	api.GET("/exception/:name", h.getException)
+	api.PUT("/exception/:name", h.alterException)
+	api.DELETE("/exception/:name", h.dropException)
+	api.GET("/exception/:name/ddl", h.getExceptionDDL)
+	api.GET("/domains", h.listDomains)
+	api.POST("/domains", h.createDomain)
+	api.GET("/domain/:name", h.getDomain)
+	api.PUT("/domain/:name", h.alterDomain)
+	api.DELETE("/domain/:name", h.dropDomain)
+	api.GET("/domain/:name/ddl", h.getDomainDDL)
+	api.GET("/charsets", h.listCharacterSets)
+	api.GET("/charset/:name", h.getCharacterSet)
+	api.PUT("/charset/:name", h.setDefaultCollation)
+	api.GET("/charset/:name/ddl", h.getCharacterSetDDL)
+	api.GET("/collations", h.listCollations)
+	api.POST("/collations", h.createCollation)
+	api.GET("/collation/:name", h.getCollation)
+	api.DELETE("/collation/:name", h.dropCollation)
+	api.GET("/collation/:name/ddl", h.getCollationDDL)
+	api.GET("/dependencies/:type/:name/uses", h.getDependencyUses)
+	api.GET("/dependencies/:type/:name/used-by", h.getDependencyUsedBy)
+	api.GET("/dependencies/:type/:name/graph", h.getDependencyGraph)
+	api.POST("/dependencies/check", h.checkDependencies)
+	api.POST("/tables", h.createTable)
+	api.GET("/table/:name/design", h.getTableDesign)
+	api.PUT("/table/:name/design", h.alterTable)
+	api.GET("/indexes", h.listIndexes)
+	api.POST("/indexes", h.createIndex)
+	api.GET("/indexes/report", h.getIndexReport)
+	api.GET("/index/:name", h.getIndex)
+	api.DELETE("/index/:name", h.dropIndex)
+	api.POST("/index/:name/activate", h.activateIndex)
+	api.POST("/index/:name/deactivate", h.deactivateIndex)
+	api.POST("/index/:name/statistics", h.setIndexStatistics)
+	api.GET("/monitor/overview", h.getMonitorOverview)
+	api.GET("/monitor/attachments", h.listAttachments)
+	api.GET("/monitor/transactions", h.listMonitoredTransactions)
+	api.GET("/monitor/statements", h.listMonitoredStatements)
+	api.DELETE("/monitor/attachment/:id", h.killAttachment)
+	api.DELETE("/monitor/statement/:id", h.cancelStatement)
+	api.GET("/health/database", h.getDatabaseHealth)
+	api.POST("/services/backup", h.backupDatabase)
+	api.POST("/services/restore", h.restoreDatabase)
+	api.POST("/services/validate", h.validateDatabase)
+	api.POST("/services/sweep", h.sweepDatabase)
+	api.POST("/services/shutdown", h.shutdownDatabase)
+	api.POST("/services/online", h.bringOnline)
+	api.PUT("/services/properties", h.setDatabaseProperties)
+	api.GET("/users", h.listUsers)
+	api.POST("/users", h.createUser)
+	api.PUT("/user/:name", h.alterUser)
+	api.DELETE("/user/:name", h.dropUser)
+	api.GET("/roles", h.listRoles)
+	api.POST("/roles", h.createRole)
+	api.DELETE("/role/:name", h.dropRole)
+	api.GET("/grants", h.listGrants)
+	api.POST("/grants", h.grantPrivileges)
+	api.POST("/grants/revoke", h.revokePrivileges)
+	api.GET("/grants/:objectType/:name", h.getPrivilegeMatrix)
+	api.PUT("/grants/:objectType/:name", h.applyPrivilegeMatrix)
 	api.GET("/table/:name/data", h.getTableData)
 	api.PUT("/table/:name/data", h.updateTableData)
 	api.POST("/table/:name/data", h.insertTableData)
 	api.DELETE("/table/:name/data", h.deleteTableData)
 	api.GET("/table/:name/ddl", h.getTableDDL)
+	api.GET("/ddl/database", h.getDatabaseDDL)
+	api.GET("/schema/snapshot", h.getSchemaSnapshot)
+	api.POST("/schema/compare", h.compareSchemas)
+	api.POST("/data/compare", h.compareData)
+	api.POST("/data/compare/script", h.getDataSyncScript)
 
 	// New Endpoints
 	api.POST("/execute", h.executeQuery)
+	api.POST("/explain", h.explainQuery)
 	api.GET("/metadata", h.getMetadata)
 }
 
@@ -74,11 +173,8 @@ func (h *Handler) connect(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request"})
 	}
 
-	if os.Getenv("DEMO_MODE") == "true" {
-		if params.Database != "firebird5:employee" {
-			fmt.Printf("Blocked connection attempt to %s in DEMO MODE\n", params.Database)
-			return c.JSON(http.StatusForbidden, map[string]string{"error": "Demo mode: only firebird5:employee allowed"})
-		}
+	if !demoAllows(params) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Demo mode: only firebird5:employee allowed"})
 	}
 
 	if err := h.svc.Connect(params); err != nil {
@@ -106,6 +202,15 @@ func (h *Handler) connect(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]string{"token": t})
 }
 
+// demoAllows reports whether a connection may be opened. In DEMO_MODE only the demo database is allowed.
+func demoAllows(params domain.ConnectionParams) bool {
+	if os.Getenv("DEMO_MODE") == "true" && params.Database != "firebird5:employee" {
+		fmt.Printf("Blocked connection attempt to %s in DEMO MODE\n", params.Database)
+		return false
+	}
+	return true
+}
+
 func (h *Handler) authMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		authHeader := c.Request().Header.Get("Authorization")
@@ -172,15 +277,49 @@ func (h *Handler) listProcedures(c echo.Context) error {
 	return c.JSON(http.StatusOK, tables)
 }
 
+// getProcedureSource returns the source and, when the procedure was altered through this
+// server process since it started, the source it replaced as "previous".
 func (h *Handler) getProcedureSource(c echo.Context) error {
 	params := c.Get("connParams").(domain.ConnectionParams)
 	procName := c.Param("name")
 
 	source, err := h.svc.GetProcedureSource(params, procName)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return errorResponse(c, err)
+	}
+	resp := map[string]string{"source": source}
+	if previous, ok := h.svc.GetPreviousProcedureSource(params, procName); ok {
+		resp["previous"] = previous
 	}
-	return c.JSON(http.StatusOK, map[string]string{"source": source})
+	return c.JSON(http.StatusOK, resp)
+}
+
+type AlterProcedureRequest struct {
+	Source string `json:"source"` // Complete CREATE OR ALTER PROCEDURE statement
+}
+
+func (h *Handler) alterProcedure(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	procName := c.Param("name")
+
+	var req AlterProcedureRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	previous, err := h.svc.AlterProcedure(params, procName, req.Source)
+	if err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success", "previous": previous})
+}
+
+func (h *Handler) revertProcedure(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	if err := h.svc.RevertProcedure(params, c.Param("name")); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
 }
 
 func (h *Handler) getProcedureParameters(c echo.Context) error {
@@ -189,7 +328,7 @@ func (h *Handler) getProcedureParameters(c echo.Context) error {
 
 	paramsList, err := h.svc.GetProcedureParameters(params, procName)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return errorResponse(c, err)
 	}
 	return c.JSON(http.StatusOK, paramsList)
 }
@@ -198,17 +337,18 @@ func (h *Handler) executeProcedure(c echo.Context) error {
 	params := c.Get("connParams").(domain.ConnectionParams)
 	procName := c.Param("name")
 
-	var inputParams map[string]interface{}
-	if err := c.Bind(&inputParams); err != nil {
-		// If body is empty or invalid, assume no params or error?
-		// Firebird needs exact params. If we fail to parse, proceed with empty map if binding failed on empty body.
-		// Echo Bind might return error on empty body if header says JSON.
-		inputParams = make(map[string]interface{})
+	inputParams, err := decodeRoutineArgs(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	selectable, ok := procedureMode(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "mode must be select or execute"})
 	}
 
-	data, cols, err := h.svc.ExecuteProcedure(params, procName, inputParams)
+	data, cols, err := h.svc.ExecuteProcedure(params, procName, inputParams, selectable)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return errorResponse(c, err)
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
@@ -220,6 +360,34 @@ func (h *Handler) executeProcedure(c echo.Context) error {
 	})
 }
 
+// decodeRoutineArgs decodes the routine arguments of an execute request. An empty body
+// or null means no arguments.
+func decodeRoutineArgs(c echo.Context) (map[string]interface{}, error) {
+	var args map[string]interface{}
+	// Numbers are kept as json.Number so BIGINT and NUMERIC values do not lose precision in float64.
+	decoder := json.NewDecoder(c.Request().Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&args); err != nil && err != io.EOF {
+		return nil, err
+	}
+	if args == nil {
+		args = make(map[string]interface{})
+	}
+	return args, nil
+}
+
+// procedureMode reads ?mode=select or ?mode=execute, which overrides the mode derived from RDB$PROCEDURE_TYPE.
+func procedureMode(c echo.Context) (*bool, bool) {
+	switch mode := c.QueryParam("mode"); mode {
+	case "":
+		return nil, true
+	case "select", "execute":
+		v := mode == "select"
+		return &v, true
+	}
+	return nil, false
+}
+
 func (h *Handler) getTableData(c echo.Context) error {
 	params := c.Get("connParams").(domain.ConnectionParams)
 	tableName := c.Param("name")
@@ -325,8 +493,32 @@ func (h *Handler) getTableDDL(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]string{"ddl": ddl})
 }
 
+// getDatabaseDDL streams the DDL script of the whole database.
+// With ?download=1 the script is sent as an attachment.
+func (h *Handler) getDatabaseDDL(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, echo.MIMETextPlainCharsetUTF8)
+	if c.QueryParam("download") != "" {
+		res.Header().Set(echo.HeaderContentDisposition, "attachment; filename=\"schema.sql\"")
+	}
+
+	// Headers are committed on the first write, so errors before that can still be reported as JSON.
+	if err := h.svc.WriteDatabaseDDL(params, res); err != nil {
+		if !res.Committed {
+			res.Header().Del(echo.HeaderContentDisposition)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		c.Logger().Errorf("getDatabaseDDL: %v", err)
+	}
+	return nil
+}
+
 type ExecuteRequest struct {
-	SQL string `json:"sql"`
+	SQL   string `json:"sql"`
+	Force bool   `json:"force"` // Execute even when the statement breaks dependent objects
+	Stats bool   `json:"stats"` // Also report the I/O and record counters of the execution
 }
 
 func (h *Handler) executeQuery(c echo.Context) error {
@@ -340,7 +532,19 @@ func (h *Handler) executeQuery(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Missing SQL statement"})
 	}
 
-	data, cols, err := h.svc.ExecuteQuery(params, req.SQL)
+	if !req.Force {
+		warnings, err := h.svc.CheckDDLDependencies(params, req.SQL)
+		if err != nil {
+			c.Logger().Errorf("executeQuery dependency check: %v", err)
+		} else if len(warnings) > 0 {
+			return c.JSON(http.StatusConflict, map[string]interface{}{
+				"error":    warnings[0].Message,
+				"warnings": warnings,
+			})
+		}
+	}
+
+	data, cols, stats, err := h.svc.ExecuteQuery(params, req.SQL, req.Stats)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
@@ -349,9 +553,29 @@ func (h *Handler) executeQuery(c echo.Context) error {
 		"data":    data,
 		"columns": cols,
 		"total":   len(data),
+		"stats":   stats,
 	})
 }
 
+// explainQuery prepares the statement without executing it and returns its plan.
+func (h *Handler) explainQuery(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var req ExecuteRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	if req.SQL == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Missing SQL statement"})
+	}
+
+	plan, err := h.svc.ExplainQuery(params, req.SQL)
+	if err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, plan)
+}
+
 func (h *Handler) getMetadata(c echo.Context) error {
 	params := c.Get("connParams").(domain.ConnectionParams)
 
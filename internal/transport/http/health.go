@@ -0,0 +1,35 @@
+package http
+
+import (
+	"firebird-web-admin/internal/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// getDatabaseHealth accepts ?oit_gap=, ?oat_gap= and ?transaction_age= (seconds) to change
+// the warning thresholds.
+func (h *Handler) getDatabaseHealth(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var thresholds domain.HealthThresholds
+	for name, dest := range map[string]*int64{
+		"oit_gap":         &thresholds.OITGap,
+		"oat_gap":         &thresholds.OATGap,
+		"transaction_age": &thresholds.TransactionAge,
+	} {
+		if v := c.QueryParam(name); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid " + name})
+			}
+			*dest = n
+		}
+	}
+	health, err := h.svc.GetDatabaseHealth(params, thresholds)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, health)
+}
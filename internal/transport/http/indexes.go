@@ -0,0 +1,93 @@
+package http
+
+import (
+	"firebird-web-admin/internal/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// listIndexes returns all user indexes, or those of ?table= only.
+func (h *Handler) listIndexes(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	indexes, err := h.svc.ListIndexes(params, c.QueryParam("table"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, indexes)
+}
+
+func (h *Handler) getIndex(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	index, err := h.svc.GetIndex(params, c.Param("name"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, index)
+}
+
+func (h *Handler) createIndex(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	index := domain.Index{Active: true}
+	if err := c.Bind(&index); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	if err := h.svc.CreateIndex(params, index); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+func (h *Handler) dropIndex(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	if err := h.svc.DropIndex(params, c.Param("name")); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+// activateIndex also rebuilds an index that already is active.
+func (h *Handler) activateIndex(c echo.Context) error {
+	return h.setIndexActive(c, true)
+}
+
+func (h *Handler) deactivateIndex(c echo.Context) error {
+	return h.setIndexActive(c, false)
+}
+
+func (h *Handler) setIndexActive(c echo.Context, active bool) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	if err := h.svc.SetIndexActive(params, c.Param("name"), active); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+func (h *Handler) setIndexStatistics(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	index, err := h.svc.SetIndexStatistics(params, c.Param("name"))
+	if err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, index)
+}
+
+// getIndexReport accepts ?max_selectivity= to change the low-selectivity threshold.
+func (h *Handler) getIndexReport(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var maxSelectivity float64
+	if v := c.QueryParam("max_selectivity"); v != "" {
+		var err error
+		if maxSelectivity, err = strconv.ParseFloat(v, 64); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid max_selectivity"})
+		}
+	}
+	issues, err := h.svc.GetIndexReport(params, maxSelectivity)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, issues)
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"errors"
+	"firebird-web-admin/internal/domain"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) getMonitorOverview(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	overview, err := h.svc.GetMonitorOverview(params)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, overview)
+}
+
+func (h *Handler) listAttachments(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	attachments, err := h.svc.ListAttachments(params)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, attachments)
+}
+
+func (h *Handler) listMonitoredTransactions(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	transactions, err := h.svc.ListMonitoredTransactions(params)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, transactions)
+}
+
+// listMonitoredStatements returns only running statements with ?active=1.
+func (h *Handler) listMonitoredStatements(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	active := c.QueryParam("active") == "1" || c.QueryParam("active") == "true"
+	statements, err := h.svc.ListMonitoredStatements(params, active)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, statements)
+}
+
+func (h *Handler) killAttachment(c echo.Context) error {
+	return h.endMonitored(c, h.svc.KillAttachment)
+}
+
+func (h *Handler) cancelStatement(c echo.Context) error {
+	return h.endMonitored(c, h.svc.CancelStatement)
+}
+
+// endMonitored kills an attachment or cancels a statement by :id. It is refused in
+// DEMO_MODE, with 403 when the user may not end the other user's work and with 404 when
+// the id is no longer monitored.
+func (h *Handler) endMonitored(c echo.Context, end func(domain.ConnectionParams, int64) error) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	if os.Getenv("DEMO_MODE") == "true" {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Demo mode: killing attachments and statements is disabled"})
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid id"})
+	}
+	if err := end(params, id); err != nil {
+		var permErr *domain.PermissionError
+		if errors.As(err, &permErr) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"firebird-web-admin/internal/domain"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// PackageSourceRequest carries the complete statement that defines a package header or body.
+type PackageSourceRequest struct {
+	Source string `json:"source"`
+}
+
+func (h *Handler) listPackages(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	packages, err := h.svc.ListPackages(params)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, packages)
+}
+
+func (h *Handler) getPackage(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	pkg, err := h.svc.GetPackage(params, c.Param("name"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, pkg)
+}
+
+func (h *Handler) getPackageSource(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	header, body, err := h.svc.GetPackageSource(params, c.Param("name"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"header": header, "body": body})
+}
+
+func (h *Handler) compilePackageHeader(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var req PackageSourceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	if err := h.svc.CompilePackageHeader(params, c.Param("name"), req.Source); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+func (h *Handler) compilePackageBody(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var req PackageSourceRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	if err := h.svc.CompilePackageBody(params, c.Param("name"), req.Source); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+func (h *Handler) executePackageProcedure(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	inputParams, err := decodeRoutineArgs(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	selectable, ok := procedureMode(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "mode must be select or execute"})
+	}
+
+	data, cols, err := h.svc.ExecutePackageProcedure(params, c.Param("name"), c.Param("routine"), inputParams, selectable)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":    data,
+		"columns": cols,
+		"total":   len(data),
+	})
+}
+
+func (h *Handler) executePackageFunction(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	args, err := decodeRoutineArgs(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	data, cols, err := h.svc.ExecutePackageFunction(params, c.Param("name"), c.Param("routine"), args)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"data":    data,
+		"columns": cols,
+		"total":   len(data),
+	})
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"firebird-web-admin/internal/domain"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) getSchemaSnapshot(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	schema, err := h.svc.GetSchema(params)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, schema)
+}
+
+// CompareSchemasRequest selects both sides of a schema comparison.
+// A side with neither a connection nor a snapshot uses the current connection.
+type CompareSchemasRequest struct {
+	Source domain.SchemaSource `json:"source"`
+	Target domain.SchemaSource `json:"target"`
+}
+
+func (h *Handler) compareSchemas(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var req CompareSchemasRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	for _, side := range []*domain.SchemaSource{&req.Source, &req.Target} {
+		if side.Connection == nil && side.Snapshot == nil {
+			side.Connection = &params
+			continue
+		}
+		if side.Connection == nil {
+			continue
+		}
+		if !demoAllows(*side.Connection) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "Demo mode: only firebird5:employee allowed"})
+		}
+		// A connection given in the request is checked like one made through /connect.
+		if err := h.svc.Connect(*side.Connection); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Connection failed: " + err.Error()})
+		}
+	}
+
+	diff, err := h.svc.CompareSchemas(req.Source, req.Target)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, diff)
+}
@@ -0,0 +1,150 @@
+package http
+
+import (
+	"firebird-web-admin/internal/domain"
+	"net/http"
+	"os"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GrantsRequest carries the roles and privileges to grant or revoke.
+type GrantsRequest struct {
+	Grants []domain.Grant `json:"grants"`
+}
+
+func (h *Handler) listUsers(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	users, err := h.svc.ListUsers(params)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, users)
+}
+
+func (h *Handler) createUser(c echo.Context) error {
+	var req domain.UserRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	return h.changeUsers(c, func(params domain.ConnectionParams) error {
+		return h.svc.CreateUser(params, req)
+	})
+}
+
+func (h *Handler) alterUser(c echo.Context) error {
+	var req domain.UserRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	return h.changeUsers(c, func(params domain.ConnectionParams) error {
+		return h.svc.AlterUser(params, c.Param("name"), req)
+	})
+}
+
+// dropUser accepts ?plugin= to drop the user from a user manager other than the default.
+func (h *Handler) dropUser(c echo.Context) error {
+	return h.changeUsers(c, func(params domain.ConnectionParams) error {
+		return h.svc.DropUser(params, c.Param("name"), c.QueryParam("plugin"))
+	})
+}
+
+// changeUsers runs a user statement. It is refused in DEMO_MODE because users live in the
+// security database shared by every database of the server.
+func (h *Handler) changeUsers(c echo.Context, change func(domain.ConnectionParams) error) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	if os.Getenv("DEMO_MODE") == "true" {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Demo mode: user management is disabled"})
+	}
+	if err := change(params); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+func (h *Handler) listRoles(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	roles, err := h.svc.ListRoles(params)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, roles)
+}
+
+func (h *Handler) createRole(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var role domain.Role
+	if err := c.Bind(&role); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	if err := h.svc.CreateRole(params, role.Name); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+func (h *Handler) dropRole(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	if err := h.svc.DropRole(params, c.Param("name")); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+// listGrants accepts ?object= and ?grantee= to list the grants on one object or to one
+// user, role or routine.
+func (h *Handler) listGrants(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	grants, err := h.svc.ListGrants(params, c.QueryParam("object"), c.QueryParam("grantee"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, grants)
+}
+
+func (h *Handler) grantPrivileges(c echo.Context) error {
+	return h.changeGrants(c, h.svc.GrantPrivileges)
+}
+
+func (h *Handler) revokePrivileges(c echo.Context) error {
+	return h.changeGrants(c, h.svc.RevokePrivileges)
+}
+
+func (h *Handler) changeGrants(c echo.Context, change func(domain.ConnectionParams, []domain.Grant) error) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var req GrantsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	if err := change(params, req.Grants); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+func (h *Handler) getPrivilegeMatrix(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	matrix, err := h.svc.GetPrivilegeMatrix(params, c.Param("objectType"), c.Param("name"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, matrix)
+}
+
+// applyPrivilegeMatrix makes the privileges on the object match the matrix in the body.
+// With ?dry_run=1 the GRANT and REVOKE statements are returned without running them.
+func (h *Handler) applyPrivilegeMatrix(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var desired domain.PrivilegeMatrix
+	if err := c.Bind(&desired); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	result, err := h.svc.ApplyPrivilegeMatrix(params, c.Param("objectType"), c.Param("name"), desired, dryRun(c))
+	if err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, result)
+}
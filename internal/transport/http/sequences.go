@@ -0,0 +1,79 @@
+package http
+
+import (
+	"firebird-web-admin/internal/domain"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SequenceValueRequest carries the value for restart and set value operations.
+// A restart without a value restarts the sequence at its initial value.
+type SequenceValueRequest struct {
+	Value *int64 `json:"value"`
+}
+
+func (h *Handler) listSequences(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	sequences, err := h.svc.ListSequences(params)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, sequences)
+}
+
+func (h *Handler) getSequence(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	sequence, err := h.svc.GetSequence(params, c.Param("name"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, sequence)
+}
+
+func (h *Handler) createSequence(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var sequence domain.Sequence
+	if err := c.Bind(&sequence); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	if err := h.svc.CreateSequence(params, sequence); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+func (h *Handler) restartSequence(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var req SequenceValueRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	if err := h.svc.RestartSequence(params, c.Param("name"), req.Value); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+func (h *Handler) setSequenceValue(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var req SequenceValueRequest
+	if err := c.Bind(&req); err != nil || req.Value == nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "value is required"})
+	}
+	if err := h.svc.SetSequenceValue(params, c.Param("name"), *req.Value); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+func (h *Handler) dropSequence(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	if err := h.svc.DropSequence(params, c.Param("name")); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
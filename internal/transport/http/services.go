@@ -0,0 +1,117 @@
+package http
+
+import (
+	"firebird-web-admin/internal/domain"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) backupDatabase(c echo.Context) error {
+	var req domain.BackupRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	return h.runService(c, "backupDatabase", func(params domain.ConnectionParams, w io.Writer) error {
+		return h.svc.Backup(params, req, w)
+	})
+}
+
+func (h *Handler) restoreDatabase(c echo.Context) error {
+	var req domain.RestoreRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	return h.runService(c, "restoreDatabase", func(params domain.ConnectionParams, w io.Writer) error {
+		return h.svc.Restore(params, req, w)
+	})
+}
+
+// runService streams the verbose log of a service call as plain text. It is refused in
+// DEMO_MODE because backup and restore write files on the server. An error after the log
+// has started is reported as its last line.
+func (h *Handler) runService(c echo.Context, name string, run func(domain.ConnectionParams, io.Writer) error) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	if os.Getenv("DEMO_MODE") == "true" {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Demo mode: backup and restore are disabled"})
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, echo.MIMETextPlainCharsetUTF8)
+
+	// Headers are committed on the first write, so errors before that can still be reported as JSON.
+	if err := run(params, res); err != nil {
+		if !res.Committed {
+			return errorResponse(c, err)
+		}
+		c.Logger().Errorf("%s: %v", name, err)
+		_, _ = io.WriteString(res, "ERROR: "+err.Error()+"\n")
+	}
+	return nil
+}
+
+type OnlineRequest struct {
+	State string `json:"state"` // "normal" (default), "multi" or "single"
+}
+
+func (h *Handler) validateDatabase(c echo.Context) error {
+	var req domain.ValidationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	return h.maintain(c, func(params domain.ConnectionParams) (interface{}, error) {
+		return h.svc.ValidateDatabase(params, req)
+	})
+}
+
+func (h *Handler) sweepDatabase(c echo.Context) error {
+	return h.maintain(c, func(params domain.ConnectionParams) (interface{}, error) {
+		return h.svc.SweepDatabase(params)
+	})
+}
+
+func (h *Handler) shutdownDatabase(c echo.Context) error {
+	var req domain.ShutdownRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	return h.maintain(c, func(params domain.ConnectionParams) (interface{}, error) {
+		return h.svc.ShutdownDatabase(params, req)
+	})
+}
+
+func (h *Handler) bringOnline(c echo.Context) error {
+	var req OnlineRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	return h.maintain(c, func(params domain.ConnectionParams) (interface{}, error) {
+		return h.svc.BringOnline(params, req.State)
+	})
+}
+
+func (h *Handler) setDatabaseProperties(c echo.Context) error {
+	var props domain.DatabaseProperties
+	if err := c.Bind(&props); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	return h.maintain(c, func(params domain.ConnectionParams) (interface{}, error) {
+		return h.svc.SetDatabaseProperties(params, props)
+	})
+}
+
+// maintain runs a maintenance service call and returns its result. It is refused in
+// DEMO_MODE because it changes or takes the database offline.
+func (h *Handler) maintain(c echo.Context, run func(domain.ConnectionParams) (interface{}, error)) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	if os.Getenv("DEMO_MODE") == "true" {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Demo mode: database maintenance is disabled"})
+	}
+	result, err := run(params)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"firebird-web-admin/internal/domain"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// dryRun reports whether ?dry_run=1 asks for the generated statements without executing them.
+func dryRun(c echo.Context) bool {
+	v := c.QueryParam("dry_run")
+	return v == "1" || v == "true"
+}
+
+func (h *Handler) getTableDesign(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	design, err := h.svc.GetTableDesign(params, c.Param("name"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, design)
+}
+
+func (h *Handler) createTable(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var design domain.TableDesign
+	if err := c.Bind(&design); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	result, err := h.svc.CreateTable(params, design, dryRun(c))
+	if err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) alterTable(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var design domain.TableDesign
+	if err := c.Bind(&design); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	result, err := h.svc.AlterTable(params, c.Param("name"), design, dryRun(c))
+	if err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"errors"
+	"firebird-web-admin/internal/domain"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// sqlErrorResponse reports errors that point at a position in the submitted source as 400
+// with line and column, so editors can highlight the offending token.
+func sqlErrorResponse(c echo.Context, err error) error {
+	var sqlErr *domain.SQLError
+	if errors.As(err, &sqlErr) {
+		return c.JSON(http.StatusBadRequest, sqlErr)
+	}
+	return errorResponse(c, err)
+}
+
+// errorResponse reports invalid input as 400, a missing object as 404, an object that
+// already exists as 409, a feature the server lacks as 501 and any other error as 500.
+func errorResponse(c echo.Context, err error) error {
+	var invalid *domain.ValidationError
+	var notFound *domain.NotFoundError
+	var conflict *domain.ConflictError
+	var unsupported *domain.UnsupportedError
+	switch {
+	case errors.As(err, &invalid):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.As(err, &notFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.As(err, &conflict):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.As(err, &unsupported):
+		return c.JSON(http.StatusNotImplemented, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+}
+
+func (h *Handler) listTriggers(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	triggers, err := h.svc.ListTriggers(params)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, triggers)
+}
+
+func (h *Handler) getTrigger(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	trigger, err := h.svc.GetTrigger(params, c.Param("name"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, trigger)
+}
+
+func (h *Handler) getTriggerSource(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	source, err := h.svc.GetTriggerSource(params, c.Param("name"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"source": source})
+}
+
+func (h *Handler) createTrigger(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var trigger domain.Trigger
+	if err := c.Bind(&trigger); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	if err := h.svc.CreateTrigger(params, trigger); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+func (h *Handler) alterTrigger(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var trigger domain.Trigger
+	if err := c.Bind(&trigger); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	if err := h.svc.AlterTrigger(params, c.Param("name"), trigger); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+func (h *Handler) activateTrigger(c echo.Context) error {
+	return h.setTriggerActive(c, true)
+}
+
+func (h *Handler) deactivateTrigger(c echo.Context) error {
+	return h.setTriggerActive(c, false)
+}
+
+func (h *Handler) setTriggerActive(c echo.Context, active bool) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	if err := h.svc.SetTriggerActive(params, c.Param("name"), active); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
+
+func (h *Handler) dropTrigger(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	if err := h.svc.DropTrigger(params, c.Param("name")); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}
@@ -0,0 +1,44 @@
+package http
+
+import (
+	"firebird-web-admin/internal/domain"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AlterViewRequest carries the complete CREATE OR ALTER VIEW statement.
+type AlterViewRequest struct {
+	Source string `json:"source"`
+}
+
+func (h *Handler) getView(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	view, err := h.svc.GetView(params, c.Param("name"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, view)
+}
+
+func (h *Handler) getViewSource(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+	source, err := h.svc.GetViewSource(params, c.Param("name"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"source": source})
+}
+
+func (h *Handler) alterView(c echo.Context) error {
+	params := c.Get("connParams").(domain.ConnectionParams)
+
+	var req AlterViewRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	if err := h.svc.AlterView(params, c.Param("name"), req.Source); err != nil {
+		return sqlErrorResponse(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
+}